@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/psql"
+	"github.com/illfate/social-tournaments-service/pkg/scheduler"
 	"github.com/illfate/social-tournaments-service/pkg/server/graphql"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
 )
 
 const (
@@ -17,6 +24,11 @@ const (
 	dbNameEnvVar         = "DB_NAME"
 	userSchemeFile       = "USER_SCHEME_FILE"
 	tournamentSchemeFile = "TOURNAMENT_SCHEME_FILE"
+	schedulerInterval    = "SCHEDULER_INTERVAL"
+	idempotencyKeyTTL    = "IDEMPOTENCY_KEY_TTL"
+
+	defaultSchedulerInterval = time.Minute
+	shutdownTimeout          = 10 * time.Second
 )
 
 func main() {
@@ -51,6 +63,24 @@ func main() {
 		log.Printf(`no "%s" env variable`, dbNameEnvVar)
 		return
 	}
+	interval := defaultSchedulerInterval
+	if v := os.Getenv(schedulerInterval); v != "" {
+		var err error
+		interval, err = time.ParseDuration(v)
+		if err != nil {
+			log.Printf(`incorrect "%s" env variable: %s`, schedulerInterval, err)
+			return
+		}
+	}
+	keyTTL := sts.DefaultIdempotencyKeyTTL
+	if v := os.Getenv(idempotencyKeyTTL); v != "" {
+		var err error
+		keyTTL, err = time.ParseDuration(v)
+		if err != nil {
+			log.Printf(`incorrect "%s" env variable: %s`, idempotencyKeyTTL, err)
+			return
+		}
+	}
 
 	db, err := psql.New(dbUser, dbHost, dbPass, dbName)
 	if err != nil {
@@ -58,16 +88,47 @@ func main() {
 		return
 	}
 	defer db.Close()
+	db.SetIdempotencyKeyTTL(keyTTL)
 
-	s, err := graphql.NewResolver(db, uScheme, uScheme)
+	s, err := graphql.NewResolver(db, uScheme, tScheme)
 	if err != nil {
 		log.Printf("couldn't start graphql: %s", err)
 		return
 	}
 
-	err = http.ListenAndServe(":"+portNum, s)
-	if err != nil {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scheduler.New(db, interval).Run(ctx)
+	}()
+
+	server := http.Server{
+		Addr:    ":" + portNum,
+		Handler: s,
+	}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		cancel()
+		shutdownCtx, stop := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer stop()
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Printf("couldn't shut down server: %s", err)
+		}
+	}()
+
+	err = server.ListenAndServe()
+	if err != http.ErrServerClosed {
 		log.Print(err)
 		return
 	}
+	<-shutdown
 }
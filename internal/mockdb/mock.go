@@ -2,6 +2,7 @@ package mockdb
 
 import (
 	"context"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 	"github.com/stretchr/testify/mock"
@@ -26,13 +27,46 @@ func (c *Connector) DeleteUser(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
-func (c *Connector) AddPoints(ctx context.Context, id, points int64) error {
-	args := c.Called(id, points)
+func (c *Connector) UpdateUser(ctx context.Context, id int64, update sts.UserUpdate) error {
+	args := c.Called(id, update)
 	return args.Error(0)
 }
 
-func (c *Connector) AddTournament(ctx context.Context, name string, deposit uint64) (int64, error) {
-	args := c.Called(name, deposit)
+func (c *Connector) ListUsers(ctx context.Context, q sts.UserQuery) (*sts.UserPage, error) {
+	args := c.Called(q)
+	return args.Get(0).(*sts.UserPage), args.Error(1)
+}
+
+func (c *Connector) Credit(ctx context.Context, id int64, currency sts.Currency, amount uint64,
+	expiresIn time.Duration) error {
+	args := c.Called(id, currency, amount, expiresIn)
+	return args.Error(0)
+}
+
+func (c *Connector) Debit(ctx context.Context, id int64, currency sts.Currency, amount uint64) error {
+	args := c.Called(id, currency, amount)
+	return args.Error(0)
+}
+
+func (c *Connector) TransferPoints(ctx context.Context, fromID, toID int64, currency sts.Currency,
+	amount uint64) error {
+	args := c.Called(fromID, toID, currency, amount)
+	return args.Error(0)
+}
+
+func (c *Connector) GetUserTransactions(ctx context.Context, userID, beforeID int64,
+	limit uint64) ([]sts.Transaction, error) {
+	args := c.Called(userID, beforeID, limit)
+	return args.Get(0).([]sts.Transaction), args.Error(1)
+}
+
+func (c *Connector) GetRatingHistory(ctx context.Context, userID int64) ([]sts.RatingChange, error) {
+	args := c.Called(userID)
+	return args.Get(0).([]sts.RatingChange), args.Error(1)
+}
+
+func (c *Connector) AddTournament(ctx context.Context, settings sts.TournamentSettings) (int64, error) {
+	args := c.Called(settings)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -40,7 +74,150 @@ func (c *Connector) GetTournament(ctx context.Context, id int64) (*sts.Tournamen
 	args := c.Called(id)
 	return args.Get(0).(*sts.Tournament), args.Error(1)
 }
-func (c *Connector) JoinTournament(ctx context.Context, tournamentID, userID int64) error {
+
+func (c *Connector) UpdateTournament(ctx context.Context, id int64, update sts.TournamentUpdate) error {
+	args := c.Called(id, update)
+	return args.Error(0)
+}
+
+func (c *Connector) CloneTournament(ctx context.Context, id int64) (int64, error) {
+	args := c.Called(id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (c *Connector) ListTournaments(ctx context.Context, q sts.TournamentQuery) (*sts.TournamentPage, error) {
+	args := c.Called(q)
+	return args.Get(0).(*sts.TournamentPage), args.Error(1)
+}
+
+func (c *Connector) JoinTournament(ctx context.Context, tournamentID, userID int64, inviteCode string) (bool, error) {
+	args := c.Called(tournamentID, userID, inviteCode)
+	return args.Bool(0), args.Error(1)
+}
+
+func (c *Connector) LeaveTournament(ctx context.Context, tournamentID, userID int64) error {
 	args := c.Called(tournamentID, userID)
 	return args.Error(0)
 }
+
+func (c *Connector) OpenRegistration(ctx context.Context, tournamentID int64) error {
+	args := c.Called(tournamentID)
+	return args.Error(0)
+}
+
+func (c *Connector) StartTournament(ctx context.Context, tournamentID int64) error {
+	args := c.Called(tournamentID)
+	return args.Error(0)
+}
+
+func (c *Connector) FinishTournament(ctx context.Context, tournamentID int64, ranking []int64) error {
+	args := c.Called(tournamentID, ranking)
+	return args.Error(0)
+}
+
+func (c *Connector) CancelTournament(ctx context.Context, tournamentID int64) error {
+	args := c.Called(tournamentID)
+	return args.Error(0)
+}
+
+func (c *Connector) GetLeaderboard(ctx context.Context, q sts.LeaderboardQuery) (*sts.Leaderboard, error) {
+	args := c.Called(q)
+	return args.Get(0).(*sts.Leaderboard), args.Error(1)
+}
+
+func (c *Connector) GetHouseAccount(ctx context.Context, name string, currency sts.Currency) (*sts.HouseAccount, error) {
+	args := c.Called(name, currency)
+	return args.Get(0).(*sts.HouseAccount), args.Error(1)
+}
+
+func (c *Connector) GenerateBracket(ctx context.Context, tournamentID int64, seeding []int64) error {
+	args := c.Called(tournamentID, seeding)
+	return args.Error(0)
+}
+
+func (c *Connector) GetBracket(ctx context.Context, tournamentID int64) (sts.Bracket, error) {
+	args := c.Called(tournamentID)
+	return args.Get(0).(sts.Bracket), args.Error(1)
+}
+
+func (c *Connector) ReportMatch(ctx context.Context, tournamentID int64, round, position uint32, winnerID int64) error {
+	args := c.Called(tournamentID, round, position, winnerID)
+	return args.Error(0)
+}
+
+func (c *Connector) PairRound(ctx context.Context, tournamentID int64) (sts.Pairings, error) {
+	args := c.Called(tournamentID)
+	return args.Get(0).(sts.Pairings), args.Error(1)
+}
+
+func (c *Connector) GetPairings(ctx context.Context, tournamentID int64) (sts.Pairings, error) {
+	args := c.Called(tournamentID)
+	return args.Get(0).(sts.Pairings), args.Error(1)
+}
+
+func (c *Connector) ReportResult(ctx context.Context, tournamentID int64, round, board uint32, result sts.Result) error {
+	args := c.Called(tournamentID, round, board, result)
+	return args.Error(0)
+}
+
+func (c *Connector) GetStandings(ctx context.Context, tournamentID int64) ([]sts.Standing, error) {
+	args := c.Called(tournamentID)
+	return args.Get(0).([]sts.Standing), args.Error(1)
+}
+
+func (c *Connector) JoinTournamentAsTeam(ctx context.Context, tournamentID, teamID int64, inviteCode string) error {
+	args := c.Called(tournamentID, teamID, inviteCode)
+	return args.Error(0)
+}
+
+func (c *Connector) CreateTeam(ctx context.Context, name string, captainID int64) (int64, error) {
+	args := c.Called(name, captainID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (c *Connector) GetTeam(ctx context.Context, id int64) (*sts.Team, error) {
+	args := c.Called(id)
+	return args.Get(0).(*sts.Team), args.Error(1)
+}
+
+func (c *Connector) InviteToTeam(ctx context.Context, teamID, userID int64) error {
+	args := c.Called(teamID, userID)
+	return args.Error(0)
+}
+
+func (c *Connector) JoinTeam(ctx context.Context, teamID, userID int64) error {
+	args := c.Called(teamID, userID)
+	return args.Error(0)
+}
+
+func (c *Connector) SetTeamShares(ctx context.Context, teamID int64, shares []sts.TeamMember) error {
+	args := c.Called(teamID, shares)
+	return args.Error(0)
+}
+
+func (c *Connector) CreateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	settings sts.InviteSettings) (*sts.InviteCode, error) {
+	args := c.Called(tournamentID, organizerID, settings)
+	return args.Get(0).(*sts.InviteCode), args.Error(1)
+}
+
+func (c *Connector) GetInviteCodes(ctx context.Context, tournamentID, organizerID int64) ([]sts.InviteCode, error) {
+	args := c.Called(tournamentID, organizerID)
+	return args.Get(0).([]sts.InviteCode), args.Error(1)
+}
+
+func (c *Connector) RevokeInviteCode(ctx context.Context, tournamentID, organizerID int64, code string) error {
+	args := c.Called(tournamentID, organizerID, code)
+	return args.Error(0)
+}
+
+func (c *Connector) RegenerateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	code string) (*sts.InviteCode, error) {
+	args := c.Called(tournamentID, organizerID, code)
+	return args.Get(0).(*sts.InviteCode), args.Error(1)
+}
+
+func (c *Connector) FundHouseAccount(ctx context.Context, name string, currency sts.Currency, points uint64) error {
+	args := c.Called(name, currency, points)
+	return args.Error(0)
+}
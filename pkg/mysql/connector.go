@@ -2,7 +2,9 @@ package mysql
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/illfate/social-tournaments-service/pkg/sts"
 	"github.com/jmoiron/sqlx"
 
 	// import mysql driver
@@ -11,20 +13,34 @@ import (
 
 // Connector provides connection to db.
 type Connector struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	now    func() time.Time
+	keyTTL time.Duration
 }
 
 // New constructs new connection to db.
 func New(dbUser, dbPass, dbName string) (*Connector, error) {
-	db, err := sqlx.Connect("mysql", fmt.Sprintf("%s:%s@/%s", dbUser, dbPass, dbName))
+	db, err := sqlx.Connect("mysql", fmt.Sprintf("%s:%s@/%s?parseTime=true", dbUser, dbPass, dbName))
 	if err != nil {
 		return nil, fmt.Errorf("can't open db: %s", err)
 	}
 	return &Connector{
-		db: db,
+		db:     db,
+		now:    time.Now,
+		keyTTL: sts.DefaultIdempotencyKeyTTL,
 	}, nil
 }
 
+// SetClock replaces function that is used to get current time, e.g. to check registration windows.
+func (c *Connector) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// SetIdempotencyKeyTTL changes how long idempotency keys are kept. Retries after that take effect again.
+func (c *Connector) SetIdempotencyKeyTTL(ttl time.Duration) {
+	c.keyTTL = ttl
+}
+
 // Close shuts down connection to db.
 func (c *Connector) Close() {
 	c.db.Close()
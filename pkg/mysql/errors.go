@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"fmt"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+// Numbers of mysql errors that break rules of db.
+const (
+	errBadNull            = 1048
+	errDupEntry           = 1062
+	errOutOfRange         = 1264
+	errTruncatedValue     = 1366
+	errDataTooLong        = 1406
+	errUnsignedOutOfRange = 1690
+	errCheckViolated      = 3819
+)
+
+// dbError translates err that breaks rules of db to an error of the service. Unsigned balance
+// that goes below zero is ErrInsufficientFunds, other values out of range and invalid data are
+// ErrValidation. The rest of errors are prefixed with message.
+func dbError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	mysqlErr, ok := errors.Cause(err).(*driver.MySQLError)
+	if !ok {
+		return fmt.Errorf("%s: %s", message, err)
+	}
+	switch mysqlErr.Number {
+	case errUnsignedOutOfRange:
+		return sts.ErrInsufficientFunds
+	case errBadNull, errOutOfRange, errTruncatedValue, errDataTooLong, errCheckViolated:
+		return sts.ErrValidation
+	}
+	return fmt.Errorf("%s: %s", message, err)
+}
+
+// duplicate reports whether err is a violation of a unique key.
+func duplicate(err error) bool {
+	mysqlErr, ok := errors.Cause(err).(*driver.MySQLError)
+	return ok && mysqlErr.Number == errDupEntry
+}
@@ -0,0 +1,192 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// addLot records that amount of points that has just been credited to user expires at expiresAt.
+func addLot(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount uint64,
+	expiresAt time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+	INSERT INTO point_lots (user_id, currency, amount, expires_at)
+	     VALUES (?, ?, ?, ?)`, userID, currency, amount, expiresAt)
+	if err != nil {
+		return dbError(err, "couldn't add expiring points")
+	}
+	return nil
+}
+
+// spendLots takes amount of points from expiring credits of user, the soonest ones first, and returns
+// how much has been taken from each credit. Credits that have expired by now aren't spent. Points
+// that are paid as entry fee are remembered, so that refund returns them to their credits.
+func spendLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount uint64,
+	reason sts.Reason, tournamentID int64, now time.Time) ([]sts.Lot, error) {
+	var lots []sts.Lot
+	err := tx.SelectContext(ctx, &lots, `
+	  SELECT id, amount, expires_at AS expiresat
+	    FROM point_lots
+	   WHERE user_id = ? AND currency = ? AND amount > 0
+	ORDER BY expires_at, id
+	     FOR UPDATE`, userID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get expiring points: %s", err)
+	}
+	spentLots := sts.SpendLots(lots, amount, now)
+	for _, lot := range spentLots {
+		_, err = tx.ExecContext(ctx, `
+		UPDATE point_lots
+		   SET amount = amount - ?
+		 WHERE id = ?`, lot.Amount, lot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't spend expiring points: %s", err)
+		}
+		if reason != sts.ReasonEntryFee || tournamentID == 0 {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO lot_spends (lot_id, tournament_id, amount)
+		     VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE amount = amount + VALUES(amount)`, lot.ID, tournamentID, lot.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't record spent points: %s", err)
+		}
+	}
+	return spentLots, nil
+}
+
+// restoreLots returns expiring points that user has paid as entry fee of tournament with passed
+// tournamentID to their credits. Points of credits that have already expired are taken by the next expiry.
+func restoreLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, tournamentID int64) error {
+	_, err := tx.ExecContext(ctx, `
+	UPDATE point_lots AS l
+	  JOIN lot_spends AS s ON s.lot_id = l.id
+	   SET l.amount = l.amount + s.amount
+	 WHERE s.tournament_id = ? AND l.user_id = ? AND l.currency = ?`, tournamentID, userID, currency)
+	if err != nil {
+		return fmt.Errorf("couldn't restore expiring points: %s", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+	DELETE s
+	  FROM lot_spends AS s
+	  JOIN point_lots AS l ON s.lot_id = l.id
+	 WHERE s.tournament_id = ? AND l.user_id = ? AND l.currency = ?`, tournamentID, userID, currency)
+	if err != nil {
+		return fmt.Errorf("couldn't forget spent points: %s", err)
+	}
+	return nil
+}
+
+// expirations returns points of user with passed userID that expire after now, the soonest ones first.
+func expirations(ctx context.Context, q sqlx.QueryerContext, userID int64, now time.Time) ([]sts.Expiration, error) {
+	result := []sts.Expiration{}
+	err := sqlx.SelectContext(ctx, q, &result, `
+	  SELECT currency, amount, expires_at AS expiresat
+	    FROM point_lots
+	   WHERE user_id = ? AND amount > 0 AND expires_at > ?
+	ORDER BY expires_at, id`, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load expirations: %s", err)
+	}
+	return result, nil
+}
+
+// ExpirePoints takes points that have expired by passed time from their owners
+// and records the expiry in the ledger.
+func (c *Connector) ExpirePoints(ctx context.Context, now time.Time) error {
+	var wallets []struct {
+		UserID   int64        `db:"user_id"`
+		Currency sts.Currency `db:"currency"`
+	}
+	err := c.db.SelectContext(ctx, &wallets, `
+	SELECT DISTINCT user_id, currency
+	  FROM point_lots
+	 WHERE expires_at <= ? AND amount > 0`, now)
+	if err != nil {
+		return fmt.Errorf("couldn't get expired points: %s", err)
+	}
+	for _, w := range wallets {
+		err = c.expireWallet(ctx, w.UserID, w.Currency, now)
+		if err != nil {
+			return err
+		}
+	}
+	// Credits are kept while their points can still be refunded from a tournament.
+	_, err = c.db.ExecContext(ctx, `
+	DELETE l
+	  FROM point_lots AS l
+	 WHERE l.amount = 0 AND l.expires_at <= ?
+	       AND NOT EXISTS(SELECT 1
+	                        FROM lot_spends AS s
+	                             JOIN tournaments AS t ON t.id = s.tournament_id
+	                       WHERE s.lot_id = l.id AND t.status NOT IN (?, ?))`,
+		now, sts.StatusFinished, sts.StatusCancelled)
+	if err != nil {
+		return fmt.Errorf("couldn't delete spent points: %s", err)
+	}
+	return nil
+}
+
+func (c *Connector) expireWallet(ctx context.Context, userID int64, currency sts.Currency, now time.Time) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = expireLots(ctx, tx, userID, currency, now)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// expireLots takes points of credits of user that have expired by now from the wallet of passed
+// currency and records the expiry in the ledger. Wallet stays locked until transaction ends.
+func expireLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, now time.Time) error {
+	// Wallet is locked before its credits, in the same order as changeBalance does.
+	err := lockWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return err
+	}
+	var lots []sts.Lot
+	err = tx.SelectContext(ctx, &lots, `
+	SELECT id, amount
+	  FROM point_lots
+	 WHERE user_id = ? AND currency = ? AND expires_at <= ? AND amount > 0
+	   FOR UPDATE`, userID, currency, now)
+	if err != nil {
+		return fmt.Errorf("couldn't get expired points: %s", err)
+	}
+	var expired uint64
+	for _, lot := range lots {
+		expired += lot.Amount
+		_, err = tx.ExecContext(ctx, `
+		UPDATE point_lots
+		   SET amount = 0
+		 WHERE id = ?`, lot.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't expire points: %s", err)
+		}
+	}
+	if expired == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE balances
+	   SET balance = balance - ?
+	 WHERE user_id = ? AND currency = ?`, expired, userID, currency)
+	if err != nil {
+		return fmt.Errorf("couldn't update balance: %s", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO transactions (user_id, amount, currency, reason)
+	     VALUES (?, ?, ?, ?)`, userID, -int64(expired), currency, sts.ReasonExpiry)
+	if err != nil {
+		return fmt.Errorf("couldn't record transaction: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// useIdempotencyKey claims idempotency key from ctx for passed request. If the request has already
+// taken effect with this key, function returns its stored response and true. If the key has been used
+// for another request, function returns ErrIdempotencyKeyReused. Expired keys are claimed anew.
+// Concurrent retries wait for the first one to commit or roll back its transaction.
+func (c *Connector) useIdempotencyKey(ctx context.Context, tx *sqlx.Tx, request string) (string, bool, error) {
+	key, ok := sts.IdempotencyKey(ctx)
+	if !ok {
+		return "", false, nil
+	}
+	if key == "" || len(key) > sts.MaxIdempotencyKeyLength {
+		return "", false, sts.ErrInvalidIdempotencyKey
+	}
+	now := c.now()
+	insert, err := tx.ExecContext(ctx, `
+	INSERT INTO idempotency_keys (idempotency_key, request, created_at)
+	     VALUES (?, ?, ?)
+	ON DUPLICATE KEY UPDATE idempotency_key = idempotency_key`, key, request, now)
+	if err != nil {
+		return "", false, fmt.Errorf("couldn't store idempotency key: %s", err)
+	}
+	rows, err := insert.RowsAffected()
+	if err != nil {
+		return "", false, err
+	}
+	if rows == 1 {
+		return "", false, nil
+	}
+
+	var stored struct {
+		Request   string    `db:"request"`
+		Response  string    `db:"response"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = tx.GetContext(ctx, &stored, `
+	SELECT request, response, created_at
+	  FROM idempotency_keys
+	 WHERE idempotency_key = ?
+	   FOR UPDATE`, key)
+	if err != nil {
+		return "", false, fmt.Errorf("couldn't get idempotency key: %s", err)
+	}
+	if !now.Before(stored.CreatedAt.Add(c.keyTTL)) {
+		_, err = tx.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		   SET request = ?, response = '', created_at = ?
+		 WHERE idempotency_key = ?`, request, now, key)
+		if err != nil {
+			return "", false, fmt.Errorf("couldn't renew idempotency key: %s", err)
+		}
+		return "", false, nil
+	}
+	if stored.Request != request {
+		return "", false, sts.ErrIdempotencyKeyReused
+	}
+	return stored.Response, true, nil
+}
+
+// saveIdempotentResponse stores response of request whose idempotency key is in ctx.
+func saveIdempotentResponse(ctx context.Context, tx *sqlx.Tx, response string) error {
+	key, ok := sts.IdempotencyKey(ctx)
+	if !ok {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+	UPDATE idempotency_keys
+	   SET response = ?
+	 WHERE idempotency_key = ?`, response, key)
+	if err != nil {
+		return fmt.Errorf("couldn't store idempotent response: %s", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes idempotency keys that have expired by passed time.
+func (c *Connector) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	_, err := c.db.ExecContext(ctx, `
+	DELETE
+	  FROM idempotency_keys
+	 WHERE created_at <= ?`, now.Add(-c.keyTTL))
+	if err != nil {
+		return fmt.Errorf("couldn't delete expired idempotency keys: %s", err)
+	}
+	return nil
+}
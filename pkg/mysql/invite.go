@@ -0,0 +1,221 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// inviteColumns lists columns of invite_codes table in the order scanInviteCode reads them.
+const inviteColumns = `code, tournament_id, expires_at, max_uses, uses, revoked, created_at`
+
+func scanInviteCode(row scanner, c *sts.InviteCode) error {
+	return row.Scan(&c.Code, &c.TournamentID, &c.ExpiresAt, &c.MaxUses, &c.Uses, &c.Revoked, &c.CreatedAt)
+}
+
+// lockInviteCode loads passed code of tournament with passed tournamentID and locks it until
+// the end of transaction. If code isn't found, function returns sql.ErrNoRows.
+func lockInviteCode(ctx context.Context, tx *sqlx.Tx, tournamentID int64, code string) (*sts.InviteCode, error) {
+	var invite sts.InviteCode
+	err := scanInviteCode(tx.QueryRowContext(ctx, `
+    SELECT `+inviteColumns+`
+      FROM invite_codes
+     WHERE code = ? AND tournament_id = ?
+       FOR UPDATE`, code, tournamentID), &invite)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// useInviteCode counts a use of passed code of locked private tournament. If code isn't valid
+// at passed time, function returns ErrInvalidInviteCode. Public tournaments don't need a code.
+func useInviteCode(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, code string, now time.Time) error {
+	if !t.IsPrivate() {
+		return nil
+	}
+	invite, err := lockInviteCode(ctx, tx, t.ID, code)
+	if err == sql.ErrNoRows {
+		return sts.ErrInvalidInviteCode
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't load invite code: %s", err)
+	}
+	if !invite.Valid(now) {
+		return sts.ErrInvalidInviteCode
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE invite_codes
+       SET uses = uses + 1
+     WHERE code = ?`, code)
+	if err != nil {
+		return fmt.Errorf("couldn't use invite code: %s", err)
+	}
+	return nil
+}
+
+// lockOrganizedTournament locks private tournament with passed id and checks that it's organized
+// by user with passed organizerID. See CreateInviteCode for errors.
+func lockOrganizedTournament(ctx context.Context, tx *sqlx.Tx, id, organizerID int64) error {
+	t, err := lockTournament(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	return t.CheckOrganizer(organizerID)
+}
+
+// CreateInviteCode generates a new invite code of private tournament with passed tournamentID.
+// If tournament isn't found, function returns ErrNotFound. If tournament is public, function
+// returns ErrPublicTournament. If user with passed organizerID doesn't organize tournament,
+// function returns ErrNotOrganizer.
+func (c *Connector) CreateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	settings sts.InviteSettings) (*sts.InviteCode, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := addInviteCode(ctx, tx, tournamentID, settings, c.now())
+	if err != nil {
+		return nil, err
+	}
+	return invite, tx.Commit()
+}
+
+func addInviteCode(ctx context.Context, tx *sqlx.Tx, tournamentID int64, settings sts.InviteSettings,
+	now time.Time) (*sts.InviteCode, error) {
+	code, err := sts.NewInviteCode()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate invite code: %s", err)
+	}
+	invite := sts.InviteCode{
+		Code:         code,
+		TournamentID: tournamentID,
+		ExpiresAt:    settings.ExpiresAt,
+		MaxUses:      settings.MaxUses,
+		CreatedAt:    now,
+	}
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO invite_codes (code, tournament_id, expires_at, max_uses, created_at)
+         VALUES (?, ?, ?, ?, ?)`, invite.Code, tournamentID, invite.ExpiresAt, invite.MaxUses, invite.CreatedAt)
+	if err != nil {
+		return nil, dbError(err, "couldn't add invite code")
+	}
+	return &invite, nil
+}
+
+// GetInviteCodes returns every invite code of private tournament with passed tournamentID,
+// starting from the oldest one. See CreateInviteCode for errors.
+func (c *Connector) GetInviteCodes(ctx context.Context, tournamentID, organizerID int64) ([]sts.InviteCode, error) {
+	var t sts.Tournament
+	err := scanTournament(c.db.QueryRowContext(ctx, `
+    SELECT `+tournamentColumns+`
+      FROM tournaments AS t
+     WHERE t.id = ?`, tournamentID), &t)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load tournament: %s", err)
+	}
+	err = t.CheckOrganizer(organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+    SELECT `+inviteColumns+`
+      FROM invite_codes
+     WHERE tournament_id = ?
+  ORDER BY created_at, code`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get invite codes: %s", err)
+	}
+	defer rows.Close()
+	codes := []sts.InviteCode{}
+	for rows.Next() {
+		var invite sts.InviteCode
+		err = scanInviteCode(rows, &invite)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan invite code: %s", err)
+		}
+		codes = append(codes, invite)
+	}
+	return codes, rows.Err()
+}
+
+// RevokeInviteCode makes passed code of private tournament with passed tournamentID unusable.
+// If code isn't found, function returns ErrNotFound. See CreateInviteCode for other errors.
+func (c *Connector) RevokeInviteCode(ctx context.Context, tournamentID, organizerID int64, code string) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return err
+	}
+	_, err = revokeInviteCode(ctx, tx, tournamentID, code)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// revokeInviteCode revokes passed code of tournament with passed tournamentID and returns its
+// limits. If code isn't found, function returns ErrNotFound.
+func revokeInviteCode(ctx context.Context, tx *sqlx.Tx, tournamentID int64, code string) (*sts.InviteSettings,
+	error) {
+	invite, err := lockInviteCode(ctx, tx, tournamentID, code)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load invite code: %s", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE invite_codes
+       SET revoked = TRUE
+     WHERE code = ?`, code)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't revoke invite code: %s", err)
+	}
+	return &sts.InviteSettings{
+		ExpiresAt: invite.ExpiresAt,
+		MaxUses:   invite.MaxUses,
+	}, nil
+}
+
+// RegenerateInviteCode revokes passed code of private tournament with passed tournamentID and
+// returns a new code with the same limits. Uses of the new code start from zero. If code isn't
+// found, function returns ErrNotFound. See CreateInviteCode for other errors.
+func (c *Connector) RegenerateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	code string) (*sts.InviteCode, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := revokeInviteCode(ctx, tx, tournamentID, code)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := addInviteCode(ctx, tx, tournamentID, *settings, c.now())
+	if err != nil {
+		return nil, err
+	}
+	return invite, tx.Commit()
+}
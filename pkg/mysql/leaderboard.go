@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// addLeaderboardStats adds results of tournament finished at passed time to leaderboards
+// of every period.
+func addLeaderboardStats(ctx context.Context, tx *sqlx.Tx, results []sts.LeaderboardEntry, now time.Time) error {
+	for _, period := range sts.Periods {
+		for _, r := range results {
+			_, err := tx.ExecContext(ctx, `
+    INSERT INTO leaderboard_stats (period, period_start, user_id, prize, wins, tournaments, profit)
+         VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE prize = prize + VALUES(prize), wins = wins + VALUES(wins),
+                        tournaments = tournaments + VALUES(tournaments), profit = profit + VALUES(profit)`,
+				period, sts.PeriodStart(period, now), r.UserID, r.Prize, r.Wins, r.Tournaments, r.Profit)
+			if err != nil {
+				return fmt.Errorf("couldn't update leaderboard: %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// leaderboardColumn returns column of leaderboard_stats that holds passed statistic.
+func leaderboardColumn(order sts.LeaderboardOrder) string {
+	switch order {
+	case sts.OrderWins:
+		return "wins"
+	case sts.OrderProfit:
+		return "profit"
+	}
+	return "prize"
+}
+
+// GetLeaderboard returns a page of leaderboard of users that have finished tournaments in
+// requested period. If query is incorrect, function returns ErrInvalidLeaderboard.
+func (c *Connector) GetLeaderboard(ctx context.Context, q sts.LeaderboardQuery) (*sts.Leaderboard, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	at := q.At
+	if at.IsZero() {
+		at = c.now()
+	}
+	start := sts.PeriodStart(q.Period, at)
+	column := leaderboardColumn(q.Order)
+
+	rows, err := c.db.QueryContext(ctx, `
+    SELECT user_id, prize, wins, tournaments, profit
+      FROM leaderboard_stats
+     WHERE period = ? AND period_start = ?
+  ORDER BY `+column+` DESC, user_id
+     LIMIT ? OFFSET ?`, q.Period, start, q.Limit, q.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get leaderboard: %s", err)
+	}
+	defer rows.Close()
+	leaderboard := sts.Leaderboard{
+		Entries: []sts.LeaderboardEntry{},
+	}
+	for rows.Next() {
+		var e sts.LeaderboardEntry
+		err = rows.Scan(&e.UserID, &e.Prize, &e.Wins, &e.Tournaments, &e.Profit)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan leaderboard entry: %s", err)
+		}
+		leaderboard.Entries = append(leaderboard.Entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(leaderboard.Entries) != 0 {
+		first := leaderboard.Entries[0]
+		rank, err := c.leaderboardRank(ctx, q.Period, start, column, first.Value(q.Order))
+		if err != nil {
+			return nil, err
+		}
+		sts.RankEntries(leaderboard.Entries, q.Order, rank, q.Offset)
+	}
+
+	if q.UserID == 0 {
+		return &leaderboard, nil
+	}
+	var me sts.LeaderboardEntry
+	err = c.db.QueryRowContext(ctx, `
+    SELECT user_id, prize, wins, tournaments, profit
+      FROM leaderboard_stats
+     WHERE period = ? AND period_start = ? AND user_id = ?`, q.Period, start, q.UserID).Scan(&me.UserID, &me.Prize,
+		&me.Wins, &me.Tournaments, &me.Profit)
+	if err == sql.ErrNoRows {
+		return &leaderboard, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get leaderboard entry: %s", err)
+	}
+	me.Rank, err = c.leaderboardRank(ctx, q.Period, start, column, me.Value(q.Order))
+	if err != nil {
+		return nil, err
+	}
+	leaderboard.Me = &me
+	return &leaderboard, nil
+}
+
+// leaderboardRank returns rank of passed value of column in leaderboard of passed period.
+func (c *Connector) leaderboardRank(ctx context.Context, period sts.Period, start time.Time, column string,
+	value int64) (uint64, error) {
+	var higher uint64
+	err := c.db.QueryRowContext(ctx, `
+    SELECT COUNT(*)
+      FROM leaderboard_stats
+     WHERE period = ? AND period_start = ? AND `+column+` > ?`, period, start, value).Scan(&higher)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get leaderboard rank: %s", err)
+	}
+	return higher + 1, nil
+}
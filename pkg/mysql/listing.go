@@ -0,0 +1,128 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+// tournamentSort holds a condition that skips tournaments up to the cursor and an order of
+// listed tournaments. The condition takes id of the cursor, its key and its id again.
+type tournamentSort struct {
+	after string
+	order string
+}
+
+var tournamentSorts = map[sts.TournamentSort]tournamentSort{
+	sts.SortNewest: {
+		after: "(? = 0 OR (t.created_at, t.id) < (?, ?))",
+		order: "t.created_at DESC, t.id DESC",
+	},
+	sts.SortLargestPrize: {
+		after: "(? = 0 OR (t.prize, t.id) < (?, ?))",
+		order: "t.prize DESC, t.id DESC",
+	},
+	sts.SortSoonestStart: {
+		after: "t.starts_at IS NOT NULL AND (? = 0 OR (t.starts_at, t.id) > (?, ?))",
+		order: "t.starts_at, t.id",
+	},
+}
+
+// ListTournaments returns a page of tournaments that are visible to the viewer of the query and
+// match its filters, in its sort order and starting after its cursor. If query is incorrect,
+// function returns ErrInvalidListing.
+func (c *Connector) ListTournaments(ctx context.Context, q sts.TournamentQuery) (*sts.TournamentPage, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	key, afterID, err := sts.DecodeCursor(q.After)
+	if err != nil {
+		return nil, err
+	}
+	var after interface{} = key
+	if q.Sort != sts.SortLargestPrize {
+		after = sts.CursorTime(key)
+	}
+	sort := tournamentSorts[q.Sort]
+	var ids []int64
+	err = c.db.SelectContext(ctx, &ids, `
+	  SELECT t.id
+	    FROM tournaments AS t
+	   WHERE (t.visibility = 'public' OR
+	          ? <> 0 AND (t.organizer_id = ? OR
+	                      EXISTS(SELECT 1 FROM participants AS p WHERE p.tournament_id = t.id AND p.user_id = ?) OR
+	                      EXISTS(SELECT 1 FROM waitlist AS w WHERE w.tournament_id = t.id AND w.user_id = ?)))
+	     AND (? = '' OR t.status = ?)
+	     AND t.deposit >= ? AND (? = 0 OR t.deposit <= ?)
+	     AND t.name LIKE ?
+	     AND (? IS NULL OR t.created_at >= ?)
+	     AND (? IS NULL OR t.created_at < ?)
+	     AND `+sort.after+`
+	ORDER BY `+sort.order+`
+	   LIMIT ?`, q.ViewerID, q.ViewerID, q.ViewerID, q.ViewerID, q.Status, q.Status, q.MinDeposit, q.MaxDeposit, q.MaxDeposit, likePrefix(q.NamePrefix),
+		q.CreatedAfter, q.CreatedAfter, q.CreatedBefore, q.CreatedBefore, afterID, after, afterID, q.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list tournaments: %s", err)
+	}
+	page := sts.TournamentPage{
+		Tournaments: []sts.Tournament{},
+	}
+	for i, id := range ids {
+		if uint64(i) == q.Limit {
+			page.NextCursor = sts.TournamentCursor(&page.Tournaments[i-1], q.Sort)
+			break
+		}
+		t, err := c.GetTournament(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		page.Tournaments = append(page.Tournaments, *t)
+	}
+	return &page, nil
+}
+
+// ListUsers returns a page of users ordered by id, starting after the cursor of the query.
+// If query is incorrect, function returns ErrInvalidListing.
+func (c *Connector) ListUsers(ctx context.Context, q sts.UserQuery) (*sts.UserPage, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	_, afterID, err := sts.DecodeCursor(q.After)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	err = c.db.SelectContext(ctx, &ids, `
+	  SELECT id
+	    FROM users
+	   WHERE id > ?
+	ORDER BY id
+	   LIMIT ?`, afterID, q.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list users: %s", err)
+	}
+	page := sts.UserPage{
+		Users: []sts.User{},
+	}
+	for i, id := range ids {
+		if uint64(i) == q.Limit {
+			page.NextCursor = sts.UserCursor(page.Users[i-1].ID)
+			break
+		}
+		user, err := c.GetUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		page.Users = append(page.Users, *user)
+	}
+	return &page, nil
+}
+
+// likePrefix returns a pattern of LIKE that matches strings starting with prefix.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// checkRating returns ErrRatingOutOfRange if rating of user with passed userID doesn't allow
+// to join tournament. If user isn't found, function returns ErrNotFound.
+func checkRating(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, userID int64) error {
+	if t.MinRating == 0 && t.MaxRating == 0 {
+		return nil
+	}
+	var rating float64
+	err := tx.QueryRowContext(ctx, `
+    SELECT rating
+      FROM users
+     WHERE id = ?`, userID).Scan(&rating)
+	if err == sql.ErrNoRows {
+		return sts.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't get user rating: %s", err)
+	}
+	if !t.InRatingRange(rating) {
+		return sts.ErrRatingOutOfRange
+	}
+	return nil
+}
+
+// rateGames updates ratings of users that have played passed games of tournament with
+// passed tournamentID and records new ratings in the rating history.
+func rateGames(ctx context.Context, tx *sqlx.Tx, tournamentID int64, games []sts.Game) error {
+	ratings := make(map[int64]sts.Glicko)
+	for _, g := range games {
+		for _, id := range []int64{g.User1, g.User2} {
+			if _, ok := ratings[id]; ok {
+				continue
+			}
+			var r sts.Glicko
+			err := tx.QueryRowContext(ctx, `
+    SELECT rating, rating_deviation, rating_volatility
+      FROM users
+     WHERE id = ?
+       FOR UPDATE`, id).Scan(&r.Rating, &r.Deviation, &r.Volatility)
+			if err == sql.ErrNoRows {
+				return sts.ErrNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("couldn't load rating: %s", err)
+			}
+			ratings[id] = r
+		}
+	}
+	updated, played := sts.RateGames(ratings, games)
+	for id, r := range updated {
+		_, err := tx.ExecContext(ctx, `
+    UPDATE users
+       SET rating = ?, rating_deviation = ?, rating_volatility = ?, games = games + ?
+     WHERE id = ?`, r.Rating, r.Deviation, r.Volatility, played[id], id)
+		if err != nil {
+			return fmt.Errorf("couldn't update rating: %s", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+    INSERT INTO rating_history (user_id, tournament_id, rating, deviation, volatility)
+         VALUES (?, ?, ?, ?, ?)`, id, nullID(tournamentID), r.Rating, r.Deviation, r.Volatility)
+		if err != nil {
+			return fmt.Errorf("couldn't add rating history: %s", err)
+		}
+	}
+	return nil
+}
+
+// GetRatingHistory returns rating changes of user with passed userID, starting from the oldest
+// one. If user isn't found, function returns ErrNotFound.
+func (c *Connector) GetRatingHistory(ctx context.Context, userID int64) ([]sts.RatingChange, error) {
+	rows, err := c.db.QueryContext(ctx, `
+    SELECT id, user_id, tournament_id, rating, deviation, created_at
+      FROM rating_history
+     WHERE user_id = ?
+  ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get rating history: %s", err)
+	}
+	defer rows.Close()
+	history := []sts.RatingChange{}
+	for rows.Next() {
+		var (
+			c            sts.RatingChange
+			tournamentID sql.NullInt64
+		)
+		err = rows.Scan(&c.ID, &c.UserID, &tournamentID, &c.Rating, &c.Deviation, &c.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan rating change: %s", err)
+		}
+		c.TournamentID = tournamentID.Int64
+		history = append(history, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		_, err = c.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+// schedulerLock is a name of the lock that is held by the scheduler doing the work.
+const schedulerLock = "sts_scheduler"
+
+// DueTournaments returns tournaments whose status has to be changed at passed time
+// according to their schedule.
+func (c *Connector) DueTournaments(ctx context.Context, now time.Time) (*sts.DueTournaments, error) {
+	var due sts.DueTournaments
+	err := c.db.SelectContext(ctx, &due.Open, `
+    SELECT id
+      FROM tournaments
+     WHERE status = ? AND registration_opens_at <= ?`, sts.StatusDraft, now)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get tournaments to open: %s", err)
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+    SELECT t.id, t.min_players,
+           CASE WHEN t.team_based THEN COUNT(DISTINCT p.team_id) ELSE COUNT(p.user_id) END
+      FROM tournaments AS t
+ LEFT JOIN participants AS p ON t.id = p.tournament_id
+     WHERE t.status = ? AND t.starts_at <= ?
+  GROUP BY t.id`, sts.StatusRegistration, now)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get tournaments to start: %s", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id       int64
+			settings sts.TournamentSettings
+			entries  int
+		)
+		// min_players of team-based tournament limits the number of teams, like entrants counts them
+		err = rows.Scan(&id, &settings.MinPlayers, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan tournament: %s", err)
+		}
+		if settings.HasEnoughPlayers(entries) {
+			due.Start = append(due.Start, id)
+		} else {
+			due.Cancel = append(due.Cancel, id)
+		}
+	}
+	return &due, rows.Err()
+}
+
+// TryLock tries to take the lock that is shared by every scheduler working with the db.
+// If the lock is taken, function returns function that releases it. Otherwise it returns nil.
+func (c *Connector) TryLock(ctx context.Context) (func() error, error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get connection: %s", err)
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT COALESCE(GET_LOCK(?, 0), 0)`, schedulerLock).Scan(&locked)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("couldn't take lock: %s", err)
+	}
+	if !locked {
+		return nil, conn.Close()
+	}
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, schedulerLock)
+		if err != nil {
+			return fmt.Errorf("couldn't release lock: %s", err)
+		}
+		return nil
+	}, nil
+}
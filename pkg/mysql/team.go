@@ -0,0 +1,402 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateTeam adds team with passed name and makes user with passed captainID its captain and
+// the only member. It returns id of this team. If user isn't found, function returns ErrNotFound.
+func (c *Connector) CreateTeam(ctx context.Context, name string, captainID int64) (int64, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	insert, err := tx.ExecContext(ctx, `
+    INSERT INTO teams (name, captain_id)
+         SELECT ?, id
+           FROM users
+          WHERE id = ?`, name, captainID)
+	if err != nil {
+		return 0, dbError(err, "couldn't add team")
+	}
+	rows, err := insert.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return 0, sts.ErrNotFound
+	}
+	id, err := insert.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO team_members (team_id, user_id, share)
+         VALUES (?, ?, 100)`, id, captainID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't add team captain: %s", err)
+	}
+	return id, tx.Commit()
+}
+
+// GetTeam returns team with passed id. If team isn't found, function returns ErrNotFound.
+func (c *Connector) GetTeam(ctx context.Context, id int64) (*sts.Team, error) {
+	var team sts.Team
+	err := c.db.QueryRowContext(ctx, `
+    SELECT id, name, captain_id
+      FROM teams
+     WHERE id = ?`, id).Scan(&team.ID, &team.Name, &team.CaptainID)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get team: %s", err)
+	}
+	team.Members, err = teamMembers(ctx, c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	team.Invited = []int64{}
+	err = c.db.SelectContext(ctx, &team.Invited, `
+    SELECT user_id
+      FROM team_invites
+     WHERE team_id = ?
+  ORDER BY user_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load team invites: %s", err)
+	}
+	return &team, nil
+}
+
+func teamMembers(ctx context.Context, q sqlx.QueryerContext, teamID int64) ([]sts.TeamMember, error) {
+	rows, err := q.QueryContext(ctx, `
+    SELECT user_id, share
+      FROM team_members
+     WHERE team_id = ?
+  ORDER BY user_id`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load team members: %s", err)
+	}
+	defer rows.Close()
+	members := []sts.TeamMember{}
+	for rows.Next() {
+		var m sts.TeamMember
+		err = rows.Scan(&m.UserID, &m.Share)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan team member: %s", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// lockTeam locks team with passed id until the end of transaction and returns its members.
+// If team isn't found, function returns ErrNotFound.
+func lockTeam(ctx context.Context, tx *sqlx.Tx, id int64) ([]sts.TeamMember, error) {
+	err := tx.QueryRowContext(ctx, `
+    SELECT id
+      FROM teams
+     WHERE id = ?
+       FOR UPDATE`, id).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load team: %s", err)
+	}
+	return teamMembers(ctx, tx, id)
+}
+
+// InviteToTeam allows user with passed userID to join team with passed teamID. Inviting a member
+// of the team does nothing. If team or user isn't found, function returns ErrNotFound.
+func (c *Connector) InviteToTeam(ctx context.Context, teamID, userID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			return nil
+		}
+	}
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+    SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)`, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("couldn't check user: %s", err)
+	}
+	if !exists {
+		return sts.ErrNotFound
+	}
+	_, err = tx.ExecContext(ctx, `
+    INSERT IGNORE INTO team_invites (team_id, user_id)
+                VALUES (?, ?)`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't invite user to team: %s", err)
+	}
+	return tx.Commit()
+}
+
+// JoinTeam adds invited user with passed userID to team with passed teamID and splits shares
+// of the team equally between its members. If team isn't found, function returns ErrNotFound.
+// If user hasn't been invited, function returns ErrNotInvited. If team already has
+// MaxTeamMembers members, function returns ErrTeamFull.
+func (c *Connector) JoinTeam(ctx context.Context, teamID, userID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	delete, err := tx.ExecContext(ctx, `
+    DELETE
+      FROM team_invites
+     WHERE team_id = ? AND user_id = ?`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't remove team invite: %s", err)
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sts.ErrNotInvited
+	}
+	if len(members) >= sts.MaxTeamMembers {
+		return sts.ErrTeamFull
+	}
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO team_members (team_id, user_id, share)
+         VALUES (?, ?, 1)`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't add user to team: %s", err)
+	}
+	members = append(members, sts.TeamMember{UserID: userID})
+	for i, share := range sts.EqualShares(len(members)) {
+		members[i].Share = share
+	}
+	err = setTeamShares(ctx, tx, teamID, members)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTeamShares sets percents of deposits and prizes that fall on members of team with passed
+// teamID. If team isn't found, function returns ErrNotFound. If shares aren't set for every
+// member, aren't positive or don't sum up to 100, function returns ErrInvalidTeamShares.
+func (c *Connector) SetTeamShares(ctx context.Context, teamID int64, shares []sts.TeamMember) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	err = sts.ValidateTeamShares(shares, members)
+	if err != nil {
+		return err
+	}
+	err = setTeamShares(ctx, tx, teamID, shares)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setTeamShares(ctx context.Context, tx *sqlx.Tx, teamID int64, shares []sts.TeamMember) error {
+	for _, m := range shares {
+		_, err := tx.ExecContext(ctx, `
+    UPDATE team_members
+       SET share = ?
+     WHERE team_id = ? AND user_id = ?`, m.Share, teamID, m.UserID)
+		if err != nil {
+			return fmt.Errorf("couldn't update team share: %s", err)
+		}
+	}
+	return nil
+}
+
+// teamEntries returns teams that have joined tournament with passed tournamentID with shares
+// of their members, ordered by team id.
+func teamEntries(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]sts.TeamEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+    SELECT team_id, user_id, share
+      FROM participants
+     WHERE tournament_id = ? AND team_id IS NOT NULL
+  ORDER BY team_id, user_id`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load teams: %s", err)
+	}
+	defer rows.Close()
+	var entries []sts.TeamEntry
+	for rows.Next() {
+		var (
+			teamID int64
+			m      sts.TeamMember
+		)
+		err = rows.Scan(&teamID, &m.UserID, &m.Share)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan team member: %s", err)
+		}
+		if len(entries) == 0 || entries[len(entries)-1].TeamID != teamID {
+			entries = append(entries, sts.TeamEntry{TeamID: teamID})
+		}
+		last := &entries[len(entries)-1]
+		last.Members = append(last.Members, m)
+	}
+	return entries, rows.Err()
+}
+
+// JoinTournamentAsTeam registers team with passed teamID in team-based tournament with passed
+// tournamentID. Deposit is charged to team members according to their shares. If tournament,
+// team or one of its members isn't found, function returns ErrNotFound. If tournament isn't
+// team-based, function returns ErrSoloTournament. If tournament isn't open for registration or
+// current time is out of its registration window, function returns ErrTournamentClosed. If rating
+// of a member is out of tournament range, function returns ErrRatingOutOfRange. If tournament
+// is full, function returns ErrTournamentFull. If a member has already joined tournament,
+// function returns ErrAlreadyJoined. Private tournament requires a valid invite code,
+// otherwise function returns ErrInvalidInviteCode. Retries with the same idempotency key in ctx
+// succeed without joining again.
+func (c *Connector) JoinTournamentAsTeam(ctx context.Context, tournamentID, teamID int64, inviteCode string) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, done, err := c.useIdempotencyKey(ctx, tx, sts.IdempotentRequest("join_team", tournamentID, teamID, inviteCode))
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if !t.TeamBased {
+		return sts.ErrSoloTournament
+	}
+	if t.Status != sts.StatusRegistration || !t.InRegistrationWindow(c.now()) {
+		return sts.ErrTournamentClosed
+	}
+	err = useInviteCode(ctx, tx, t, inviteCode, c.now())
+	if err != nil {
+		return err
+	}
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		err = checkRating(ctx, tx, t, m.UserID)
+		if err != nil {
+			return err
+		}
+	}
+	teams, err := teamEntries(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.IsFull(len(teams)) {
+		return sts.ErrTournamentFull
+	}
+	for _, team := range teams {
+		for _, joined := range team.Members {
+			for _, m := range members {
+				if joined.UserID == m.UserID {
+					return sts.ErrAlreadyJoined
+				}
+			}
+		}
+	}
+
+	entry := sts.TeamEntry{TeamID: teamID, Members: members}
+	for i, deposit := range entry.Split(t.Deposit) {
+		err = changeBalance(ctx, tx, members[i].UserID, t.Currency, -int64(deposit), sts.ReasonEntryFee,
+			tournamentID, c.now())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+    INSERT INTO participants (user_id, tournament_id, team_id, share)
+         VALUES (?, ?, ?, ?)`, members[i].UserID, tournamentID, teamID, members[i].Share)
+		if duplicate(err) {
+			return sts.ErrAlreadyJoined
+		}
+		if err != nil {
+			return dbError(err, "couldn't add team member to tournament")
+		}
+	}
+	err = addEntries(ctx, tx, t, 1)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// finishTeams pays out prize of locked team-based tournament according to passed ranking of teams
+// and returns results of team members.
+func finishTeams(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64,
+	shares []uint32, now time.Time) ([]sts.LeaderboardEntry, error) {
+	entries, err := teamEntries(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	teams := make(map[int64]sts.TeamEntry, len(entries))
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		teams[e.TeamID] = e
+		ids = append(ids, e.TeamID)
+	}
+	err = sts.ValidateRanking(ranking, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := sts.TeamPayouts(t.PrizePool(), shares, ranking)
+	for _, p := range payouts {
+		_, err = tx.ExecContext(ctx, `
+    UPDATE payouts
+       SET amount = ?, team_id = ?
+     WHERE tournament_id = ? AND place = ?`, p.Amount, p.TeamID, t.ID, p.Place)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't save payout: %s", err)
+		}
+	}
+	results := sts.TeamResults(ranking, payouts, t.Deposit, teams)
+	for _, r := range results {
+		if r.Prize > 0 {
+			err = changeBalance(ctx, tx, r.UserID, t.Currency, int64(r.Prize), sts.ReasonPrize, t.ID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE tournaments
+       SET winner_team = ?
+     WHERE id = ?`, ranking[0], t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't finish tournament: %s", err)
+	}
+	return results, nil
+}
@@ -4,44 +4,320 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
 )
 
-// AddTournament adds tournament with passed name and deposit. Return id of this tournament.
-func (c *Connector) AddTournament(ctx context.Context, name string, deposit uint64) (int64, error) {
-	insert, err := c.db.ExecContext(ctx, `
- INSERT INTO tournaments (name,deposit)
- 	  VALUES (?, ?)`,
-		name, deposit)
+// tournamentColumns lists columns of tournaments table in the order scanTournament reads them.
+const tournamentColumns = `t.id, t.name, t.deposit, t.rake_type, t.rake, t.min_players, t.max_players,
+       t.registration_opens_at, t.registration_closes_at, t.starts_at, t.format, t.rounds, t.min_rating,
+       t.max_rating, t.team_based, t.visibility, t.organizer_id, t.status, t.gross_prize, t.prize, t.winner,
+       t.winner_team, t.guaranteed_prize, t.sponsor, t.overlay, t.currency, t.created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTournament reads tournamentColumns into t followed by passed extra destinations.
+func scanTournament(row scanner, t *sts.Tournament, extra ...interface{}) error {
+	var (
+		organizer, winner, winnerTeam sql.NullInt64
+		sponsor                       sql.NullString
+	)
+	dest := []interface{}{&t.ID, &t.Name, &t.Deposit, &t.RakeType, &t.Rake, &t.MinPlayers, &t.MaxPlayers,
+		&t.RegistrationOpensAt, &t.RegistrationClosesAt, &t.StartsAt, &t.Format, &t.Rounds, &t.MinRating,
+		&t.MaxRating, &t.TeamBased, &t.Visibility, &organizer, &t.Status, &t.GrossPrize, &t.Prize, &winner,
+		&winnerTeam, &t.GuaranteedPrize, &sponsor, &t.Overlay,
+		&t.Currency, &t.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+	t.OrganizerID = organizer.Int64
+	t.Sponsor = sponsor.String
+	if t.Status == sts.StatusFinished {
+		if !winner.Valid && !winnerTeam.Valid {
+			return fmt.Errorf("no winner")
+		}
+		t.Winner = winner.Int64
+		t.WinnerTeam = winnerTeam.Int64
+	}
+	return nil
+}
+
+// lockTournament loads tournament with passed id and locks it until the end of transaction.
+// If tournament isn't found, function returns ErrNotFound.
+func lockTournament(ctx context.Context, tx *sqlx.Tx, id int64) (*sts.Tournament, error) {
+	var t sts.Tournament
+	err := scanTournament(tx.QueryRowContext(ctx, `
+    SELECT `+tournamentColumns+`
+      FROM tournaments AS t
+     WHERE t.id = ?
+       FOR UPDATE`, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load tournament: %s", err)
+	}
+	return &t, nil
+}
+
+// loadTournament locks tournament with passed id like lockTournament and loads its prize shares,
+// participants, teams and waitlist through tx, so they stay as loaded until the end of transaction.
+func loadTournament(ctx context.Context, tx *sqlx.Tx, id int64) (*sts.Tournament, error) {
+	t, err := lockTournament(ctx, tx, id)
 	if err != nil {
-		return 0, fmt.Errorf("couldn't add tournament: %s", err)
+		return nil, err
+	}
+	payouts, err := getPayouts(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.PrizeShares = make([]uint32, 0, len(payouts))
+	for _, p := range payouts {
+		t.PrizeShares = append(t.PrizeShares, p.Share)
+	}
+	t.Users, err = participants(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Waitlist, err = waitlist(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.TeamBased {
+		teams, err := teamEntries(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		t.Teams = make([]int64, 0, len(teams))
+		for _, team := range teams {
+			t.Teams = append(t.Teams, team.TeamID)
+		}
+	}
+	return t, nil
+}
+
+// checkTournament returns ErrNotFound if tournament with passed id doesn't exist.
+func (c *Connector) checkTournament(ctx context.Context, id int64) error {
+	var exists bool
+	err := c.db.QueryRowContext(ctx, `
+    SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = ?)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("couldn't check tournament: %s", err)
+	}
+	if !exists {
+		return sts.ErrNotFound
+	}
+	return nil
+}
+
+func participants(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]int64, error) {
+	var users []int64
+	err := sqlx.SelectContext(ctx, q, &users, `
+    SELECT user_id
+      FROM participants
+     WHERE tournament_id = ?`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load participants: %s", err)
+	}
+	return users, nil
+}
+
+// entrants returns number of entries of tournament, i.e. number of teams that have joined
+// team-based tournament or number of participants otherwise.
+func entrants(ctx context.Context, q sqlx.QueryerContext, t *sts.Tournament) (int, error) {
+	var count int
+	err := q.QueryRowxContext(ctx, `
+    SELECT IF(?, COUNT(DISTINCT team_id), COUNT(*))
+      FROM participants
+     WHERE tournament_id = ?`, t.TeamBased, t.ID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't count participants: %s", err)
+	}
+	return count, nil
+}
+
+// AddTournament adds tournament with passed settings in draft status. Return id of this tournament.
+// If settings are incorrect, function returns ErrInvalidPrizeShares, ErrInvalidRake,
+// ErrInvalidCapacity, ErrInvalidSchedule, ErrInvalidFormat, ErrInvalidRatingRange,
+// ErrInvalidVisibility, ErrInvalidGuarantee or ErrInvalidCurrency.
+func (c *Connector) AddTournament(ctx context.Context, settings sts.TournamentSettings) (int64, error) {
+	err := settings.Validate()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	id, err := addTournament(ctx, tx, settings)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// addTournament adds tournament with passed validated settings in draft status and returns its id.
+func addTournament(ctx context.Context, tx *sqlx.Tx, settings sts.TournamentSettings) (int64, error) {
+	err := checkCurrency(ctx, tx, settings.Currency)
+	if err != nil {
+		return 0, err
+	}
+	err = checkSponsor(ctx, tx, settings.Sponsor, settings.Currency)
+	if err != nil {
+		return 0, err
+	}
+	insert, err := tx.ExecContext(ctx, `
+ INSERT INTO tournaments (name, deposit, rake_type, rake, min_players, max_players,
+                          registration_opens_at, registration_closes_at, starts_at, format, rounds,
+                          min_rating, max_rating, team_based, visibility, organizer_id, guaranteed_prize,
+                          sponsor, currency)
+ 	  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		settings.Name, settings.Deposit, settings.RakeType, settings.Rake, settings.MinPlayers, settings.MaxPlayers,
+		settings.RegistrationOpensAt, settings.RegistrationClosesAt, settings.StartsAt, settings.Format, settings.Rounds,
+		settings.MinRating, settings.MaxRating, settings.TeamBased, settings.Visibility, nullID(settings.OrganizerID),
+		settings.GuaranteedPrize, nullAccount(settings.Sponsor), settings.Currency)
+	if err != nil {
+		return 0, dbError(err, "couldn't add tournament")
 	}
 	id, err := insert.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
-	return id, nil
+	return id, addPayouts(ctx, tx, id, settings.PrizeShares)
+}
+
+// addPayouts adds shares of prize that are paid to each place of tournament with passed id.
+func addPayouts(ctx context.Context, tx *sqlx.Tx, tournamentID int64, shares []uint32) error {
+	for i, share := range shares {
+		_, err := tx.ExecContext(ctx, `
+	INSERT INTO payouts (tournament_id, place, share)
+	     VALUES (?, ?, ?)`, tournamentID, i+1, share)
+		if err != nil {
+			return dbError(err, "couldn't add payout")
+		}
+	}
+	return nil
+}
+
+// UpdateTournament applies passed changes to settings of tournament with passed id. Every setting
+// can be changed while nobody has joined tournament or its waitlist, afterwards only name can be
+// changed, otherwise function returns ErrTournamentLocked. If tournament isn't found, function
+// returns ErrNotFound. If tournament has started, function returns ErrTournamentClosed. Budget
+// of open tournament is reserved for the changed guaranteed prize, if sponsor doesn't have enough
+// points, function returns ErrInsufficientBudget. See AddTournament for errors of incorrect settings.
+func (c *Connector) UpdateTournament(ctx context.Context, id int64, update sts.TournamentUpdate) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// lock keeps players from joining until settings are replaced
+	t, err := loadTournament(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	settings := update.Apply(t.TournamentSettings)
+	err = settings.Validate()
+	if err != nil {
+		return err
+	}
+	err = t.CheckUpdate(settings)
+	if err != nil {
+		return err
+	}
+	err = checkCurrency(ctx, tx, settings.Currency)
+	if err != nil {
+		return err
+	}
+	err = checkSponsor(ctx, tx, settings.Sponsor, settings.Currency)
+	if err != nil {
+		return err
+	}
+	// budget reserved by open tournament follows its guaranteed prize
+	err = releaseGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	t.TournamentSettings = settings
+	if t.Status == sts.StatusRegistration {
+		err = reserveGuarantee(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE tournaments
+	   SET name = ?, deposit = ?, rake_type = ?, rake = ?, min_players = ?, max_players = ?,
+	       registration_opens_at = ?, registration_closes_at = ?, starts_at = ?, format = ?, rounds = ?,
+	       min_rating = ?, max_rating = ?, team_based = ?, visibility = ?, organizer_id = ?,
+	       guaranteed_prize = ?, sponsor = ?, currency = ?
+	 WHERE id = ?`,
+		settings.Name, settings.Deposit, settings.RakeType, settings.Rake, settings.MinPlayers, settings.MaxPlayers,
+		settings.RegistrationOpensAt, settings.RegistrationClosesAt, settings.StartsAt, settings.Format, settings.Rounds,
+		settings.MinRating, settings.MaxRating, settings.TeamBased, settings.Visibility, nullID(settings.OrganizerID),
+		settings.GuaranteedPrize, nullAccount(settings.Sponsor), settings.Currency, id)
+	if err != nil {
+		return dbError(err, "couldn't update tournament")
+	}
+	_, err = tx.ExecContext(ctx, `
+	DELETE
+	  FROM payouts
+	 WHERE tournament_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("couldn't delete payouts: %s", err)
+	}
+	err = addPayouts(ctx, tx, id, settings.PrizeShares)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CloneTournament adds a draft with settings of tournament with passed id and returns id of
+// the draft. Schedule times that have passed aren't copied. If tournament isn't found,
+// function returns ErrNotFound. See AddTournament for other errors.
+func (c *Connector) CloneTournament(ctx context.Context, id int64) (int64, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	t, err := loadTournament(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+	settings := t.Clone(c.now())
+	err = settings.Validate()
+	if err != nil {
+		return 0, err
+	}
+	cloneID, err := addTournament(ctx, tx, settings)
+	if err != nil {
+		return 0, err
+	}
+	return cloneID, tx.Commit()
 }
 
 // GetTournament returns tournament with passed id. If tournament isn't found,
 // function returns ErrNotFound.
 func (c *Connector) GetTournament(ctx context.Context, id int64) (*sts.Tournament, error) {
 	var (
-		users    sql.NullString
-		winner   sql.NullInt64
-		finished bool
-		t        sts.Tournament
+		users string
+		t     sts.Tournament
 	)
-	err := c.db.QueryRowContext(ctx, `
-	  SELECT id, name, deposit, prize, winner, finished, JSON_ARRAYAGG(user_id)
-	    FROM tournaments
-   LEFT JOIN participants ON id = tournament_id
-	   WHERE id = ?
-	GROUP BY id`, id).
-		Scan(&t.ID, &t.Name, &t.Deposit, &t.Prize, &winner, &finished, &users)
+	err := scanTournament(c.db.QueryRowContext(ctx, `
+	  SELECT `+tournamentColumns+`, IF(COUNT(p.user_id) = 0, JSON_ARRAY(), JSON_ARRAYAGG(p.user_id))
+	    FROM tournaments AS t
+   LEFT JOIN participants AS p ON t.id = p.tournament_id
+	   WHERE t.id = ?
+	GROUP BY t.id`, id), &t, &users)
 	if err == sql.ErrNoRows {
 		return nil, sts.ErrNotFound
 	}
@@ -49,74 +325,595 @@ func (c *Connector) GetTournament(ctx context.Context, id int64) (*sts.Tournamen
 		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(users.String), &t.Users)
+	err = json.Unmarshal([]byte(users), &t.Users)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't unmarshal json: %s", err)
 	}
-	if finished {
-		if !winner.Valid {
-			return nil, fmt.Errorf("no winner")
+	t.Waitlist, err = waitlist(ctx, c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	entries := len(t.Users)
+	if t.TeamBased {
+		teams, err := teamEntries(ctx, c.db, id)
+		if err != nil {
+			return nil, err
 		}
-		t.Winner = winner.Int64
+		t.Teams = make([]int64, 0, len(teams))
+		for _, team := range teams {
+			t.Teams = append(t.Teams, team.TeamID)
+		}
+		entries = len(t.Teams)
+	}
+	t.Underfilled = (t.Status == sts.StatusDraft || t.Status == sts.StatusRegistration) &&
+		!t.HasEnoughPlayers(entries)
+	t.Payouts, err = getPayouts(ctx, c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	t.PrizeShares = make([]uint32, 0, len(t.Payouts))
+	for _, p := range t.Payouts {
+		t.PrizeShares = append(t.PrizeShares, p.Share)
+	}
+	if t.Status != sts.StatusFinished {
+		t.Payouts = sts.PlannedPayouts(t.PrizePool(), t.PrizeShares)
 	}
 	return &t, nil
 }
 
+func getPayouts(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]sts.Payout, error) {
+	rows, err := q.QueryContext(ctx, `
+	  SELECT place, share, amount, user_id, team_id
+	    FROM payouts
+	   WHERE tournament_id = ?
+	ORDER BY place`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get payouts: %s", err)
+	}
+	defer rows.Close()
+	var payouts []sts.Payout
+	for rows.Next() {
+		var (
+			p      sts.Payout
+			amount sql.NullInt64
+			userID sql.NullInt64
+			teamID sql.NullInt64
+		)
+		err = rows.Scan(&p.Place, &p.Share, &amount, &userID, &teamID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan payout: %s", err)
+		}
+		p.Amount = uint64(amount.Int64)
+		p.UserID = userID.Int64
+		p.TeamID = teamID.Int64
+		payouts = append(payouts, p)
+	}
+	return payouts, rows.Err()
+}
+
 // JoinTournament adds user with passed userID to tournament with passed tournamentID.
-// If tournament or user isn't found, function returns ErrNotFound.
-func (c *Connector) JoinTournament(ctx context.Context, tournamentID, userID int64) error {
+// If tournament is full, user is put on the waitlist without charging the deposit and
+// function returns true. If tournament or user isn't found, function returns ErrNotFound.
+// If tournament isn't open for registration or current time is out of its registration
+// window, function returns ErrTournamentClosed. If user rating is out of tournament range,
+// function returns ErrRatingOutOfRange. If tournament is team-based, function returns ErrTeamTournament.
+// Users other than the organizer must pass a valid invite code to join private tournament,
+// otherwise function returns ErrInvalidInviteCode. The code is ignored for public tournaments.
+// If user has already joined tournament or its waitlist, function returns ErrAlreadyJoined.
+// Retries with the same idempotency key in ctx return the first result without joining again.
+func (c *Connector) JoinTournament(ctx context.Context, tournamentID, userID int64, inviteCode string) (bool, error) {
 	tx, err := c.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback()
-	var (
-		finished bool
-		t        sts.Tournament
-	)
-	err = tx.QueryRowContext(ctx, `
-    SELECT id, name, deposit, prize, finished
-      FROM tournaments
-     WHERE id = ?`, tournamentID).
-		Scan(&t.ID, &t.Name, &t.Deposit, &t.Prize, &finished)
-	if err == sql.ErrNoRows {
-		return sts.ErrNotFound
+	response, done, err := c.useIdempotencyKey(ctx, tx, sts.IdempotentRequest("join", tournamentID, userID, inviteCode))
+	if err != nil {
+		return false, err
+	}
+	if done {
+		return response == "true", nil
+	}
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return false, err
+	}
+	if t.TeamBased {
+		return false, sts.ErrTeamTournament
+	}
+	if t.Status != sts.StatusRegistration || !t.InRegistrationWindow(c.now()) {
+		return false, sts.ErrTournamentClosed
+	}
+	if userID != t.OrganizerID {
+		err = useInviteCode(ctx, tx, t, inviteCode, c.now())
+		if err != nil {
+			return false, err
+		}
+	}
+	err = checkRating(ctx, tx, t, userID)
+	if err != nil {
+		return false, err
+	}
+	users, err := participants(ctx, tx, tournamentID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range users {
+		if id == userID {
+			return false, sts.ErrAlreadyJoined
+		}
+	}
+
+	if t.IsFull(len(users)) {
+		err = addToWaitlist(ctx, tx, tournamentID, userID)
+		if err != nil {
+			return false, err
+		}
+		err = saveIdempotentResponse(ctx, tx, "true")
+		if err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+	err = addParticipant(ctx, tx, t, userID, c.now())
+	if err != nil {
+		return false, err
+	}
+	err = saveIdempotentResponse(ctx, tx, "false")
+	if err != nil {
+		return false, err
+	}
+	return false, tx.Commit()
+}
+
+// addParticipant charges the deposit from user with passed userID at now and adds the user to
+// locked tournament.
+func addParticipant(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, userID int64, now time.Time) error {
+	err := changeBalance(ctx, tx, userID, t.Currency, -int64(t.Deposit), sts.ReasonEntryFee, t.ID, now)
+	if err != nil {
+		return err
+	}
+	err = addEntries(ctx, tx, t, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO participants(user_id,tournament_id)
+	     VALUES (?,?)`, userID, t.ID)
+	if duplicate(err) {
+		return sts.ErrAlreadyJoined
 	}
 	if err != nil {
-		return fmt.Errorf("couldn't load tournament: %s", err)
+		return dbError(err, "couldn't add user to tournament")
 	}
-	if finished {
-		return errors.New("tournament has finished")
+	return nil
+}
+
+func waitlist(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]int64, error) {
+	users := []int64{}
+	err := sqlx.SelectContext(ctx, q, &users, `
+    SELECT user_id
+      FROM waitlist
+     WHERE tournament_id = ?
+  ORDER BY joined_at, id`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load waitlist: %s", err)
 	}
+	return users, nil
+}
 
-	update, err := tx.ExecContext(ctx, `
-    UPDATE users
-       SET balance = balance - ?
-     WHERE id = ?`, t.Deposit, userID)
+// addToWaitlist puts user with passed userID to the end of tournament waitlist.
+// If user isn't found, function returns ErrNotFound.
+func addToWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID, userID int64) error {
+	insert, err := tx.ExecContext(ctx, `
+    INSERT INTO waitlist (user_id, tournament_id)
+         SELECT id, ?
+           FROM users
+          WHERE id = ?`, tournamentID, userID)
+	if duplicate(err) {
+		return sts.ErrAlreadyJoined
+	}
 	if err != nil {
-		return fmt.Errorf("couldn't update user balance: %s", err)
+		return dbError(err, "couldn't add user to waitlist")
 	}
-	rows, err := update.RowsAffected()
+	rows, err := insert.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("couldn't process user update : %s", err)
+		return err
 	}
 	if rows == 0 {
 		return sts.ErrNotFound
 	}
+	return nil
+}
 
-	_, err = tx.ExecContext(ctx, `
+// promoteWaitlisted gives a free seat in locked tournament to the first waitlisted user that
+// can pay the deposit at now. Users that can't pay it are removed from the waitlist.
+func promoteWaitlisted(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, now time.Time) error {
+	for {
+		var (
+			id      int64
+			userID  int64
+			balance uint64
+		)
+		err := tx.QueryRowContext(ctx, `
+    SELECT w.id, w.user_id,
+           COALESCE((SELECT balance FROM balances WHERE user_id = w.user_id AND currency = ?), 0)
+      FROM waitlist AS w
+     WHERE w.tournament_id = ?
+  ORDER BY w.joined_at, w.id
+     LIMIT 1
+       FOR UPDATE`, t.Currency, t.ID).Scan(&id, &userID, &balance)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't load waitlist: %s", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+    DELETE
+      FROM waitlist
+     WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("couldn't remove user from waitlist: %s", err)
+		}
+		if balance < t.Deposit {
+			continue
+		}
+		err = addParticipant(ctx, tx, t, userID, now)
+		// balance may include credits that have just expired
+		if err != sts.ErrInsufficientFunds {
+			return err
+		}
+	}
+}
+
+// clearWaitlist removes every user from tournament waitlist.
+func clearWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID int64) error {
+	_, err := tx.ExecContext(ctx, `
+    DELETE
+      FROM waitlist
+     WHERE tournament_id = ?`, tournamentID)
+	if err != nil {
+		return fmt.Errorf("couldn't clear waitlist: %s", err)
+	}
+	return nil
+}
+
+// addEntries adds passed number of deposits to tournament prize and takes rake from them.
+// Negative number of entries takes deposits back from tournament prize and returns rake.
+func addEntries(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, entries int64) error {
+	deposits := entries * int64(t.Deposit)
+	rake := entries * int64(t.RakeAmount())
+	_, err := tx.ExecContext(ctx, `
     UPDATE tournaments
-       SET prize = prize + deposit
-     WHERE id = ?`, tournamentID)
+       SET gross_prize = gross_prize + ?, prize = prize + ?
+     WHERE id = ?`, deposits, deposits-rake, t.ID)
 	if err != nil {
-		return fmt.Errorf("couldn't increase tournament prize: %s", err)
+		return dbError(err, "couldn't update tournament prize")
 	}
+	return changeHouseBalance(ctx, tx, sts.RakeAccount, t.Currency, rake)
+}
 
+// LeaveTournament removes user with passed userID from tournament with passed tournamentID
+// and refunds the deposit. The freed seat is taken by the first waitlisted user that can
+// pay the deposit. Waitlisted user is just removed from the waitlist. If tournament isn't
+// found, function returns ErrNotFound. If user doesn't participate in tournament, function
+// returns ErrNotParticipant. If tournament has already started, function returns
+// ErrTournamentClosed. If tournament is team-based, function returns ErrTeamTournament.
+func (c *Connector) LeaveTournament(ctx context.Context, tournamentID, userID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.TeamBased {
+		return sts.ErrTeamTournament
+	}
+	if t.Status != sts.StatusDraft && t.Status != sts.StatusRegistration {
+		return sts.ErrTournamentClosed
+	}
+
+	delete, err := tx.ExecContext(ctx, `
+    DELETE
+      FROM participants
+     WHERE tournament_id = ? AND user_id = ?`, tournamentID, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't remove user from tournament: %s", err)
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		err = leaveWaitlist(ctx, tx, tournamentID, userID)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	now := c.now()
+	err = changeBalance(ctx, tx, userID, t.Currency, int64(t.Deposit), sts.ReasonRefund, tournamentID, now)
+	if err != nil {
+		return err
+	}
+	err = addEntries(ctx, tx, t, -1)
+	if err != nil {
+		return err
+	}
+	err = promoteWaitlisted(ctx, tx, t, now)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// leaveWaitlist removes user with passed userID from tournament waitlist.
+// If user isn't waitlisted, function returns ErrNotParticipant.
+func leaveWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID, userID int64) error {
+	delete, err := tx.ExecContext(ctx, `
+    DELETE
+      FROM waitlist
+     WHERE tournament_id = ? AND user_id = ?`, tournamentID, userID)
+	if err != nil {
+		return fmt.Errorf("couldn't remove user from waitlist: %s", err)
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sts.ErrNotParticipant
+	}
+	return nil
+}
+
+// OpenRegistration moves tournament from draft to registration status and reserves its guaranteed
+// prize from the budget of its sponsor. If tournament isn't found, function returns ErrNotFound.
+// If tournament isn't a draft, function returns TransitionError. If sponsor doesn't have enough
+// points, function returns ErrInsufficientBudget.
+func (c *Connector) OpenRegistration(ctx context.Context, tournamentID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = transition(ctx, tx, t, sts.StatusRegistration)
+	if err != nil {
+		return err
+	}
+	err = reserveGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// StartTournament closes registration, clears the waitlist and moves tournament to running
+// status. If tournament isn't found, function returns ErrNotFound. If tournament isn't
+// open for registration, function returns TransitionError. If tournament doesn't have
+// minimum number of players, function returns ErrNotEnoughPlayers.
+func (c *Connector) StartTournament(ctx context.Context, tournamentID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = transition(ctx, tx, t, sts.StatusRunning)
+	if err != nil {
+		return err
+	}
+	count, err := entrants(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	if !t.HasEnoughPlayers(count) {
+		return sts.ErrNotEnoughPlayers
+	}
+	err = clearWaitlist(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CancelTournament refunds deposits to every participant, returns the reserved guaranteed prize
+// to the sponsor, resets tournament prize, clears the waitlist and moves tournament to cancelled
+// status. If tournament isn't found, function returns ErrNotFound. If tournament has already
+// finished or been cancelled, function returns TransitionError.
+func (c *Connector) CancelTournament(ctx context.Context, tournamentID int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	t.Users, err = participants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	entries := len(t.Users)
+	var teams []sts.TeamEntry
+	if t.TeamBased {
+		teams, err = teamEntries(ctx, tx, tournamentID)
+		if err != nil {
+			return err
+		}
+		entries = len(teams)
+	}
+	err = releaseGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	refunds, err := t.Cancel(teams)
+	if err != nil {
+		return err
+	}
+	for _, r := range refunds {
+		err = changeBalance(ctx, tx, r.UserID, t.Currency, int64(r.Amount), sts.ReasonRefund, t.ID, c.now())
+		if err != nil {
+			return err
+		}
+	}
 	_, err = tx.ExecContext(ctx, `
-	INSERT INTO participants(user_id,tournament_id) 
-	     VALUES (?,?)`, userID, tournamentID)
+	UPDATE tournaments
+	   SET status = ?, gross_prize = ?, prize = ?
+	 WHERE id = ?`, t.Status, t.GrossPrize, t.Prize, t.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't cancel tournament: %s", err)
+	}
+	err = changeHouseBalance(ctx, tx, sts.RakeAccount, t.Currency, -int64(entries)*int64(t.RakeAmount()))
+	if err != nil {
+		return err
+	}
+	err = clearWaitlist(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// transition moves locked tournament to passed status if it's allowed.
+func transition(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, status sts.Status) error {
+	err := sts.CheckTransition(t.Status, status)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE tournaments
+       SET status = ?
+     WHERE id = ?`, status, t.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't update tournament status: %s", err)
+	}
+	t.Status = status
+	return nil
+}
+
+// FinishTournament splits tournament prize between users according to passed ranking
+// and marks tournament as finished. Ranking must contain every participant exactly once,
+// starting from the winner. If tournament isn't found, function returns ErrNotFound.
+// If ranked user doesn't participate in tournament, function returns ErrNotParticipant.
+// Ratings of participants of a pool tournament are updated as if every user has won against
+// every user ranked below. Ranking of team-based tournament contains teams instead of users,
+// prizes of teams are split between their members by their shares and ratings aren't updated.
+// When prize falls short of the guaranteed one, the overlay is paid out of the budget that has
+// been reserved from the sponsor, the rest of the budget is returned.
+func (c *Connector) FinishTournament(ctx context.Context, tournamentID int64, ranking []int64) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = finish(ctx, tx, t, ranking, c.now())
 	if err != nil {
-		return fmt.Errorf("couldn't add user to tournament: %s", err)
+		return err
 	}
 	return tx.Commit()
 }
+
+// finish pays out prize of locked tournament together with the overlay of its guaranteed prize
+// according to passed ranking, marks it as finished and adds its results to leaderboards of the
+// period that contains now.
+func finish(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64, now time.Time) error {
+	tournamentID := t.ID
+	err := transition(ctx, tx, t, sts.StatusFinished)
+	if err != nil {
+		return err
+	}
+	err = payOverlay(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+
+	var shares []uint32
+	err = tx.SelectContext(ctx, &shares, `
+    SELECT share
+      FROM payouts
+     WHERE tournament_id = ?
+  ORDER BY place`, tournamentID)
+	if err != nil {
+		return fmt.Errorf("couldn't load prize shares: %s", err)
+	}
+	var results []sts.LeaderboardEntry
+	if t.TeamBased {
+		results, err = finishTeams(ctx, tx, t, ranking, shares, now)
+	} else {
+		results, err = finishUsers(ctx, tx, t, ranking, shares, now)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE payouts
+       SET amount = 0
+     WHERE tournament_id = ? AND amount IS NULL`, tournamentID)
+	if err != nil {
+		return fmt.Errorf("couldn't save unpaid places: %s", err)
+	}
+	return addLeaderboardStats(ctx, tx, results, now)
+}
+
+// finishUsers pays out prize of locked tournament according to passed ranking of users and
+// returns their results.
+func finishUsers(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64,
+	shares []uint32, now time.Time) ([]sts.LeaderboardEntry, error) {
+	users, err := participants(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = sts.ValidateRanking(ranking, users)
+	if err != nil {
+		return nil, err
+	}
+	if t.Format == sts.FormatPool {
+		err = rateGames(ctx, tx, t.ID, sts.RankingGames(ranking))
+		if err != nil {
+			return nil, err
+		}
+	}
+	payouts := sts.RankingPayouts(t.PrizePool(), shares, ranking)
+	for _, p := range payouts {
+		if p.Amount > 0 {
+			err = changeBalance(ctx, tx, p.UserID, t.Currency, int64(p.Amount), sts.ReasonPrize, t.ID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `
+    UPDATE payouts
+       SET amount = ?, user_id = ?
+     WHERE tournament_id = ? AND place = ?`, p.Amount, p.UserID, t.ID, p.Place)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't save payout: %s", err)
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+    UPDATE tournaments
+       SET winner = ?
+     WHERE id = ?`, ranking[0], t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't finish tournament: %s", err)
+	}
+	return sts.RankingResults(ranking, payouts, t.Deposit), nil
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+)
+
+// TransferPoints moves amount of passed currency from user with fromID to user with toID at once.
+// Expiring points keep their expiry. If either user isn't found, function returns ErrNotFound.
+// If sender doesn't have enough points, function returns ErrInsufficientFunds. If transfer would
+// exceed what sender may transfer a day, function returns ErrTransferCapExceeded.
+func (c *Connector) TransferPoints(ctx context.Context, fromID, toID int64, currency sts.Currency,
+	amount uint64) error {
+	err := sts.ValidateTransfer(fromID, toID, amount)
+	if err != nil {
+		return err
+	}
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = checkCurrency(ctx, tx, currency)
+	if err != nil {
+		return err
+	}
+	// Wallets are locked in the order of user ids, so that opposite transfers don't deadlock.
+	ids := []int64{fromID, toID}
+	if fromID > toID {
+		ids = []int64{toID, fromID}
+	}
+	for _, id := range ids {
+		err = lockWallet(ctx, tx, id, currency)
+		if err != nil {
+			return err
+		}
+	}
+	now := c.now()
+	err = expireLots(ctx, tx, fromID, currency, now)
+	if err != nil {
+		return err
+	}
+	err = transferBalance(ctx, tx, fromID, toID, currency, -int64(amount), now)
+	if err != nil {
+		return err
+	}
+	err = checkTransferCap(ctx, tx, fromID, currency, now)
+	if err != nil {
+		return err
+	}
+	lots, err := spendLots(ctx, tx, fromID, currency, amount, sts.ReasonTransfer, 0, now)
+	if err != nil {
+		return err
+	}
+	err = transferBalance(ctx, tx, toID, fromID, currency, int64(amount), now)
+	if err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		err = addLot(ctx, tx, toID, currency, lot.Amount, lot.ExpiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// transferBalance adds amount to balance of user with passed userID and records the transfer
+// with the other user of it. Transfer is stamped with passed time that checkTransferCap uses,
+// so the cap doesn't depend on the clock of the db.
+func transferBalance(ctx context.Context, tx *sqlx.Tx, userID, peerID int64, currency sts.Currency, amount int64,
+	now time.Time) error {
+	err := addToBalance(ctx, tx, userID, currency, amount)
+	if err != nil {
+		return err
+	}
+	return recordTransaction(ctx, tx, sts.Transaction{
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  currency,
+		Reason:    sts.ReasonTransfer,
+		PeerID:    peerID,
+		CreatedAt: now,
+	})
+}
+
+// checkTransferCap returns ErrTransferCapExceeded if user with passed userID has transferred
+// more points of passed currency since the start of the day than the currency allows.
+func checkTransferCap(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, now time.Time) error {
+	var limit sql.NullInt64
+	err := tx.QueryRowContext(ctx, `
+	SELECT daily_transfer_cap
+	  FROM currencies
+	 WHERE code = ?`, currency).Scan(&limit)
+	if err != nil {
+		return fmt.Errorf("couldn't get transfer cap: %s", err)
+	}
+	if !limit.Valid {
+		return nil
+	}
+	var sent int64
+	err = tx.QueryRowContext(ctx, `
+	SELECT COALESCE(SUM(-amount), 0)
+	  FROM transactions
+	 WHERE user_id = ? AND currency = ? AND reason = ? AND amount < 0 AND created_at >= ?`,
+		userID, currency, sts.ReasonTransfer, sts.TransferDay(now)).Scan(&sent)
+	if err != nil {
+		return fmt.Errorf("couldn't get transferred points: %s", err)
+	}
+	if sent > limit.Int64 {
+		return sts.ErrTransferCapExceeded
+	}
+	return nil
+}
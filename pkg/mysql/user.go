@@ -5,18 +5,27 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 )
 
-// AddUser adds user with passed name to db. It returns id of this user.
+// AddUser adds user with passed name to db. It returns id of this user. If name isn't a valid
+// handle, function returns ErrInvalidHandle. If name is taken, function returns ErrHandleTaken.
 func (c *Connector) AddUser(ctx context.Context, name string) (int64, error) {
+	err := sts.ValidateHandle(name)
+	if err != nil {
+		return 0, err
+	}
 	insert, err := c.db.ExecContext(ctx, `
  INSERT INTO users (name) 
       VALUES (?)`,
 		name)
+	if duplicate(err) {
+		return 0, sts.ErrHandleTaken
+	}
 	if err != nil {
-		return 0, fmt.Errorf("couldn't add user: %s", err)
+		return 0, dbError(err, "couldn't add user")
 	}
 	id, err := insert.LastInsertId()
 	if err != nil {
@@ -28,10 +37,11 @@ func (c *Connector) AddUser(ctx context.Context, name string) (int64, error) {
 // GetUser returns user with passed id. If user isn't found, function returns ErrNotFound.
 func (c *Connector) GetUser(ctx context.Context, id int64) (*sts.User, error) {
 	var user sts.User
-	err := c.db.GetContext(ctx, &user, `
-SELECT id, name, balance 
-  FROM users 
- WHERE id = ?`, id)
+	err := c.db.QueryRowContext(ctx, `
+SELECT id, name, display_name, avatar_url, country, bio, rating, rating_deviation, games
+  FROM users
+ WHERE id = ?`, id).Scan(&user.ID, &user.Name, &user.DisplayName, &user.AvatarURL, &user.Country, &user.Bio,
+		&user.Rating, &user.RatingDeviation, &user.Games)
 	if err == sql.ErrNoRows {
 		return nil, sts.ErrNotFound
 	}
@@ -39,9 +49,60 @@ SELECT id, name, balance
 		log.Print(err)
 		return nil, err
 	}
+	user.Balances, err = balances(ctx, c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	user.Expirations, err = expirations(ctx, c.db, id, c.now())
+	if err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
+// UpdateUser changes profile of user with passed id. If user isn't found, function returns
+// ErrNotFound. If update is incorrect, function returns ErrInvalidHandle or ErrInvalidProfile.
+// If new name is taken, function returns ErrHandleTaken.
+func (c *Connector) UpdateUser(ctx context.Context, id int64, update sts.UserUpdate) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// affected rows of update don't tell whether user exists, since mysql doesn't count unchanged rows
+	var name string
+	err = tx.GetContext(ctx, &name, `
+	SELECT name
+	  FROM users
+	 WHERE id = ?
+	   FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return sts.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't get user: %s", err)
+	}
+	err = update.Validate(name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE users
+	   SET name = COALESCE(?, name),
+	       display_name = COALESCE(?, display_name),
+	       avatar_url = COALESCE(?, avatar_url),
+	       country = COALESCE(?, country),
+	       bio = COALESCE(?, bio)
+	 WHERE id = ?`, update.Name, update.DisplayName, update.AvatarURL, update.Country, update.Bio, id)
+	if duplicate(err) {
+		return sts.ErrHandleTaken
+	}
+	if err != nil {
+		return dbError(err, "couldn't update user")
+	}
+	return tx.Commit()
+}
+
 // DeleteUser deletes user with passed id. If user isn't found, function returns ErrNotFound.
 func (c *Connector) DeleteUser(ctx context.Context, id int64) error {
 	delete, err := c.db.ExecContext(ctx, `
@@ -61,21 +122,54 @@ func (c *Connector) DeleteUser(ctx context.Context, id int64) error {
 	return nil
 }
 
-// AddPoints adds points to user with passed id. If user isn't found, function returns ErrNotFound.
-func (c *Connector) AddPoints(ctx context.Context, id, points int64) error {
-	update, err := c.db.ExecContext(ctx, `
-	UPDATE users 
-	   SET balance = balance + ? 
-	 WHERE id = ?`, points, id)
+// Credit adds amount of passed currency to balance of user with passed id. Credited points expire
+// after expiresIn unless it's zero. If user isn't found, function returns ErrNotFound.
+// If currency doesn't exist, function returns ErrInvalidCurrency. Retries with the same
+// idempotency key in ctx succeed without crediting again.
+func (c *Connector) Credit(ctx context.Context, id int64, currency sts.Currency, amount uint64,
+	expiresIn time.Duration) error {
+	expiresAt, err := sts.ExpiresAt(c.now(), expiresIn)
 	if err != nil {
-		return fmt.Errorf("couldn't update balance: %s", err)
+		return err
 	}
-	rows, err := update.RowsAffected()
+	request := sts.IdempotentRequest("fund", id, currency, amount, expiresIn)
+	return c.addPoints(ctx, id, currency, int64(amount), sts.ReasonFund, expiresAt, request)
+}
+
+// Debit takes amount of passed currency from balance of user with passed id. Points that expire
+// the soonest are taken first. See Credit for errors.
+func (c *Connector) Debit(ctx context.Context, id int64, currency sts.Currency, amount uint64) error {
+	request := sts.IdempotentRequest("take", id, currency, amount)
+	return c.addPoints(ctx, id, currency, -int64(amount), sts.ReasonTake, nil, request)
+}
+
+func (c *Connector) addPoints(ctx context.Context, id int64, currency sts.Currency, amount int64,
+	reason sts.Reason, expiresAt *time.Time, request string) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sts.ErrNotFound
+	defer tx.Rollback()
+	_, done, err := c.useIdempotencyKey(ctx, tx, request)
+	if err != nil {
+		return err
 	}
-	return nil
+	if done {
+		return nil
+	}
+	err = checkCurrency(ctx, tx, currency)
+	if err != nil {
+		return err
+	}
+	err = changeBalance(ctx, tx, id, currency, amount, reason, 0, c.now())
+	if err != nil {
+		return err
+	}
+	if expiresAt != nil && amount > 0 {
+		err = addLot(ctx, tx, id, currency, uint64(amount), *expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
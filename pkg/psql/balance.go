@@ -0,0 +1,196 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// changeBalance adds amount of passed currency to balance of user with passed userID and records
+// this change in the ledger. Zero tournamentID means that the change isn't related to any tournament.
+// Taken points are spent from expiring credits first, credits that have expired by now are taken
+// by expiry beforehand. If user isn't found, function returns ErrNotFound. If user doesn't have
+// enough points, function returns ErrInsufficientFunds.
+func changeBalance(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount int64,
+	reason sts.Reason, tournamentID int64, now time.Time) error {
+	if amount < 0 {
+		err := expireLots(ctx, tx, userID, currency, now)
+		if err != nil {
+			return err
+		}
+	}
+	err := updateBalance(ctx, tx, userID, currency, amount, reason, tournamentID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case amount < 0:
+		_, err = spendLots(ctx, tx, userID, currency, uint64(-amount), reason, tournamentID, now)
+		return err
+	case reason == sts.ReasonRefund && tournamentID != 0:
+		return restoreLots(ctx, tx, userID, currency, tournamentID)
+	}
+	return nil
+}
+
+// updateBalance changes balance like changeBalance does, but leaves expiring credits as they are.
+func updateBalance(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount int64,
+	reason sts.Reason, tournamentID int64) error {
+	err := addToBalance(ctx, tx, userID, currency, amount)
+	if err != nil {
+		return err
+	}
+	return recordTransaction(ctx, tx, sts.Transaction{
+		UserID:       userID,
+		Amount:       amount,
+		Currency:     currency,
+		Reason:       reason,
+		TournamentID: tournamentID,
+	})
+}
+
+// addToBalance adds amount to wallet of passed currency of user with passed userID. If user isn't
+// found, function returns ErrNotFound. If balance would become negative, function returns
+// ErrInsufficientFunds.
+func addToBalance(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount int64) error {
+	err := openWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return err
+	}
+	update, err := tx.ExecContext(ctx, `
+UPDATE balances
+   SET balance = balance + $1
+ WHERE user_id = $2 AND currency = $3 AND balance + $1 >= 0`, amount, userID, currency)
+	if err != nil {
+		return dbError(err, "couldn't update balance")
+	}
+	rows, err := update.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't process user update")
+	}
+	if rows == 0 {
+		var exists bool
+		err = tx.QueryRowContext(ctx, `
+SELECT EXISTS(SELECT 1 FROM balances WHERE user_id = $1 AND currency = $2)`, userID, currency).Scan(&exists)
+		if err != nil {
+			return errors.Wrap(err, "couldn't check wallet")
+		}
+		if exists {
+			return sts.ErrInsufficientFunds
+		}
+		return sts.ErrNotFound
+	}
+	return nil
+}
+
+// recordTransaction adds t to the ledger. Transaction without CreatedAt is stamped by the db.
+func recordTransaction(ctx context.Context, tx *sqlx.Tx, t sts.Transaction) error {
+	var createdAt *time.Time
+	if !t.CreatedAt.IsZero() {
+		createdAt = &t.CreatedAt
+	}
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO transactions (user_id, amount, currency, reason, tournament_id, peer_id, created_at)
+     VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, now()))`, t.UserID, t.Amount, t.Currency, t.Reason,
+		nullID(t.TournamentID), nullID(t.PeerID), createdAt)
+	return errors.Wrap(err, "couldn't record transaction")
+}
+
+// openWallet creates an empty wallet of passed currency for user with passed userID unless it exists.
+func openWallet(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency) error {
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO balances (user_id, currency)
+     SELECT id, $2
+       FROM users
+      WHERE id = $1
+ON CONFLICT (user_id, currency) DO NOTHING`, userID, currency)
+	return errors.Wrap(err, "couldn't open wallet")
+}
+
+// lockWallet opens wallet of passed currency for user with passed userID and locks it until
+// transaction ends. If user isn't found, function returns ErrNotFound.
+func lockWallet(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency) error {
+	err := openWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return err
+	}
+	var balance uint64
+	err = tx.QueryRowContext(ctx, `
+SELECT balance
+  FROM balances
+ WHERE user_id = $1 AND currency = $2
+   FOR UPDATE`, userID, currency).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return sts.ErrNotFound
+	}
+	return errors.Wrap(err, "couldn't lock wallet")
+}
+
+// checkCurrency returns ErrInvalidCurrency if passed currency doesn't exist.
+func checkCurrency(ctx context.Context, q sqlx.QueryerContext, currency sts.Currency) error {
+	var exists bool
+	err := q.QueryRowxContext(ctx, `
+SELECT EXISTS(SELECT 1 FROM currencies WHERE code = $1)`, currency).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "couldn't check currency")
+	}
+	if !exists {
+		return sts.ErrInvalidCurrency
+	}
+	return nil
+}
+
+// balances returns a wallet of every currency of user with passed userID.
+func balances(ctx context.Context, q sqlx.QueryerContext, userID int64) ([]sts.Balance, error) {
+	result := []sts.Balance{}
+	err := sqlx.SelectContext(ctx, q, &result, `
+   SELECT c.code AS currency, COALESCE(b.balance, 0) AS amount
+     FROM currencies AS c
+LEFT JOIN balances AS b ON b.currency = c.code AND b.user_id = $1
+ ORDER BY c.code`, userID)
+	return result, errors.Wrap(err, "couldn't load balances")
+}
+
+// GetUserTransactions returns up to limit latest balance changes of user with passed userID,
+// starting from the newest one. If beforeID isn't zero, only transactions older than the
+// transaction with beforeID are returned. If user isn't found, function returns ErrNotFound.
+func (db *DB) GetUserTransactions(ctx context.Context, userID, beforeID int64, limit uint64) ([]sts.Transaction, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+  SELECT id, user_id, amount, currency, reason, tournament_id, peer_id, created_at
+    FROM transactions
+   WHERE user_id = $1 AND ($2 = 0 OR id < $2)
+ORDER BY id DESC
+   LIMIT $3`, userID, beforeID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get transactions")
+	}
+	defer rows.Close()
+	transactions := []sts.Transaction{}
+	for rows.Next() {
+		var (
+			t                    sts.Transaction
+			tournamentID, peerID sql.NullInt64
+		)
+		err = rows.Scan(&t.ID, &t.UserID, &t.Amount, &t.Currency, &t.Reason, &tournamentID, &peerID, &t.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan transaction")
+		}
+		t.TournamentID = tournamentID.Int64
+		t.PeerID = peerID.Int64
+		transactions = append(transactions, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "couldn't read transactions")
+	}
+	if len(transactions) == 0 {
+		_, err = db.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return transactions, nil
+}
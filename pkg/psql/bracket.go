@@ -0,0 +1,168 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// GenerateBracket generates single-elimination bracket from participants of running tournament
+// with passed tournamentID. Seeding lists every participant from the strongest one, empty
+// seeding means random order. If tournament isn't found, function returns ErrNotFound.
+// If tournament isn't running, function returns ErrTournamentNotRunning. If tournament
+// isn't a single-elimination one, function returns ErrUnsupportedFormat. If bracket has
+// already been generated, function returns ErrBracketExists. If seeding is incorrect,
+// function returns ErrInvalidSeeding.
+func (db *DB) GenerateBracket(ctx context.Context, tournamentID int64, seeding []int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != sts.StatusRunning {
+		return sts.ErrTournamentNotRunning
+	}
+	if t.Format != sts.FormatSingleElimination {
+		return sts.ErrUnsupportedFormat
+	}
+	bracket, err := getBracket(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if len(bracket) != 0 {
+		return sts.ErrBracketExists
+	}
+
+	users, err := participants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	seeding, err = sts.Seed(users, seeding)
+	if err != nil {
+		return err
+	}
+	bracket, err = sts.NewBracket(seeding)
+	if err != nil {
+		return err
+	}
+	for _, m := range bracket {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO matches (tournament_id, round, position, user1_id, user2_id, winner_id)
+     VALUES ($1, $2, $3, $4, $5, $6)`, tournamentID, m.Round, m.Position, nullID(m.User1), nullID(m.User2),
+			nullID(m.Winner))
+		if err != nil {
+			return errors.Wrap(err, "couldn't add match")
+		}
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// GetBracket returns matches of tournament with passed tournamentID ordered by round and
+// position. If tournament isn't found, function returns ErrNotFound.
+func (db *DB) GetBracket(ctx context.Context, tournamentID int64) (sts.Bracket, error) {
+	err := db.checkTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return getBracket(ctx, db.conn, tournamentID)
+}
+
+func getBracket(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) (sts.Bracket, error) {
+	rows, err := q.QueryContext(ctx, `
+  SELECT round, position, user1_id, user2_id, winner_id
+    FROM matches
+   WHERE tournament_id = $1
+ORDER BY round, position`, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get matches")
+	}
+	defer rows.Close()
+	bracket := sts.Bracket{}
+	for rows.Next() {
+		var (
+			m                    sts.Match
+			user1, user2, winner sql.NullInt64
+		)
+		err = rows.Scan(&m.Round, &m.Position, &user1, &user2, &winner)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan match")
+		}
+		m.User1, m.User2, m.Winner = user1.Int64, user2.Int64, winner.Int64
+		bracket = append(bracket, m)
+	}
+	return bracket, errors.Wrap(rows.Err(), "couldn't read matches")
+}
+
+// ReportMatch records the winner of match with passed round and position, updates ratings of
+// both users and advances the winner to the next round. When the final is reported, tournament
+// is finished with the ranking of the bracket. If tournament or match isn't found, function
+// returns ErrNotFound. If tournament isn't running, function returns ErrTournamentNotRunning.
+// If tournament isn't a single-elimination one, function returns ErrUnsupportedFormat.
+// See Bracket.Report for other errors.
+func (db *DB) ReportMatch(ctx context.Context, tournamentID int64, round, position uint32, winnerID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != sts.StatusRunning {
+		return sts.ErrTournamentNotRunning
+	}
+	if t.Format != sts.FormatSingleElimination {
+		return sts.ErrUnsupportedFormat
+	}
+	bracket, err := getBracket(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	next, err := bracket.Report(round, position, winnerID)
+	if err != nil {
+		return err
+	}
+	m := bracket.Match(round, position)
+	err = saveMatch(ctx, tx, tournamentID, *m)
+	if err != nil {
+		return err
+	}
+	err = rateGames(ctx, tx, tournamentID, []sts.Game{{User1: m.User1, User2: m.User2, Score: m.Score()}})
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		err = saveMatch(ctx, tx, tournamentID, *next)
+	} else {
+		err = finish(ctx, tx, t, bracket.Ranking(), db.now())
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+func saveMatch(ctx context.Context, tx *sqlx.Tx, tournamentID int64, m sts.Match) error {
+	_, err := tx.ExecContext(ctx, `
+UPDATE matches
+   SET user1_id = $1, user2_id = $2, winner_id = $3
+ WHERE tournament_id = $4 AND round = $5 AND position = $6`, nullID(m.User1), nullID(m.User2), nullID(m.Winner),
+		tournamentID, m.Round, m.Position)
+	return errors.Wrap(err, "couldn't save match")
+}
+
+// nullID converts zero id to NULL.
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: id,
+		Valid: id != 0,
+	}
+}
@@ -0,0 +1,41 @@
+package psql
+
+import (
+	"strings"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// Codes of postgres errors that break rules of db.
+const (
+	codeNotNullViolation = "23502"
+	codeUniqueViolation  = "23505"
+	codeCheckViolation   = "23514"
+	classDataException   = "22"
+)
+
+// dbError translates err that breaks rules of db to an error of the service. Negative balance
+// is ErrInsufficientFunds, other broken checks and invalid data are ErrValidation. The rest of
+// errors are wrapped with message.
+func dbError(err error, message string) error {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	if !ok {
+		return errors.Wrap(err, message)
+	}
+	switch {
+	case pqErr.Code == codeCheckViolation && strings.HasSuffix(pqErr.Constraint, "_balance_check"):
+		return sts.ErrInsufficientFunds
+	case pqErr.Code == codeCheckViolation, pqErr.Code == codeNotNullViolation,
+		pqErr.Code.Class() == classDataException:
+		return sts.ErrValidation
+	}
+	return errors.Wrap(err, message)
+}
+
+// duplicate reports whether err is a violation of a unique key.
+func duplicate(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	return ok && pqErr.Code == codeUniqueViolation
+}
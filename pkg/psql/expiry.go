@@ -0,0 +1,180 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// addLot records that amount of points that has just been credited to user expires at expiresAt.
+func addLot(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount uint64,
+	expiresAt time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO point_lots (user_id, currency, amount, expires_at)
+     VALUES ($1, $2, $3, $4)`, userID, currency, amount, expiresAt)
+	return dbError(err, "couldn't add expiring points")
+}
+
+// spendLots takes amount of points from expiring credits of user, the soonest ones first, and returns
+// how much has been taken from each credit. Credits that have expired by now aren't spent. Points
+// that are paid as entry fee are remembered, so that refund returns them to their credits.
+func spendLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, amount uint64,
+	reason sts.Reason, tournamentID int64, now time.Time) ([]sts.Lot, error) {
+	var lots []sts.Lot
+	err := tx.SelectContext(ctx, &lots, `
+  SELECT id, amount, expires_at AS expiresat
+    FROM point_lots
+   WHERE user_id = $1 AND currency = $2 AND amount > 0
+ORDER BY expires_at, id
+     FOR UPDATE`, userID, currency)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get expiring points")
+	}
+	spentLots := sts.SpendLots(lots, amount, now)
+	for _, lot := range spentLots {
+		_, err = tx.ExecContext(ctx, `
+UPDATE point_lots
+   SET amount = amount - $1
+ WHERE id = $2`, lot.Amount, lot.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't spend expiring points")
+		}
+		if reason != sts.ReasonEntryFee || tournamentID == 0 {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO lot_spends (lot_id, tournament_id, amount)
+     VALUES ($1, $2, $3)
+ON CONFLICT (lot_id, tournament_id) DO UPDATE SET amount = lot_spends.amount + EXCLUDED.amount`,
+			lot.ID, tournamentID, lot.Amount)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't record spent points")
+		}
+	}
+	return spentLots, nil
+}
+
+// restoreLots returns expiring points that user has paid as entry fee of tournament with passed
+// tournamentID to their credits. Points of credits that have already expired are taken by the next expiry.
+func restoreLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, tournamentID int64) error {
+	_, err := tx.ExecContext(ctx, `
+UPDATE point_lots AS l
+   SET amount = l.amount + s.amount
+  FROM lot_spends AS s
+ WHERE s.lot_id = l.id AND s.tournament_id = $1 AND l.user_id = $2 AND l.currency = $3`,
+		tournamentID, userID, currency)
+	if err != nil {
+		return errors.Wrap(err, "couldn't restore expiring points")
+	}
+	_, err = tx.ExecContext(ctx, `
+DELETE
+  FROM lot_spends AS s
+ USING point_lots AS l
+ WHERE s.lot_id = l.id AND s.tournament_id = $1 AND l.user_id = $2 AND l.currency = $3`,
+		tournamentID, userID, currency)
+	return errors.Wrap(err, "couldn't forget spent points")
+}
+
+// expirations returns points of user with passed userID that expire after now, the soonest ones first.
+func expirations(ctx context.Context, q sqlx.QueryerContext, userID int64, now time.Time) ([]sts.Expiration, error) {
+	result := []sts.Expiration{}
+	err := sqlx.SelectContext(ctx, q, &result, `
+  SELECT currency, amount, expires_at AS expiresat
+    FROM point_lots
+   WHERE user_id = $1 AND amount > 0 AND expires_at > $2
+ORDER BY expires_at, id`, userID, now)
+	return result, errors.Wrap(err, "couldn't load expirations")
+}
+
+// ExpirePoints takes points that have expired by passed time from their owners
+// and records the expiry in the ledger.
+func (db *DB) ExpirePoints(ctx context.Context, now time.Time) error {
+	var wallets []struct {
+		UserID   int64        `db:"user_id"`
+		Currency sts.Currency `db:"currency"`
+	}
+	err := db.conn.SelectContext(ctx, &wallets, `
+SELECT DISTINCT user_id, currency
+  FROM point_lots
+ WHERE expires_at <= $1 AND amount > 0`, now)
+	if err != nil {
+		return errors.Wrap(err, "couldn't get expired points")
+	}
+	for _, w := range wallets {
+		err = db.expireWallet(ctx, w.UserID, w.Currency, now)
+		if err != nil {
+			return err
+		}
+	}
+	// Credits are kept while their points can still be refunded from a tournament.
+	_, err = db.conn.ExecContext(ctx, `
+DELETE
+  FROM point_lots AS l
+ WHERE l.amount = 0 AND l.expires_at <= $1
+       AND NOT EXISTS(SELECT 1
+                        FROM lot_spends AS s
+                             JOIN tournaments AS t ON t.id = s.tournament_id
+                       WHERE s.lot_id = l.id AND t.status NOT IN ($2, $3))`,
+		now, sts.StatusFinished, sts.StatusCancelled)
+	return errors.Wrap(err, "couldn't delete spent points")
+}
+
+func (db *DB) expireWallet(ctx context.Context, userID int64, currency sts.Currency, now time.Time) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	err = expireLots(ctx, tx, userID, currency, now)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// expireLots takes points of credits of user that have expired by now from the wallet of passed
+// currency and records the expiry in the ledger. Wallet stays locked until transaction ends.
+func expireLots(ctx context.Context, tx *sqlx.Tx, userID int64, currency sts.Currency, now time.Time) error {
+	// Wallet is locked before its credits, in the same order as changeBalance does.
+	err := lockWallet(ctx, tx, userID, currency)
+	if err != nil {
+		return err
+	}
+	var lots []sts.Lot
+	err = tx.SelectContext(ctx, &lots, `
+SELECT id, amount
+  FROM point_lots
+ WHERE user_id = $1 AND currency = $2 AND expires_at <= $3 AND amount > 0
+   FOR UPDATE`, userID, currency, now)
+	if err != nil {
+		return errors.Wrap(err, "couldn't get expired points")
+	}
+	var expired uint64
+	for _, lot := range lots {
+		expired += lot.Amount
+		_, err = tx.ExecContext(ctx, `
+UPDATE point_lots
+   SET amount = 0
+ WHERE id = $1`, lot.ID)
+		if err != nil {
+			return errors.Wrap(err, "couldn't expire points")
+		}
+	}
+	if expired == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE balances
+   SET balance = balance - $1
+ WHERE user_id = $2 AND currency = $3`, expired, userID, currency)
+	if err != nil {
+		return errors.Wrap(err, "couldn't update balance")
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO transactions (user_id, amount, currency, reason)
+     VALUES ($1, $2, $3, $4)`, userID, -int64(expired), currency, sts.ReasonExpiry)
+	return errors.Wrap(err, "couldn't record transaction")
+}
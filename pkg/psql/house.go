@@ -0,0 +1,129 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// changeHouseBalance adds amount to balance of house account with passed name and currency.
+// If account isn't found, function returns ErrNotFound. If account doesn't have enough points,
+// function returns ErrInsufficientBudget.
+func changeHouseBalance(ctx context.Context, tx *sqlx.Tx, name string, currency sts.Currency, amount int64) error {
+	if amount == 0 {
+		return nil
+	}
+	update, err := tx.ExecContext(ctx, `
+UPDATE house_accounts
+   SET balance = balance + $1
+ WHERE name = $2 AND currency = $3`, amount, name, currency)
+	err = dbError(err, fmt.Sprintf("couldn't update balance of house account [%s]", name))
+	if err == sts.ErrInsufficientFunds {
+		return sts.ErrInsufficientBudget
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := update.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 0 {
+		return sts.ErrNotFound
+	}
+	return nil
+}
+
+// GetHouseAccount returns house account with passed name and currency. If account isn't found,
+// function returns ErrNotFound.
+func (db *DB) GetHouseAccount(ctx context.Context, name string, currency sts.Currency) (*sts.HouseAccount, error) {
+	var account sts.HouseAccount
+	err := db.conn.GetContext(ctx, &account, `
+SELECT name, currency, balance
+  FROM house_accounts
+ WHERE name = $1 AND currency = $2`, name, currency)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get house account")
+	}
+	return &account, nil
+}
+
+// nullAccount returns NULL for an empty house account name.
+func nullAccount(name string) sql.NullString {
+	return sql.NullString{String: name, Valid: name != ""}
+}
+
+// checkSponsor returns ErrInvalidGuarantee if house account with passed name and currency doesn't
+// exist. Empty name means that tournament doesn't have a sponsor.
+func checkSponsor(ctx context.Context, tx *sqlx.Tx, name string, currency sts.Currency) error {
+	if name == "" {
+		return nil
+	}
+	var exists bool
+	err := tx.QueryRowContext(ctx, `
+SELECT EXISTS(SELECT 1 FROM house_accounts WHERE name = $1 AND currency = $2)`, name, currency).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "couldn't check sponsor")
+	}
+	if !exists {
+		return sts.ErrInvalidGuarantee
+	}
+	return nil
+}
+
+// reserveGuarantee takes guaranteed prize of locked tournament from the budget of its sponsor until
+// tournament finishes or is cancelled. If sponsor doesn't have enough points, function returns
+// ErrInsufficientBudget.
+func reserveGuarantee(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament) error {
+	return changeHouseBalance(ctx, tx, t.Sponsor, t.Currency, -int64(t.GuaranteedPrize))
+}
+
+// releaseGuarantee returns the budget that is reserved for guaranteed prize of locked tournament
+// to its sponsor.
+func releaseGuarantee(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament) error {
+	return changeHouseBalance(ctx, tx, t.Sponsor, t.Currency, int64(t.ReservedBudget()))
+}
+
+// payOverlay pays the overlay of guaranteed prize of locked tournament out of the budget reserved
+// when registration opened, returns the rest of the budget to the sponsor and records the overlay.
+func payOverlay(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament) error {
+	overlay := t.PrizeOverlay()
+	err := changeHouseBalance(ctx, tx, t.Sponsor, t.Currency, int64(t.GuaranteedPrize-overlay))
+	if err != nil {
+		return err
+	}
+	if overlay == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE tournaments
+   SET overlay = $1
+ WHERE id = $2`, overlay, t.ID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't save overlay")
+	}
+	t.Overlay = overlay
+	return nil
+}
+
+// FundHouseAccount adds points to house account with passed name and currency, e.g. to the budget
+// of a sponsor. If account isn't found, function returns ErrNotFound.
+func (db *DB) FundHouseAccount(ctx context.Context, name string, currency sts.Currency, points uint64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	err = changeHouseBalance(ctx, tx, name, currency, int64(points))
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
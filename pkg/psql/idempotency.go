@@ -0,0 +1,86 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// useIdempotencyKey claims idempotency key from ctx for passed request. If the request has already
+// taken effect with this key, function returns its stored response and true. If the key has been used
+// for another request, function returns ErrIdempotencyKeyReused. Expired keys are claimed anew.
+// Concurrent retries wait for the first one to commit or roll back its transaction.
+func (db *DB) useIdempotencyKey(ctx context.Context, tx *sqlx.Tx, request string) (string, bool, error) {
+	key, ok := sts.IdempotencyKey(ctx)
+	if !ok {
+		return "", false, nil
+	}
+	if key == "" || len(key) > sts.MaxIdempotencyKeyLength {
+		return "", false, sts.ErrInvalidIdempotencyKey
+	}
+	now := db.now()
+	insert, err := tx.ExecContext(ctx, `
+INSERT INTO idempotency_keys (idempotency_key, request, created_at)
+     VALUES ($1, $2, $3)
+ON CONFLICT (idempotency_key) DO NOTHING`, key, request, now)
+	if err != nil {
+		return "", false, errors.Wrap(err, "couldn't store idempotency key")
+	}
+	rows, err := insert.RowsAffected()
+	if err != nil {
+		return "", false, errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 1 {
+		return "", false, nil
+	}
+
+	var stored struct {
+		Request   string    `db:"request"`
+		Response  string    `db:"response"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = tx.GetContext(ctx, &stored, `
+SELECT request, response, created_at
+  FROM idempotency_keys
+ WHERE idempotency_key = $1
+   FOR UPDATE`, key)
+	if err != nil {
+		return "", false, errors.Wrap(err, "couldn't get idempotency key")
+	}
+	if !now.Before(stored.CreatedAt.Add(db.keyTTL)) {
+		_, err = tx.ExecContext(ctx, `
+UPDATE idempotency_keys
+   SET request = $2, response = '', created_at = $3
+ WHERE idempotency_key = $1`, key, request, now)
+		return "", false, errors.Wrap(err, "couldn't renew idempotency key")
+	}
+	if stored.Request != request {
+		return "", false, sts.ErrIdempotencyKeyReused
+	}
+	return stored.Response, true, nil
+}
+
+// saveIdempotentResponse stores response of request whose idempotency key is in ctx.
+func saveIdempotentResponse(ctx context.Context, tx *sqlx.Tx, response string) error {
+	key, ok := sts.IdempotencyKey(ctx)
+	if !ok {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+UPDATE idempotency_keys
+   SET response = $2
+ WHERE idempotency_key = $1`, key, response)
+	return errors.Wrap(err, "couldn't store idempotent response")
+}
+
+// DeleteExpiredIdempotencyKeys deletes idempotency keys that have expired by passed time.
+func (db *DB) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	_, err := db.conn.ExecContext(ctx, `
+DELETE
+  FROM idempotency_keys
+ WHERE created_at <= $1`, now.Add(-db.keyTTL))
+	return errors.Wrap(err, "couldn't delete expired idempotency keys")
+}
@@ -0,0 +1,203 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// inviteColumns lists columns of invite_codes table in the order scanInviteCode reads them.
+const inviteColumns = `code, tournament_id, expires_at, max_uses, uses, revoked, created_at`
+
+func scanInviteCode(row scanner, c *sts.InviteCode) error {
+	return row.Scan(&c.Code, &c.TournamentID, &c.ExpiresAt, &c.MaxUses, &c.Uses, &c.Revoked, &c.CreatedAt)
+}
+
+// useInviteCode counts a use of passed code of locked private tournament. If code isn't valid
+// at passed time, function returns ErrInvalidInviteCode. Public tournaments don't need a code.
+func useInviteCode(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, code string, now time.Time) error {
+	if !t.IsPrivate() {
+		return nil
+	}
+	var invite sts.InviteCode
+	err := scanInviteCode(tx.QueryRowContext(ctx, `
+SELECT `+inviteColumns+`
+  FROM invite_codes
+ WHERE code = $1 AND tournament_id = $2
+   FOR UPDATE`, code, t.ID), &invite)
+	if err == sql.ErrNoRows {
+		return sts.ErrInvalidInviteCode
+	}
+	if err != nil {
+		return errors.Wrap(err, "couldn't load invite code")
+	}
+	if !invite.Valid(now) {
+		return sts.ErrInvalidInviteCode
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE invite_codes
+   SET uses = uses + 1
+ WHERE code = $1`, code)
+	return errors.Wrap(err, "couldn't use invite code")
+}
+
+// lockOrganizedTournament locks private tournament with passed id and checks that it's organized
+// by user with passed organizerID. See CreateInviteCode for errors.
+func lockOrganizedTournament(ctx context.Context, tx *sqlx.Tx, id, organizerID int64) error {
+	t, err := lockTournament(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	return t.CheckOrganizer(organizerID)
+}
+
+// CreateInviteCode generates a new invite code of private tournament with passed tournamentID.
+// If tournament isn't found, function returns ErrNotFound. If tournament is public, function
+// returns ErrPublicTournament. If user with passed organizerID doesn't organize tournament,
+// function returns ErrNotOrganizer.
+func (db *DB) CreateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	settings sts.InviteSettings) (*sts.InviteCode, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := addInviteCode(ctx, tx, tournamentID, settings, db.now())
+	if err != nil {
+		return nil, err
+	}
+	return invite, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+func addInviteCode(ctx context.Context, tx *sqlx.Tx, tournamentID int64, settings sts.InviteSettings,
+	now time.Time) (*sts.InviteCode, error) {
+	code, err := sts.NewInviteCode()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't generate invite code")
+	}
+	invite := sts.InviteCode{
+		Code:         code,
+		TournamentID: tournamentID,
+		ExpiresAt:    settings.ExpiresAt,
+		MaxUses:      settings.MaxUses,
+		CreatedAt:    now,
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO invite_codes (code, tournament_id, expires_at, max_uses, created_at)
+     VALUES ($1, $2, $3, $4, $5)`, invite.Code, tournamentID, invite.ExpiresAt, invite.MaxUses, invite.CreatedAt)
+	if err != nil {
+		return nil, dbError(err, "couldn't add invite code")
+	}
+	return &invite, nil
+}
+
+// GetInviteCodes returns every invite code of private tournament with passed tournamentID,
+// starting from the oldest one. See CreateInviteCode for errors.
+func (db *DB) GetInviteCodes(ctx context.Context, tournamentID, organizerID int64) ([]sts.InviteCode, error) {
+	var t sts.Tournament
+	err := scanTournament(db.conn.QueryRowContext(ctx, `
+SELECT `+tournamentColumns+`
+  FROM tournaments AS t
+ WHERE t.id = $1`, tournamentID), &t)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load tournament")
+	}
+	err = t.CheckOrganizer(organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+  SELECT `+inviteColumns+`
+    FROM invite_codes
+   WHERE tournament_id = $1
+ORDER BY created_at, code`, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get invite codes")
+	}
+	defer rows.Close()
+	codes := []sts.InviteCode{}
+	for rows.Next() {
+		var c sts.InviteCode
+		err = scanInviteCode(rows, &c)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan invite code")
+		}
+		codes = append(codes, c)
+	}
+	return codes, errors.Wrap(rows.Err(), "couldn't read invite codes")
+}
+
+// RevokeInviteCode makes passed code of private tournament with passed tournamentID unusable.
+// If code isn't found, function returns ErrNotFound. See CreateInviteCode for other errors.
+func (db *DB) RevokeInviteCode(ctx context.Context, tournamentID, organizerID int64, code string) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return err
+	}
+	_, err = revokeInviteCode(ctx, tx, tournamentID, code)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// revokeInviteCode revokes passed code of tournament with passed tournamentID and returns its
+// limits. If code isn't found, function returns ErrNotFound.
+func revokeInviteCode(ctx context.Context, tx *sqlx.Tx, tournamentID int64, code string) (*sts.InviteSettings,
+	error) {
+	var settings sts.InviteSettings
+	err := tx.QueryRowContext(ctx, `
+   UPDATE invite_codes
+      SET revoked = TRUE
+    WHERE code = $1 AND tournament_id = $2
+RETURNING expires_at, max_uses`, code, tournamentID).Scan(&settings.ExpiresAt, &settings.MaxUses)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't revoke invite code")
+	}
+	return &settings, nil
+}
+
+// RegenerateInviteCode revokes passed code of private tournament with passed tournamentID and
+// returns a new code with the same limits. Uses of the new code start from zero. If code isn't
+// found, function returns ErrNotFound. See CreateInviteCode for other errors.
+func (db *DB) RegenerateInviteCode(ctx context.Context, tournamentID, organizerID int64,
+	code string) (*sts.InviteCode, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	err = lockOrganizedTournament(ctx, tx, tournamentID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := revokeInviteCode(ctx, tx, tournamentID, code)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := addInviteCode(ctx, tx, tournamentID, *settings, db.now())
+	if err != nil {
+		return nil, err
+	}
+	return invite, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
@@ -0,0 +1,128 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// addLeaderboardStats adds results of tournament finished at passed time to leaderboards
+// of every period.
+func addLeaderboardStats(ctx context.Context, tx *sqlx.Tx, results []sts.LeaderboardEntry, now time.Time) error {
+	for _, period := range sts.Periods {
+		for _, r := range results {
+			_, err := tx.ExecContext(ctx, `
+INSERT INTO leaderboard_stats AS s (period, period_start, user_id, prize, wins, tournaments, profit)
+     VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (period, period_start, user_id) DO UPDATE
+        SET prize       = s.prize + EXCLUDED.prize,
+            wins        = s.wins + EXCLUDED.wins,
+            tournaments = s.tournaments + EXCLUDED.tournaments,
+            profit      = s.profit + EXCLUDED.profit`, period, sts.PeriodStart(period, now), r.UserID, r.Prize,
+				r.Wins, r.Tournaments, r.Profit)
+			if err != nil {
+				return errors.Wrap(err, "couldn't update leaderboard")
+			}
+		}
+	}
+	return nil
+}
+
+// leaderboardColumn returns column of leaderboard_stats that holds passed statistic.
+func leaderboardColumn(order sts.LeaderboardOrder) string {
+	switch order {
+	case sts.OrderWins:
+		return "wins"
+	case sts.OrderProfit:
+		return "profit"
+	}
+	return "prize"
+}
+
+// GetLeaderboard returns a page of leaderboard of users that have finished tournaments in
+// requested period. If query is incorrect, function returns ErrInvalidLeaderboard.
+func (db *DB) GetLeaderboard(ctx context.Context, q sts.LeaderboardQuery) (*sts.Leaderboard, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	at := q.At
+	if at.IsZero() {
+		at = db.now()
+	}
+	start := sts.PeriodStart(q.Period, at)
+	column := leaderboardColumn(q.Order)
+
+	rows, err := db.conn.QueryContext(ctx, `
+  SELECT user_id, prize, wins, tournaments, profit
+    FROM leaderboard_stats
+   WHERE period = $1 AND period_start = $2
+ORDER BY `+column+` DESC, user_id
+   LIMIT $3 OFFSET $4`, q.Period, start, q.Limit, q.Offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get leaderboard")
+	}
+	defer rows.Close()
+	leaderboard := sts.Leaderboard{
+		Entries: []sts.LeaderboardEntry{},
+	}
+	for rows.Next() {
+		var e sts.LeaderboardEntry
+		err = rows.Scan(&e.UserID, &e.Prize, &e.Wins, &e.Tournaments, &e.Profit)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan leaderboard entry")
+		}
+		leaderboard.Entries = append(leaderboard.Entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "couldn't read leaderboard")
+	}
+	if len(leaderboard.Entries) != 0 {
+		first := leaderboard.Entries[0]
+		rank, err := db.leaderboardRank(ctx, q.Period, start, column, first.Value(q.Order))
+		if err != nil {
+			return nil, err
+		}
+		sts.RankEntries(leaderboard.Entries, q.Order, rank, q.Offset)
+	}
+
+	if q.UserID == 0 {
+		return &leaderboard, nil
+	}
+	var me sts.LeaderboardEntry
+	err = db.conn.QueryRowContext(ctx, `
+SELECT user_id, prize, wins, tournaments, profit
+  FROM leaderboard_stats
+ WHERE period = $1 AND period_start = $2 AND user_id = $3`, q.Period, start, q.UserID).Scan(&me.UserID, &me.Prize,
+		&me.Wins, &me.Tournaments, &me.Profit)
+	if err == sql.ErrNoRows {
+		return &leaderboard, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get leaderboard entry")
+	}
+	me.Rank, err = db.leaderboardRank(ctx, q.Period, start, column, me.Value(q.Order))
+	if err != nil {
+		return nil, err
+	}
+	leaderboard.Me = &me
+	return &leaderboard, nil
+}
+
+// leaderboardRank returns rank of passed value of column in leaderboard of passed period.
+func (db *DB) leaderboardRank(ctx context.Context, period sts.Period, start time.Time, column string,
+	value int64) (uint64, error) {
+	var higher uint64
+	err := db.conn.QueryRowContext(ctx, `
+SELECT COUNT(*)
+  FROM leaderboard_stats
+ WHERE period = $1 AND period_start = $2 AND `+column+` > $3`, period, start, value).Scan(&higher)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't get leaderboard rank")
+	}
+	return higher + 1, nil
+}
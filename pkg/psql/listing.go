@@ -0,0 +1,128 @@
+package psql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+// tournamentSort holds a condition that skips tournaments up to the cursor ($8 is its id, $9 is
+// its key) and an order of listed tournaments.
+type tournamentSort struct {
+	after string
+	order string
+}
+
+var tournamentSorts = map[sts.TournamentSort]tournamentSort{
+	sts.SortNewest: {
+		after: "($8 = 0 OR (t.created_at, t.id) < ($9::TIMESTAMPTZ, $8))",
+		order: "t.created_at DESC, t.id DESC",
+	},
+	sts.SortLargestPrize: {
+		after: "($8 = 0 OR (t.prize, t.id) < ($9::BIGINT, $8))",
+		order: "t.prize DESC, t.id DESC",
+	},
+	sts.SortSoonestStart: {
+		after: "t.starts_at IS NOT NULL AND ($8 = 0 OR (t.starts_at, t.id) > ($9::TIMESTAMPTZ, $8))",
+		order: "t.starts_at, t.id",
+	},
+}
+
+// ListTournaments returns a page of tournaments that are visible to the viewer of the query and
+// match its filters, in its sort order and starting after its cursor. If query is incorrect,
+// function returns ErrInvalidListing.
+func (db *DB) ListTournaments(ctx context.Context, q sts.TournamentQuery) (*sts.TournamentPage, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	key, afterID, err := sts.DecodeCursor(q.After)
+	if err != nil {
+		return nil, err
+	}
+	var after interface{} = key
+	if q.Sort != sts.SortLargestPrize {
+		after = sts.CursorTime(key)
+	}
+	sort := tournamentSorts[q.Sort]
+	var ids []int64
+	err = db.conn.SelectContext(ctx, &ids, `
+  SELECT t.id
+    FROM tournaments AS t
+   WHERE (t.visibility = 'public' OR
+          $10 <> 0 AND (t.organizer_id = $10 OR
+                        EXISTS(SELECT 1 FROM participants AS p WHERE p.tournament_id = t.id AND p.user_id = $10) OR
+                        EXISTS(SELECT 1 FROM waitlist AS w WHERE w.tournament_id = t.id AND w.user_id = $10)))
+     AND ($1 = '' OR t.status = $1)
+     AND t.deposit >= $2 AND ($3 = 0 OR t.deposit <= $3)
+     AND t.name LIKE $4
+     AND ($5::TIMESTAMPTZ IS NULL OR t.created_at >= $5)
+     AND ($6::TIMESTAMPTZ IS NULL OR t.created_at < $6)
+     AND `+sort.after+`
+ORDER BY `+sort.order+`
+   LIMIT $7`, q.Status, q.MinDeposit, q.MaxDeposit, likePrefix(q.NamePrefix), q.CreatedAfter, q.CreatedBefore,
+		q.Limit+1, afterID, after, q.ViewerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list tournaments")
+	}
+	page := sts.TournamentPage{
+		Tournaments: []sts.Tournament{},
+	}
+	for i, id := range ids {
+		if uint64(i) == q.Limit {
+			page.NextCursor = sts.TournamentCursor(&page.Tournaments[i-1], q.Sort)
+			break
+		}
+		t, err := db.GetTournament(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		page.Tournaments = append(page.Tournaments, *t)
+	}
+	return &page, nil
+}
+
+// ListUsers returns a page of users ordered by id, starting after the cursor of the query.
+// If query is incorrect, function returns ErrInvalidListing.
+func (db *DB) ListUsers(ctx context.Context, q sts.UserQuery) (*sts.UserPage, error) {
+	err := q.Validate()
+	if err != nil {
+		return nil, err
+	}
+	_, afterID, err := sts.DecodeCursor(q.After)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	err = db.conn.SelectContext(ctx, &ids, `
+  SELECT id
+    FROM users
+   WHERE id > $1
+ORDER BY id
+   LIMIT $2`, afterID, q.Limit+1)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list users")
+	}
+	page := sts.UserPage{
+		Users: []sts.User{},
+	}
+	for i, id := range ids {
+		if uint64(i) == q.Limit {
+			page.NextCursor = sts.UserCursor(page.Users[i-1].ID)
+			break
+		}
+		user, err := db.GetUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		page.Users = append(page.Users, *user)
+	}
+	return &page, nil
+}
+
+// likePrefix returns a pattern of LIKE that matches strings starting with prefix.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
@@ -0,0 +1,173 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// PairRound pairs participants of running round-robin or Swiss tournament with passed
+// tournamentID for the next round and returns games of this round. If tournament isn't
+// found, function returns ErrNotFound. If tournament isn't running, function returns
+// ErrTournamentNotRunning. If tournament format doesn't have pairings, function returns
+// ErrUnsupportedFormat. If current round has unreported games, function returns
+// ErrRoundNotFinished. If tournament has less than two participants, function returns
+// ErrNotEnoughPlayers.
+func (db *DB) PairRound(ctx context.Context, tournamentID int64) (sts.Pairings, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != sts.StatusRunning {
+		return nil, sts.ErrTournamentNotRunning
+	}
+	if !t.HasPairings() {
+		return nil, sts.ErrUnsupportedFormat
+	}
+	users, err := participants(ctx, tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) < 2 {
+		return nil, sts.ErrNotEnoughPlayers
+	}
+	previous, err := getPairings(ctx, tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if !previous.RoundFinished() || previous.LastRound() >= t.TotalRounds(len(users)) {
+		return nil, sts.ErrRoundNotFinished
+	}
+
+	round := previous.LastRound() + 1
+	var pairings sts.Pairings
+	if t.Format == sts.FormatRoundRobin {
+		pairings = sts.PairRoundRobin(users, round)
+	} else {
+		pairings = sts.PairSwiss(users, previous, round)
+	}
+	for _, p := range pairings {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO pairings (tournament_id, round, board, user1_id, user2_id, result)
+     VALUES ($1, $2, $3, $4, $5, $6)`, tournamentID, p.Round, p.Board, p.User1, nullID(p.User2), p.Result)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't add pairing")
+		}
+	}
+	return pairings, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// GetPairings returns games of tournament with passed tournamentID ordered by round and
+// board. If tournament isn't found, function returns ErrNotFound.
+func (db *DB) GetPairings(ctx context.Context, tournamentID int64) (sts.Pairings, error) {
+	err := db.checkTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return getPairings(ctx, db.conn, tournamentID)
+}
+
+func getPairings(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) (sts.Pairings, error) {
+	rows, err := q.QueryContext(ctx, `
+  SELECT round, board, user1_id, user2_id, result
+    FROM pairings
+   WHERE tournament_id = $1
+ORDER BY round, board`, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get pairings")
+	}
+	defer rows.Close()
+	pairings := sts.Pairings{}
+	for rows.Next() {
+		var (
+			p            sts.Pairing
+			user1, user2 sql.NullInt64
+		)
+		err = rows.Scan(&p.Round, &p.Board, &user1, &user2, &p.Result)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan pairing")
+		}
+		p.User1, p.User2 = user1.Int64, user2.Int64
+		pairings = append(pairings, p)
+	}
+	return pairings, errors.Wrap(rows.Err(), "couldn't read pairings")
+}
+
+// ReportResult records result of game with passed round and board and updates ratings of
+// both users. When the last game of the last round is reported, tournament is finished with
+// the ranking of its standings. If tournament or game isn't found, function returns ErrNotFound.
+// If tournament isn't running, function returns ErrTournamentNotRunning. See Pairings.Report
+// for other errors.
+func (db *DB) ReportResult(ctx context.Context, tournamentID int64, round, board uint32, result sts.Result) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != sts.StatusRunning {
+		return sts.ErrTournamentNotRunning
+	}
+	pairings, err := getPairings(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = pairings.Report(round, board, result)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE pairings
+   SET result = $1
+ WHERE tournament_id = $2 AND round = $3 AND board = $4`, result, tournamentID, round, board)
+	if err != nil {
+		return errors.Wrap(err, "couldn't save result")
+	}
+
+	g := pairings.Pairing(round, board)
+	err = rateGames(ctx, tx, tournamentID, []sts.Game{{User1: g.User1, User2: g.User2, Score: g.Score()}})
+	if err != nil {
+		return err
+	}
+
+	users, err := participants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if pairings.RoundFinished() && pairings.LastRound() >= t.TotalRounds(len(users)) {
+		err = finish(ctx, tx, t, sts.StandingsRanking(sts.Standings(users, pairings)), db.now())
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// GetStandings returns standings of tournament with passed tournamentID from the leader.
+// If tournament isn't found, function returns ErrNotFound.
+func (db *DB) GetStandings(ctx context.Context, tournamentID int64) ([]sts.Standing, error) {
+	err := db.checkTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := participants(ctx, db.conn, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	pairings, err := getPairings(ctx, db.conn, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return sts.Standings(users, pairings), nil
+}
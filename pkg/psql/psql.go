@@ -2,7 +2,9 @@ package psql
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/illfate/social-tournaments-service/pkg/sts"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 
@@ -11,7 +13,9 @@ import (
 )
 
 type DB struct {
-	conn *sqlx.DB
+	conn   *sqlx.DB
+	now    func() time.Time
+	keyTTL time.Duration
 }
 
 func New(dbUser, dbHost, dbPass, dbName string) (*DB, error) {
@@ -21,10 +25,22 @@ func New(dbUser, dbHost, dbPass, dbName string) (*DB, error) {
 		return nil, errors.Wrap(err, "couldn't connect to db")
 	}
 	return &DB{
-		conn: db,
+		conn:   db,
+		now:    time.Now,
+		keyTTL: sts.DefaultIdempotencyKeyTTL,
 	}, nil
 }
 
+// SetClock replaces function that is used to get current time, e.g. to check registration windows.
+func (db *DB) SetClock(now func() time.Time) {
+	db.now = now
+}
+
+// SetIdempotencyKeyTTL changes how long idempotency keys are kept. Retries after that take effect again.
+func (db *DB) SetIdempotencyKeyTTL(ttl time.Duration) {
+	db.keyTTL = ttl
+}
+
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
@@ -0,0 +1,113 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// checkRating returns ErrRatingOutOfRange if rating of user with passed userID doesn't allow
+// to join tournament. If user isn't found, function returns ErrNotFound.
+func checkRating(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, userID int64) error {
+	if t.MinRating == 0 && t.MaxRating == 0 {
+		return nil
+	}
+	var rating float64
+	err := tx.QueryRowContext(ctx, `
+SELECT rating
+  FROM users
+ WHERE id = $1`, userID).Scan(&rating)
+	if err == sql.ErrNoRows {
+		return sts.ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "couldn't get user rating")
+	}
+	if !t.InRatingRange(rating) {
+		return sts.ErrRatingOutOfRange
+	}
+	return nil
+}
+
+// rateGames updates ratings of users that have played passed games of tournament with
+// passed tournamentID and records new ratings in the rating history.
+func rateGames(ctx context.Context, tx *sqlx.Tx, tournamentID int64, games []sts.Game) error {
+	ratings := make(map[int64]sts.Glicko)
+	for _, g := range games {
+		for _, id := range []int64{g.User1, g.User2} {
+			if _, ok := ratings[id]; ok {
+				continue
+			}
+			var r sts.Glicko
+			err := tx.QueryRowContext(ctx, `
+SELECT rating, rating_deviation, rating_volatility
+  FROM users
+ WHERE id = $1
+   FOR UPDATE`, id).Scan(&r.Rating, &r.Deviation, &r.Volatility)
+			if err == sql.ErrNoRows {
+				return sts.ErrNotFound
+			}
+			if err != nil {
+				return errors.Wrap(err, "couldn't load rating")
+			}
+			ratings[id] = r
+		}
+	}
+	updated, played := sts.RateGames(ratings, games)
+	for id, r := range updated {
+		_, err := tx.ExecContext(ctx, `
+UPDATE users
+   SET rating = $1, rating_deviation = $2, rating_volatility = $3, games = games + $4
+ WHERE id = $5`, r.Rating, r.Deviation, r.Volatility, played[id], id)
+		if err != nil {
+			return errors.Wrap(err, "couldn't update rating")
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO rating_history (user_id, tournament_id, rating, deviation, volatility)
+     VALUES ($1, $2, $3, $4, $5)`, id, nullID(tournamentID), r.Rating, r.Deviation, r.Volatility)
+		if err != nil {
+			return errors.Wrap(err, "couldn't add rating history")
+		}
+	}
+	return nil
+}
+
+// GetRatingHistory returns rating changes of user with passed userID, starting from the oldest
+// one. If user isn't found, function returns ErrNotFound.
+func (db *DB) GetRatingHistory(ctx context.Context, userID int64) ([]sts.RatingChange, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+  SELECT id, user_id, tournament_id, rating, deviation, created_at
+    FROM rating_history
+   WHERE user_id = $1
+ORDER BY id`, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get rating history")
+	}
+	defer rows.Close()
+	history := []sts.RatingChange{}
+	for rows.Next() {
+		var (
+			c            sts.RatingChange
+			tournamentID sql.NullInt64
+		)
+		err = rows.Scan(&c.ID, &c.UserID, &tournamentID, &c.Rating, &c.Deviation, &c.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan rating change")
+		}
+		c.TournamentID = tournamentID.Int64
+		history = append(history, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "couldn't read rating history")
+	}
+	if len(history) == 0 {
+		_, err = db.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
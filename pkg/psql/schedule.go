@@ -0,0 +1,78 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+// schedulerLockID identifies advisory lock that is held by the scheduler doing the work.
+const schedulerLockID = 7236104
+
+// DueTournaments returns tournaments whose status has to be changed at passed time
+// according to their schedule.
+func (db *DB) DueTournaments(ctx context.Context, now time.Time) (*sts.DueTournaments, error) {
+	var due sts.DueTournaments
+	err := db.conn.SelectContext(ctx, &due.Open, `
+SELECT id
+  FROM tournaments
+ WHERE status = $1 AND registration_opens_at <= $2`, sts.StatusDraft, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get tournaments to open")
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+   SELECT t.id, t.min_players,
+          CASE WHEN t.team_based THEN COUNT(DISTINCT p.team_id) ELSE COUNT(p.user_id) END
+     FROM tournaments AS t
+LEFT JOIN participants AS p ON t.id = p.tournament_id
+    WHERE t.status = $1 AND t.starts_at <= $2
+ GROUP BY t.id`, sts.StatusRegistration, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get tournaments to start")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id       int64
+			settings sts.TournamentSettings
+			entries  int
+		)
+		// min_players of team-based tournament limits the number of teams, like entrants counts them
+		err = rows.Scan(&id, &settings.MinPlayers, &entries)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan tournament")
+		}
+		if settings.HasEnoughPlayers(entries) {
+			due.Start = append(due.Start, id)
+		} else {
+			due.Cancel = append(due.Cancel, id)
+		}
+	}
+	return &due, errors.Wrap(rows.Err(), "couldn't read tournaments")
+}
+
+// TryLock tries to take the lock that is shared by every scheduler working with the db.
+// If the lock is taken, function returns function that releases it. Otherwise it returns nil.
+func (db *DB) TryLock(ctx context.Context) (func() error, error) {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get connection")
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, schedulerLockID).Scan(&locked)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "couldn't take lock")
+	}
+	if !locked {
+		return nil, conn.Close()
+	}
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, schedulerLockID)
+		return errors.Wrap(err, "couldn't release lock")
+	}, nil
+}
@@ -0,0 +1,405 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// CreateTeam adds team with passed name and makes user with passed captainID its captain and
+// the only member. It returns id of this team. If user isn't found, function returns ErrNotFound.
+func (db *DB) CreateTeam(ctx context.Context, name string, captainID int64) (int64, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+INSERT INTO teams (name, captain_id)
+     SELECT $1, id
+       FROM users
+      WHERE id = $2
+  RETURNING id`, name, captainID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, sts.ErrNotFound
+	}
+	if err != nil {
+		return 0, dbError(err, "couldn't add team")
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO team_members (team_id, user_id, share)
+     VALUES ($1, $2, 100)`, id, captainID)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't add team captain")
+	}
+	return id, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// GetTeam returns team with passed id. If team isn't found, function returns ErrNotFound.
+func (db *DB) GetTeam(ctx context.Context, id int64) (*sts.Team, error) {
+	var team sts.Team
+	err := db.conn.QueryRowContext(ctx, `
+SELECT id, name, captain_id
+  FROM teams
+ WHERE id = $1`, id).Scan(&team.ID, &team.Name, &team.CaptainID)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get team")
+	}
+	team.Members, err = teamMembers(ctx, db.conn, id)
+	if err != nil {
+		return nil, err
+	}
+	team.Invited = []int64{}
+	err = db.conn.SelectContext(ctx, &team.Invited, `
+  SELECT user_id
+    FROM team_invites
+   WHERE team_id = $1
+ORDER BY user_id`, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load team invites")
+	}
+	return &team, nil
+}
+
+func teamMembers(ctx context.Context, q sqlx.QueryerContext, teamID int64) ([]sts.TeamMember, error) {
+	rows, err := q.QueryContext(ctx, `
+  SELECT user_id, share
+    FROM team_members
+   WHERE team_id = $1
+ORDER BY user_id`, teamID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load team members")
+	}
+	defer rows.Close()
+	members := []sts.TeamMember{}
+	for rows.Next() {
+		var m sts.TeamMember
+		err = rows.Scan(&m.UserID, &m.Share)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan team member")
+		}
+		members = append(members, m)
+	}
+	return members, errors.Wrap(rows.Err(), "couldn't read team members")
+}
+
+// lockTeam locks team with passed id until the end of transaction and returns its members.
+// If team isn't found, function returns ErrNotFound.
+func lockTeam(ctx context.Context, tx *sqlx.Tx, id int64) ([]sts.TeamMember, error) {
+	err := tx.QueryRowContext(ctx, `
+SELECT id
+  FROM teams
+ WHERE id = $1
+   FOR UPDATE`, id).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load team")
+	}
+	return teamMembers(ctx, tx, id)
+}
+
+// InviteToTeam allows user with passed userID to join team with passed teamID. Inviting a member
+// of the team does nothing. If team or user isn't found, function returns ErrNotFound.
+func (db *DB) InviteToTeam(ctx context.Context, teamID, userID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			return nil
+		}
+	}
+	insert, err := tx.ExecContext(ctx, `
+INSERT INTO team_invites (team_id, user_id)
+     SELECT $1, id
+       FROM users
+      WHERE id = $2
+ON CONFLICT DO NOTHING`, teamID, userID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't invite user to team")
+	}
+	rows, err := insert.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 0 {
+		var exists bool
+		err = tx.QueryRowContext(ctx, `
+SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists)
+		if err != nil {
+			return errors.Wrap(err, "couldn't check user")
+		}
+		if !exists {
+			return sts.ErrNotFound
+		}
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// JoinTeam adds invited user with passed userID to team with passed teamID and splits shares
+// of the team equally between its members. If team isn't found, function returns ErrNotFound.
+// If user hasn't been invited, function returns ErrNotInvited. If team already has
+// MaxTeamMembers members, function returns ErrTeamFull.
+func (db *DB) JoinTeam(ctx context.Context, teamID, userID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	delete, err := tx.ExecContext(ctx, `
+DELETE
+  FROM team_invites
+ WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't remove team invite")
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 0 {
+		return sts.ErrNotInvited
+	}
+	if len(members) >= sts.MaxTeamMembers {
+		return sts.ErrTeamFull
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO team_members (team_id, user_id, share)
+     VALUES ($1, $2, 1)`, teamID, userID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't add user to team")
+	}
+	members = append(members, sts.TeamMember{UserID: userID})
+	for i, share := range sts.EqualShares(len(members)) {
+		members[i].Share = share
+	}
+	err = setTeamShares(ctx, tx, teamID, members)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// SetTeamShares sets percents of deposits and prizes that fall on members of team with passed
+// teamID. If team isn't found, function returns ErrNotFound. If shares aren't set for every
+// member, aren't positive or don't sum up to 100, function returns ErrInvalidTeamShares.
+func (db *DB) SetTeamShares(ctx context.Context, teamID int64, shares []sts.TeamMember) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	err = sts.ValidateTeamShares(shares, members)
+	if err != nil {
+		return err
+	}
+	err = setTeamShares(ctx, tx, teamID, shares)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+func setTeamShares(ctx context.Context, tx *sqlx.Tx, teamID int64, shares []sts.TeamMember) error {
+	for _, m := range shares {
+		_, err := tx.ExecContext(ctx, `
+UPDATE team_members
+   SET share = $1
+ WHERE team_id = $2 AND user_id = $3`, m.Share, teamID, m.UserID)
+		if err != nil {
+			return errors.Wrap(err, "couldn't update team share")
+		}
+	}
+	return nil
+}
+
+// teamEntries returns teams that have joined tournament with passed tournamentID with shares
+// of their members, ordered by team id.
+func teamEntries(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]sts.TeamEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+  SELECT team_id, user_id, share
+    FROM participants
+   WHERE tournament_id = $1 AND team_id IS NOT NULL
+ORDER BY team_id, user_id`, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load teams")
+	}
+	defer rows.Close()
+	var entries []sts.TeamEntry
+	for rows.Next() {
+		var (
+			teamID int64
+			m      sts.TeamMember
+		)
+		err = rows.Scan(&teamID, &m.UserID, &m.Share)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan team member")
+		}
+		if len(entries) == 0 || entries[len(entries)-1].TeamID != teamID {
+			entries = append(entries, sts.TeamEntry{TeamID: teamID})
+		}
+		last := &entries[len(entries)-1]
+		last.Members = append(last.Members, m)
+	}
+	return entries, errors.Wrap(rows.Err(), "couldn't read teams")
+}
+
+// JoinTournamentAsTeam registers team with passed teamID in team-based tournament with passed
+// tournamentID. Deposit is charged to team members according to their shares. If tournament,
+// team or one of its members isn't found, function returns ErrNotFound. If tournament isn't
+// team-based, function returns ErrSoloTournament. If tournament isn't open for registration or
+// current time is out of its registration window, function returns ErrTournamentClosed. If rating
+// of a member is out of tournament range, function returns ErrRatingOutOfRange. If tournament
+// is full, function returns ErrTournamentFull. If a member has already joined tournament,
+// function returns ErrAlreadyJoined. Private tournament requires a valid invite code,
+// otherwise function returns ErrInvalidInviteCode. Retries with the same idempotency key in ctx
+// succeed without joining again.
+func (db *DB) JoinTournamentAsTeam(ctx context.Context, tournamentID, teamID int64, inviteCode string) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	_, done, err := db.useIdempotencyKey(ctx, tx, sts.IdempotentRequest("join_team", tournamentID, teamID, inviteCode))
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if !t.TeamBased {
+		return sts.ErrSoloTournament
+	}
+	if t.Status != sts.StatusRegistration || !t.InRegistrationWindow(db.now()) {
+		return sts.ErrTournamentClosed
+	}
+	err = useInviteCode(ctx, tx, t, inviteCode, db.now())
+	if err != nil {
+		return err
+	}
+	members, err := lockTeam(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		err = checkRating(ctx, tx, t, m.UserID)
+		if err != nil {
+			return err
+		}
+	}
+	teams, err := teamEntries(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.IsFull(len(teams)) {
+		return sts.ErrTournamentFull
+	}
+	for _, team := range teams {
+		for _, joined := range team.Members {
+			for _, m := range members {
+				if joined.UserID == m.UserID {
+					return sts.ErrAlreadyJoined
+				}
+			}
+		}
+	}
+
+	entry := sts.TeamEntry{TeamID: teamID, Members: members}
+	for i, deposit := range entry.Split(t.Deposit) {
+		err = changeBalance(ctx, tx, members[i].UserID, t.Currency, -int64(deposit), sts.ReasonEntryFee,
+			tournamentID, db.now())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO participants (user_id, tournament_id, team_id, share)
+     VALUES ($1, $2, $3, $4)`, members[i].UserID, tournamentID, teamID, members[i].Share)
+		if duplicate(err) {
+			return sts.ErrAlreadyJoined
+		}
+		if err != nil {
+			return dbError(err, "couldn't add team member to tournament")
+		}
+	}
+	err = addEntries(ctx, tx, t, 1)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// finishTeams pays out prize of locked team-based tournament according to passed ranking of teams
+// and returns results of team members.
+func finishTeams(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64,
+	shares []uint32, now time.Time) ([]sts.LeaderboardEntry, error) {
+	entries, err := teamEntries(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	teams := make(map[int64]sts.TeamEntry, len(entries))
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		teams[e.TeamID] = e
+		ids = append(ids, e.TeamID)
+	}
+	err = sts.ValidateRanking(ranking, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := sts.TeamPayouts(t.PrizePool(), shares, ranking)
+	for _, p := range payouts {
+		_, err = tx.ExecContext(ctx, `
+UPDATE payouts
+   SET amount = $1, team_id = $2
+ WHERE tournament_id = $3 AND place = $4`, p.Amount, p.TeamID, t.ID, p.Place)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't save payout")
+		}
+	}
+	results := sts.TeamResults(ranking, payouts, t.Deposit, teams)
+	for _, r := range results {
+		if r.Prize > 0 {
+			err = changeBalance(ctx, tx, r.UserID, t.Currency, int64(r.Prize), sts.ReasonPrize, t.ID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE tournaments
+   SET winner_team = $1
+ WHERE id = $2`, ranking[0], t.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't finish tournament")
+	}
+	return results, nil
+}
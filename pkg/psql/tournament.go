@@ -4,114 +4,898 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
-// AddTournament adds tournament with passed name and deposit. Return id of this tournament.
-func (db *DB) AddTournament(ctx context.Context, name string, deposit uint64) (int64, error) {
-	var id int64
+// tournamentColumns lists columns of tournaments table in the order scanTournament reads them.
+const tournamentColumns = `t.id, t.name, t.deposit, t.rake_type, t.rake, t.min_players, t.max_players,
+       t.registration_opens_at, t.registration_closes_at, t.starts_at, t.format, t.rounds, t.min_rating,
+       t.max_rating, t.team_based, t.visibility, t.organizer_id, t.status, t.gross_prize, t.prize, t.winner,
+       t.winner_team, t.guaranteed_prize, t.sponsor, t.overlay, t.currency, t.created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTournament reads tournamentColumns into t followed by passed extra destinations.
+func scanTournament(row scanner, t *sts.Tournament, extra ...interface{}) error {
+	var (
+		organizer, winner, winnerTeam sql.NullInt64
+		sponsor                       sql.NullString
+	)
+	dest := []interface{}{&t.ID, &t.Name, &t.Deposit, &t.RakeType, &t.Rake, &t.MinPlayers, &t.MaxPlayers,
+		&t.RegistrationOpensAt, &t.RegistrationClosesAt, &t.StartsAt, &t.Format, &t.Rounds, &t.MinRating,
+		&t.MaxRating, &t.TeamBased, &t.Visibility, &organizer, &t.Status, &t.GrossPrize, &t.Prize, &winner,
+		&winnerTeam, &t.GuaranteedPrize, &sponsor, &t.Overlay,
+		&t.Currency, &t.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+	t.OrganizerID = organizer.Int64
+	t.Sponsor = sponsor.String
+	if t.Status == sts.StatusFinished {
+		if !winner.Valid && !winnerTeam.Valid {
+			return errors.New("no winner")
+		}
+		t.Winner = winner.Int64
+		t.WinnerTeam = winnerTeam.Int64
+	}
+	return nil
+}
+
+// lockTournament loads tournament with passed id and locks it until the end of transaction.
+// If tournament isn't found, function returns ErrNotFound.
+func lockTournament(ctx context.Context, tx *sqlx.Tx, id int64) (*sts.Tournament, error) {
+	var t sts.Tournament
+	err := scanTournament(tx.QueryRowContext(ctx, `
+SELECT `+tournamentColumns+`
+  FROM tournaments AS t
+ WHERE t.id = $1
+   FOR UPDATE`, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, sts.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't load tournament")
+	}
+	return &t, nil
+}
+
+// loadTournament locks tournament with passed id like lockTournament and loads its prize shares,
+// participants, teams and waitlist through tx, so they stay as loaded until the end of transaction.
+func loadTournament(ctx context.Context, tx *sqlx.Tx, id int64) (*sts.Tournament, error) {
+	t, err := lockTournament(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := getPayouts(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.PrizeShares = make([]uint32, 0, len(payouts))
+	for _, p := range payouts {
+		t.PrizeShares = append(t.PrizeShares, p.Share)
+	}
+	t.Users, err = participants(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Waitlist, err = waitlist(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.TeamBased {
+		teams, err := teamEntries(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		t.Teams = make([]int64, 0, len(teams))
+		for _, team := range teams {
+			t.Teams = append(t.Teams, team.TeamID)
+		}
+	}
+	return t, nil
+}
+
+// checkTournament returns ErrNotFound if tournament with passed id doesn't exist.
+func (db *DB) checkTournament(ctx context.Context, id int64) error {
+	var exists bool
 	err := db.conn.QueryRowContext(ctx, `
-INSERT INTO tournaments (name,deposit)
-	 VALUES ($1, $2)
-  RETURNING id`, name, deposit).Scan(&id)
+SELECT EXISTS(SELECT 1 FROM tournaments WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "couldn't check tournament")
+	}
+	if !exists {
+		return sts.ErrNotFound
+	}
+	return nil
+}
+
+func participants(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]int64, error) {
+	var users []int64
+	err := sqlx.SelectContext(ctx, q, &users, `
+SELECT user_id
+  FROM participants
+ WHERE tournament_id = $1`, tournamentID)
+	return users, errors.Wrap(err, "couldn't load participants")
+}
+
+// entrants returns number of entries of tournament, i.e. number of teams that have joined
+// team-based tournament or number of participants otherwise.
+func entrants(ctx context.Context, q sqlx.QueryerContext, t *sts.Tournament) (int, error) {
+	var count int
+	err := q.QueryRowxContext(ctx, `
+SELECT CASE WHEN $2 THEN COUNT(DISTINCT team_id) ELSE COUNT(*) END
+  FROM participants
+ WHERE tournament_id = $1`, t.ID, t.TeamBased).Scan(&count)
+	return count, errors.Wrap(err, "couldn't count participants")
+}
+
+// AddTournament adds tournament with passed settings in draft status. Return id of this tournament.
+// If settings are incorrect, function returns ErrInvalidPrizeShares, ErrInvalidRake,
+// ErrInvalidCapacity, ErrInvalidSchedule, ErrInvalidFormat, ErrInvalidRatingRange,
+// ErrInvalidVisibility, ErrInvalidGuarantee or ErrInvalidCurrency.
+func (db *DB) AddTournament(ctx context.Context, settings sts.TournamentSettings) (int64, error) {
+	err := settings.Validate()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	id, err := addTournament(ctx, tx, settings)
+	if err != nil {
+		return 0, err
+	}
+	return id, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// addTournament adds tournament with passed validated settings in draft status and returns its id.
+func addTournament(ctx context.Context, tx *sqlx.Tx, settings sts.TournamentSettings) (int64, error) {
+	err := checkCurrency(ctx, tx, settings.Currency)
+	if err != nil {
+		return 0, err
+	}
+	err = checkSponsor(ctx, tx, settings.Sponsor, settings.Currency)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+INSERT INTO tournaments (name, deposit, rake_type, rake, min_players, max_players,
+                         registration_opens_at, registration_closes_at, starts_at, format, rounds,
+                         min_rating, max_rating, team_based, visibility, organizer_id, guaranteed_prize,
+                         sponsor, currency)
+	 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+  RETURNING id`, settings.Name, settings.Deposit, settings.RakeType, settings.Rake, settings.MinPlayers,
+		settings.MaxPlayers, settings.RegistrationOpensAt, settings.RegistrationClosesAt, settings.StartsAt,
+		settings.Format, settings.Rounds, settings.MinRating, settings.MaxRating, settings.TeamBased,
+		settings.Visibility, nullID(settings.OrganizerID), settings.GuaranteedPrize,
+		nullAccount(settings.Sponsor), settings.Currency).Scan(&id)
+	if err != nil {
+		return 0, dbError(err, "couldn't add tournament")
+	}
+	return id, addPayouts(ctx, tx, id, settings.PrizeShares)
+}
+
+// addPayouts adds shares of prize that are paid to each place of tournament with passed id.
+func addPayouts(ctx context.Context, tx *sqlx.Tx, tournamentID int64, shares []uint32) error {
+	for i, share := range shares {
+		_, err := tx.ExecContext(ctx, `
+INSERT INTO payouts (tournament_id, place, share)
+     VALUES ($1, $2, $3)`, tournamentID, i+1, share)
+		if err != nil {
+			return dbError(err, "couldn't add payout")
+		}
+	}
+	return nil
+}
+
+// UpdateTournament applies passed changes to settings of tournament with passed id. Every setting
+// can be changed while nobody has joined tournament or its waitlist, afterwards only name can be
+// changed, otherwise function returns ErrTournamentLocked. If tournament isn't found, function
+// returns ErrNotFound. If tournament has started, function returns ErrTournamentClosed. Budget
+// of open tournament is reserved for the changed guaranteed prize, if sponsor doesn't have enough
+// points, function returns ErrInsufficientBudget. See AddTournament for errors of incorrect settings.
+func (db *DB) UpdateTournament(ctx context.Context, id int64, update sts.TournamentUpdate) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	// lock keeps players from joining until settings are replaced
+	t, err := loadTournament(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	settings := update.Apply(t.TournamentSettings)
+	err = settings.Validate()
 	if err != nil {
-		return 0, errors.Wrap(err, "couldn't add tournament")
+		return err
 	}
-	return id, nil
+	err = t.CheckUpdate(settings)
+	if err != nil {
+		return err
+	}
+	err = checkCurrency(ctx, tx, settings.Currency)
+	if err != nil {
+		return err
+	}
+	err = checkSponsor(ctx, tx, settings.Sponsor, settings.Currency)
+	if err != nil {
+		return err
+	}
+	// budget reserved by open tournament follows its guaranteed prize
+	err = releaseGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	t.TournamentSettings = settings
+	if t.Status == sts.StatusRegistration {
+		err = reserveGuarantee(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE tournaments
+   SET name = $2, deposit = $3, rake_type = $4, rake = $5, min_players = $6, max_players = $7,
+       registration_opens_at = $8, registration_closes_at = $9, starts_at = $10, format = $11,
+       rounds = $12, min_rating = $13, max_rating = $14, team_based = $15, visibility = $16,
+       organizer_id = $17, guaranteed_prize = $18, sponsor = $19, currency = $20
+ WHERE id = $1`, id, settings.Name, settings.Deposit, settings.RakeType, settings.Rake, settings.MinPlayers,
+		settings.MaxPlayers, settings.RegistrationOpensAt, settings.RegistrationClosesAt, settings.StartsAt,
+		settings.Format, settings.Rounds, settings.MinRating, settings.MaxRating, settings.TeamBased,
+		settings.Visibility, nullID(settings.OrganizerID), settings.GuaranteedPrize,
+		nullAccount(settings.Sponsor), settings.Currency)
+	if err != nil {
+		return dbError(err, "couldn't update tournament")
+	}
+	_, err = tx.ExecContext(ctx, `
+DELETE
+  FROM payouts
+ WHERE tournament_id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, "couldn't delete payouts")
+	}
+	err = addPayouts(ctx, tx, id, settings.PrizeShares)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// CloneTournament adds a draft with settings of tournament with passed id and returns id of
+// the draft. Schedule times that have passed aren't copied. If tournament isn't found,
+// function returns ErrNotFound. See AddTournament for other errors.
+func (db *DB) CloneTournament(ctx context.Context, id int64) (int64, error) {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := loadTournament(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+	settings := t.Clone(db.now())
+	err = settings.Validate()
+	if err != nil {
+		return 0, err
+	}
+	cloneID, err := addTournament(ctx, tx, settings)
+	if err != nil {
+		return 0, err
+	}
+	return cloneID, errors.Wrap(tx.Commit(), "couldn't commit transaction")
 }
 
 // GetTournament returns tournament with passed id. If tournament isn't found,
 // function returns ErrNotFound.
 func (db *DB) GetTournament(ctx context.Context, id int64) (*sts.Tournament, error) {
 	var (
-		users    sql.NullString
-		winner   sql.NullInt64
-		finished bool
-		t        sts.Tournament
+		users string
+		t     sts.Tournament
 	)
-	err := db.conn.QueryRowContext(ctx, `
-   SELECT id, name, deposit, prize, winner, finished, json_agg(user_id)
+	err := scanTournament(db.conn.QueryRowContext(ctx, `
+   SELECT `+tournamentColumns+`, COALESCE(json_agg(p.user_id) FILTER (WHERE p.user_id IS NOT NULL), '[]')
 	 FROM tournaments as t
 LEFT JOIN participants as p on t.id = p.tournament_id
 	WHERE t.id = $1
- GROUP BY t.id`, id).
-		Scan(&t.ID, &t.Name, &t.Deposit, &t.Prize, &winner, &finished, &users)
+ GROUP BY t.id`, id), &t, &users)
 	if err == sql.ErrNoRows {
 		return nil, sts.ErrNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	err = json.Unmarshal([]byte(users.String), &t.Users)
+	err = json.Unmarshal([]byte(users), &t.Users)
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't unmarshal json")
 	}
-	if finished {
-		if !winner.Valid {
-			return nil, errors.New("no winner")
+	t.Waitlist, err = waitlist(ctx, db.conn, id)
+	if err != nil {
+		return nil, err
+	}
+	entries := len(t.Users)
+	if t.TeamBased {
+		teams, err := teamEntries(ctx, db.conn, id)
+		if err != nil {
+			return nil, err
 		}
-		t.Winner = winner.Int64
+		t.Teams = make([]int64, 0, len(teams))
+		for _, team := range teams {
+			t.Teams = append(t.Teams, team.TeamID)
+		}
+		entries = len(t.Teams)
+	}
+	t.Underfilled = (t.Status == sts.StatusDraft || t.Status == sts.StatusRegistration) &&
+		!t.HasEnoughPlayers(entries)
+	t.Payouts, err = getPayouts(ctx, db.conn, id)
+	if err != nil {
+		return nil, err
+	}
+	t.PrizeShares = make([]uint32, 0, len(t.Payouts))
+	for _, p := range t.Payouts {
+		t.PrizeShares = append(t.PrizeShares, p.Share)
+	}
+	if t.Status != sts.StatusFinished {
+		t.Payouts = sts.PlannedPayouts(t.PrizePool(), t.PrizeShares)
 	}
 	return &t, nil
 }
 
+func getPayouts(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]sts.Payout, error) {
+	rows, err := q.QueryContext(ctx, `
+  SELECT place, share, amount, user_id, team_id
+    FROM payouts
+   WHERE tournament_id = $1
+ORDER BY place`, tournamentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get payouts")
+	}
+	defer rows.Close()
+	var payouts []sts.Payout
+	for rows.Next() {
+		var (
+			p      sts.Payout
+			amount sql.NullInt64
+			userID sql.NullInt64
+			teamID sql.NullInt64
+		)
+		err = rows.Scan(&p.Place, &p.Share, &amount, &userID, &teamID)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't scan payout")
+		}
+		p.Amount = uint64(amount.Int64)
+		p.UserID = userID.Int64
+		p.TeamID = teamID.Int64
+		payouts = append(payouts, p)
+	}
+	return payouts, errors.Wrap(rows.Err(), "couldn't read payouts")
+}
+
 // JoinTournament adds user with passed userID to tournament with passed tournamentID.
-// If tournament or user isn't found, function returns ErrNotFound.
-func (db *DB) JoinTournament(ctx context.Context, tournamentID, userID int64) error {
+// If tournament is full, user is put on the waitlist without charging the deposit and
+// function returns true. If tournament or user isn't found, function returns ErrNotFound.
+// If tournament isn't open for registration or current time is out of its registration
+// window, function returns ErrTournamentClosed. If user rating is out of tournament range,
+// function returns ErrRatingOutOfRange. If tournament is team-based, function returns ErrTeamTournament.
+// Users other than the organizer must pass a valid invite code to join private tournament,
+// otherwise function returns ErrInvalidInviteCode. The code is ignored for public tournaments.
+// If user has already joined tournament or its waitlist, function returns ErrAlreadyJoined.
+// Retries with the same idempotency key in ctx return the first result without joining again.
+func (db *DB) JoinTournament(ctx context.Context, tournamentID, userID int64, inviteCode string) (bool, error) {
 	tx, err := db.conn.BeginTxx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "couldn't begin transaction")
+		return false, errors.Wrap(err, "couldn't begin transaction")
 	}
 	defer tx.Rollback()
-	var (
-		finished bool
-		t        sts.Tournament
-	)
-	err = tx.QueryRowContext(ctx, `
-SELECT id, name, deposit, prize, finished
-  FROM tournaments
- WHERE id = $1`, tournamentID).
-		Scan(&t.ID, &t.Name, &t.Deposit, &t.Prize, &finished)
-	if err == sql.ErrNoRows {
-		return sts.ErrNotFound
+	response, done, err := db.useIdempotencyKey(ctx, tx, sts.IdempotentRequest("join", tournamentID, userID, inviteCode))
+	if err != nil {
+		return false, err
+	}
+	if done {
+		return response == "true", nil
+	}
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return false, err
+	}
+	if t.TeamBased {
+		return false, sts.ErrTeamTournament
+	}
+	if t.Status != sts.StatusRegistration || !t.InRegistrationWindow(db.now()) {
+		return false, sts.ErrTournamentClosed
+	}
+	if userID != t.OrganizerID {
+		err = useInviteCode(ctx, tx, t, inviteCode, db.now())
+		if err != nil {
+			return false, err
+		}
+	}
+	err = checkRating(ctx, tx, t, userID)
+	if err != nil {
+		return false, err
+	}
+	users, err := participants(ctx, tx, tournamentID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range users {
+		if id == userID {
+			return false, sts.ErrAlreadyJoined
+		}
+	}
+
+	if t.IsFull(len(users)) {
+		err = addToWaitlist(ctx, tx, tournamentID, userID)
+		if err != nil {
+			return false, err
+		}
+		err = saveIdempotentResponse(ctx, tx, "true")
+		if err != nil {
+			return false, err
+		}
+		return true, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+	}
+	err = addParticipant(ctx, tx, t, userID, db.now())
+	if err != nil {
+		return false, err
+	}
+	err = saveIdempotentResponse(ctx, tx, "false")
+	if err != nil {
+		return false, err
+	}
+	return false, errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// addParticipant charges the deposit from user with passed userID at now and adds the user to
+// locked tournament.
+func addParticipant(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, userID int64, now time.Time) error {
+	err := changeBalance(ctx, tx, userID, t.Currency, -int64(t.Deposit), sts.ReasonEntryFee, t.ID, now)
+	if err != nil {
+		return err
 	}
+	err = addEntries(ctx, tx, t, 1)
 	if err != nil {
-		return errors.Wrap(err, "couldn't load tournament")
+		return err
 	}
-	if finished {
-		return errors.New("tournament has finished")
+
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO	participants(user_id, tournament_id)
+     VALUES ($1, $2)`, userID, t.ID)
+	if duplicate(err) {
+		return sts.ErrAlreadyJoined
 	}
+	return dbError(err, "couldn't add user to tournament")
+}
 
-	update, err := tx.ExecContext(ctx, `
-UPDATE users
-   SET balance = balance - $1
- WHERE id = $2`, t.Deposit, userID)
+func waitlist(ctx context.Context, q sqlx.QueryerContext, tournamentID int64) ([]int64, error) {
+	users := []int64{}
+	err := sqlx.SelectContext(ctx, q, &users, `
+  SELECT user_id
+    FROM waitlist
+   WHERE tournament_id = $1
+ORDER BY joined_at, id`, tournamentID)
+	return users, errors.Wrap(err, "couldn't load waitlist")
+}
+
+// addToWaitlist puts user with passed userID to the end of tournament waitlist.
+// If user isn't found, function returns ErrNotFound.
+func addToWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID, userID int64) error {
+	insert, err := tx.ExecContext(ctx, `
+INSERT INTO waitlist (user_id, tournament_id)
+     SELECT id, $2
+       FROM users
+      WHERE id = $1`, userID, tournamentID)
+	if duplicate(err) {
+		return sts.ErrAlreadyJoined
+	}
 	if err != nil {
-		return errors.Wrap(err, "couldn't update user balance: %s")
+		return dbError(err, "couldn't add user to waitlist")
 	}
-	rows, err := update.RowsAffected()
+	rows, err := insert.RowsAffected()
 	if err != nil {
-		return errors.Wrap(err, "couldn't process user update")
+		return errors.Wrap(err, "couldn't get affected rows")
 	}
 	if rows == 0 {
 		return sts.ErrNotFound
 	}
+	return nil
+}
+
+// promoteWaitlisted gives a free seat in locked tournament to the first waitlisted user that
+// can pay the deposit at now. Users that can't pay it are removed from the waitlist.
+func promoteWaitlisted(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, now time.Time) error {
+	for {
+		var (
+			id      int64
+			userID  int64
+			balance uint64
+		)
+		err := tx.QueryRowContext(ctx, `
+   SELECT w.id, w.user_id,
+          COALESCE((SELECT balance FROM balances WHERE user_id = w.user_id AND currency = $2), 0)
+     FROM waitlist AS w
+    WHERE w.tournament_id = $1
+ ORDER BY w.joined_at, w.id
+    LIMIT 1
+      FOR UPDATE`, t.ID, t.Currency).Scan(&id, &userID, &balance)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "couldn't load waitlist")
+		}
+		_, err = tx.ExecContext(ctx, `
+DELETE
+  FROM waitlist
+ WHERE id = $1`, id)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove user from waitlist")
+		}
+		if balance < t.Deposit {
+			continue
+		}
+		err = addParticipant(ctx, tx, t, userID, now)
+		// balance may include credits that have just expired
+		if err != sts.ErrInsufficientFunds {
+			return err
+		}
+	}
+}
+
+// clearWaitlist removes every user from tournament waitlist.
+func clearWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID int64) error {
+	_, err := tx.ExecContext(ctx, `
+DELETE
+  FROM waitlist
+ WHERE tournament_id = $1`, tournamentID)
+	return errors.Wrap(err, "couldn't clear waitlist")
+}
+
+// addEntries adds passed number of deposits to tournament prize and takes rake from them.
+// Negative number of entries takes deposits back from tournament prize and returns rake.
+func addEntries(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, entries int64) error {
+	deposits := entries * int64(t.Deposit)
+	rake := entries * int64(t.RakeAmount())
+	_, err := tx.ExecContext(ctx, `
+UPDATE tournaments
+   SET gross_prize = gross_prize + $1, prize = prize + $2
+ WHERE id = $3`, deposits, deposits-rake, t.ID)
+	if err != nil {
+		return dbError(err, "couldn't update tournament prize")
+	}
+	return changeHouseBalance(ctx, tx, sts.RakeAccount, t.Currency, rake)
+}
+
+// LeaveTournament removes user with passed userID from tournament with passed tournamentID
+// and refunds the deposit. The freed seat is taken by the first waitlisted user that can
+// pay the deposit. Waitlisted user is just removed from the waitlist. If tournament isn't
+// found, function returns ErrNotFound. If user doesn't participate in tournament, function
+// returns ErrNotParticipant. If tournament has already started, function returns
+// ErrTournamentClosed. If tournament is team-based, function returns ErrTeamTournament.
+func (db *DB) LeaveTournament(ctx context.Context, tournamentID, userID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.TeamBased {
+		return sts.ErrTeamTournament
+	}
+	if t.Status != sts.StatusDraft && t.Status != sts.StatusRegistration {
+		return sts.ErrTournamentClosed
+	}
+
+	delete, err := tx.ExecContext(ctx, `
+DELETE
+  FROM participants
+ WHERE tournament_id = $1 AND user_id = $2`, tournamentID, userID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't remove user from tournament")
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 0 {
+		err = leaveWaitlist(ctx, tx, tournamentID, userID)
+		if err != nil {
+			return err
+		}
+		return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+	}
+
+	now := db.now()
+	err = changeBalance(ctx, tx, userID, t.Currency, int64(t.Deposit), sts.ReasonRefund, tournamentID, now)
+	if err != nil {
+		return err
+	}
+	err = addEntries(ctx, tx, t, -1)
+	if err != nil {
+		return err
+	}
+	err = promoteWaitlisted(ctx, tx, t, now)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// leaveWaitlist removes user with passed userID from tournament waitlist.
+// If user isn't waitlisted, function returns ErrNotParticipant.
+func leaveWaitlist(ctx context.Context, tx *sqlx.Tx, tournamentID, userID int64) error {
+	delete, err := tx.ExecContext(ctx, `
+DELETE
+  FROM waitlist
+ WHERE tournament_id = $1 AND user_id = $2`, tournamentID, userID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't remove user from waitlist")
+	}
+	rows, err := delete.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get affected rows")
+	}
+	if rows == 0 {
+		return sts.ErrNotParticipant
+	}
+	return nil
+}
+
+// OpenRegistration moves tournament from draft to registration status and reserves its guaranteed
+// prize from the budget of its sponsor. If tournament isn't found, function returns ErrNotFound.
+// If tournament isn't a draft, function returns TransitionError. If sponsor doesn't have enough
+// points, function returns ErrInsufficientBudget.
+func (db *DB) OpenRegistration(ctx context.Context, tournamentID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = transition(ctx, tx, t, sts.StatusRegistration)
+	if err != nil {
+		return err
+	}
+	err = reserveGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
+// StartTournament closes registration, clears the waitlist and moves tournament to running
+// status. If tournament isn't found, function returns ErrNotFound. If tournament isn't
+// open for registration, function returns TransitionError. If tournament doesn't have
+// minimum number of players, function returns ErrNotEnoughPlayers.
+func (db *DB) StartTournament(ctx context.Context, tournamentID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = transition(ctx, tx, t, sts.StatusRunning)
+	if err != nil {
+		return err
+	}
+	count, err := entrants(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	if !t.HasEnoughPlayers(count) {
+		return sts.ErrNotEnoughPlayers
+	}
+	err = clearWaitlist(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
 
+// CancelTournament refunds deposits to every participant, returns the reserved guaranteed prize
+// to the sponsor, resets tournament prize, clears the waitlist and moves tournament to cancelled
+// status. If tournament isn't found, function returns ErrNotFound. If tournament has already
+// finished or been cancelled, function returns TransitionError.
+func (db *DB) CancelTournament(ctx context.Context, tournamentID int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	t.Users, err = participants(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	entries := len(t.Users)
+	var teams []sts.TeamEntry
+	if t.TeamBased {
+		teams, err = teamEntries(ctx, tx, tournamentID)
+		if err != nil {
+			return err
+		}
+		entries = len(teams)
+	}
+	err = releaseGuarantee(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	refunds, err := t.Cancel(teams)
+	if err != nil {
+		return err
+	}
+	for _, r := range refunds {
+		err = changeBalance(ctx, tx, r.UserID, t.Currency, int64(r.Amount), sts.ReasonRefund, t.ID, db.now())
+		if err != nil {
+			return err
+		}
+	}
 	_, err = tx.ExecContext(ctx, `
 UPDATE tournaments
-   SET prize = prize + deposit
- WHERE id = $1`, tournamentID)
+   SET status = $1, gross_prize = $2, prize = $3
+ WHERE id = $4`, t.Status, t.GrossPrize, t.Prize, t.ID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't cancel tournament")
+	}
+	err = changeHouseBalance(ctx, tx, sts.RakeAccount, t.Currency, -int64(entries)*int64(t.RakeAmount()))
+	if err != nil {
+		return err
+	}
+	err = clearWaitlist(ctx, tx, tournamentID)
 	if err != nil {
-		return errors.Wrap(err, "couldn't increase tournament prize")
+		return err
 	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
 
+// transition moves locked tournament to passed status if it's allowed.
+func transition(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, status sts.Status) error {
+	err := sts.CheckTransition(t.Status, status)
+	if err != nil {
+		return err
+	}
 	_, err = tx.ExecContext(ctx, `
-INSERT INTO	participants(user_id, tournament_id)
-     VALUES ($1, $2)`, userID, tournamentID)
+UPDATE tournaments
+   SET status = $1
+ WHERE id = $2`, status, t.ID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't update tournament status")
+	}
+	t.Status = status
+	return nil
+}
+
+// FinishTournament splits tournament prize between users according to passed ranking
+// and marks tournament as finished. Ranking must contain every participant exactly once,
+// starting from the winner. If tournament isn't found, function returns ErrNotFound.
+// If ranked user doesn't participate in tournament, function returns ErrNotParticipant.
+// Ratings of participants of a pool tournament are updated as if every user has won against
+// every user ranked below. Ranking of team-based tournament contains teams instead of users,
+// prizes of teams are split between their members by their shares and ratings aren't updated.
+// When prize falls short of the guaranteed one, the overlay is paid out of the budget that has
+// been reserved from the sponsor, the rest of the budget is returned.
+func (db *DB) FinishTournament(ctx context.Context, tournamentID int64, ranking []int64) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	t, err := lockTournament(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	err = finish(ctx, tx, t, ranking, db.now())
 	if err != nil {
-		return errors.Wrap(err, "couldn't add user to tournament")
+		return err
 	}
 	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
 }
+
+// finish pays out prize of locked tournament together with the overlay of its guaranteed prize
+// according to passed ranking, marks it as finished and adds its results to leaderboards of the
+// period that contains now.
+func finish(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64, now time.Time) error {
+	tournamentID := t.ID
+	err := transition(ctx, tx, t, sts.StatusFinished)
+	if err != nil {
+		return err
+	}
+	err = payOverlay(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+
+	var shares []uint32
+	err = tx.SelectContext(ctx, &shares, `
+  SELECT share
+    FROM payouts
+   WHERE tournament_id = $1
+ORDER BY place`, tournamentID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't load prize shares")
+	}
+	var results []sts.LeaderboardEntry
+	if t.TeamBased {
+		results, err = finishTeams(ctx, tx, t, ranking, shares, now)
+	} else {
+		results, err = finishUsers(ctx, tx, t, ranking, shares, now)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE payouts
+   SET amount = 0
+ WHERE tournament_id = $1 AND amount IS NULL`, tournamentID)
+	if err != nil {
+		return errors.Wrap(err, "couldn't save unpaid places")
+	}
+	return addLeaderboardStats(ctx, tx, results, now)
+}
+
+// finishUsers pays out prize of locked tournament according to passed ranking of users and
+// returns their results.
+func finishUsers(ctx context.Context, tx *sqlx.Tx, t *sts.Tournament, ranking []int64,
+	shares []uint32, now time.Time) ([]sts.LeaderboardEntry, error) {
+	users, err := participants(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = sts.ValidateRanking(ranking, users)
+	if err != nil {
+		return nil, err
+	}
+	if t.Format == sts.FormatPool {
+		err = rateGames(ctx, tx, t.ID, sts.RankingGames(ranking))
+		if err != nil {
+			return nil, err
+		}
+	}
+	payouts := sts.RankingPayouts(t.PrizePool(), shares, ranking)
+	for _, p := range payouts {
+		if p.Amount > 0 {
+			err = changeBalance(ctx, tx, p.UserID, t.Currency, int64(p.Amount), sts.ReasonPrize, t.ID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `
+UPDATE payouts
+   SET amount = $1, user_id = $2
+ WHERE tournament_id = $3 AND place = $4`, p.Amount, p.UserID, t.ID, p.Place)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't save payout")
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE tournaments
+   SET winner = $1
+ WHERE id = $2`, ranking[0], t.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't finish tournament")
+	}
+	return sts.RankingResults(ranking, payouts, t.Deposit), nil
+}
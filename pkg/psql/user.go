@@ -3,21 +3,30 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 )
 
-// AddUser adds user with passed name to db. It returns id of this user.
+// AddUser adds user with passed name to db. It returns id of this user. If name isn't a valid
+// handle, function returns ErrInvalidHandle. If name is taken, function returns ErrHandleTaken.
 func (db *DB) AddUser(ctx context.Context, name string) (int64, error) {
+	err := sts.ValidateHandle(name)
+	if err != nil {
+		return 0, err
+	}
 	var id int64
-	err := db.conn.QueryRowContext(ctx, `
+	err = db.conn.QueryRowContext(ctx, `
 INSERT INTO users (name) 
      VALUES ($1)
   RETURNING id`, name).Scan(&id)
+	if duplicate(err) {
+		return 0, sts.ErrHandleTaken
+	}
 	if err != nil {
-		return 0, errors.Wrap(err, "couldn't add user")
+		return 0, dbError(err, "couldn't add user")
 	}
 	return id, nil
 }
@@ -25,19 +34,70 @@ INSERT INTO users (name)
 // GetUser returns user with passed id. If user isn't found, function returns ErrNotFound.
 func (db *DB) GetUser(ctx context.Context, id int64) (*sts.User, error) {
 	var user sts.User
-	err := db.conn.GetContext(ctx, &user, `
-SELECT id, name, balance 
-  FROM users 
- WHERE id = $1`, id)
+	err := db.conn.QueryRowContext(ctx, `
+SELECT id, name, display_name, avatar_url, country, bio, rating, rating_deviation, games
+  FROM users
+ WHERE id = $1`, id).Scan(&user.ID, &user.Name, &user.DisplayName, &user.AvatarURL, &user.Country, &user.Bio,
+		&user.Rating, &user.RatingDeviation, &user.Games)
 	if err == sql.ErrNoRows {
 		return nil, sts.ErrNotFound
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't get user")
 	}
+	user.Balances, err = balances(ctx, db.conn, id)
+	if err != nil {
+		return nil, err
+	}
+	user.Expirations, err = expirations(ctx, db.conn, id, db.now())
+	if err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
+// UpdateUser changes profile of user with passed id. If user isn't found, function returns
+// ErrNotFound. If update is incorrect, function returns ErrInvalidHandle or ErrInvalidProfile.
+// If new name is taken, function returns ErrHandleTaken.
+func (db *DB) UpdateUser(ctx context.Context, id int64, update sts.UserUpdate) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "couldn't begin transaction")
+	}
+	defer tx.Rollback()
+	var name string
+	err = tx.GetContext(ctx, &name, `
+SELECT name
+  FROM users
+ WHERE id = $1
+   FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return sts.ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "couldn't get user")
+	}
+	err = update.Validate(name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE users
+   SET name = COALESCE($2, name),
+       display_name = COALESCE($3, display_name),
+       avatar_url = COALESCE($4, avatar_url),
+       country = COALESCE($5, country),
+       bio = COALESCE($6, bio)
+ WHERE id = $1`, id, update.Name, update.DisplayName, update.AvatarURL, update.Country, update.Bio)
+	if duplicate(err) {
+		return sts.ErrHandleTaken
+	}
+	if err != nil {
+		return dbError(err, "couldn't update user")
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
+}
+
 // DeleteUser deletes user with passed id. If user isn't found, function returns ErrNotFound.
 func (db *DB) DeleteUser(ctx context.Context, id int64) error {
 	delete, err := db.conn.ExecContext(ctx, `
@@ -57,21 +117,54 @@ WHERE id = $1`, id)
 	return nil
 }
 
-// AddPoints adds points to user with passed id. If user isn't found, function returns ErrNotFound.
-func (db *DB) AddPoints(ctx context.Context, id, points int64) error {
-	update, err := db.conn.ExecContext(ctx, `
-UPDATE users 
-   SET balance = balance + $1 
- WHERE id = $2`, points, id)
+// Credit adds amount of passed currency to balance of user with passed id. Credited points expire
+// after expiresIn unless it's zero. If user isn't found, function returns ErrNotFound.
+// If currency doesn't exist, function returns ErrInvalidCurrency. Retries with the same
+// idempotency key in ctx succeed without crediting again.
+func (db *DB) Credit(ctx context.Context, id int64, currency sts.Currency, amount uint64,
+	expiresIn time.Duration) error {
+	expiresAt, err := sts.ExpiresAt(db.now(), expiresIn)
+	if err != nil {
+		return err
+	}
+	request := sts.IdempotentRequest("fund", id, currency, amount, expiresIn)
+	return db.addPoints(ctx, id, currency, int64(amount), sts.ReasonFund, expiresAt, request)
+}
+
+// Debit takes amount of passed currency from balance of user with passed id. Points that expire
+// the soonest are taken first. See Credit for errors.
+func (db *DB) Debit(ctx context.Context, id int64, currency sts.Currency, amount uint64) error {
+	request := sts.IdempotentRequest("take", id, currency, amount)
+	return db.addPoints(ctx, id, currency, -int64(amount), sts.ReasonTake, nil, request)
+}
+
+func (db *DB) addPoints(ctx context.Context, id int64, currency sts.Currency, amount int64, reason sts.Reason,
+	expiresAt *time.Time, request string) error {
+	tx, err := db.conn.BeginTxx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "couldn't update balance")
+		return errors.Wrap(err, "couldn't begin transaction")
 	}
-	rows, err := update.RowsAffected()
+	defer tx.Rollback()
+	_, done, err := db.useIdempotencyKey(ctx, tx, request)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sts.ErrNotFound
+	if done {
+		return nil
 	}
-	return nil
+	err = checkCurrency(ctx, tx, currency)
+	if err != nil {
+		return err
+	}
+	err = changeBalance(ctx, tx, id, currency, amount, reason, 0, db.now())
+	if err != nil {
+		return err
+	}
+	if expiresAt != nil && amount > 0 {
+		err = addLot(ctx, tx, id, currency, uint64(amount), *expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(tx.Commit(), "couldn't commit transaction")
 }
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+// Store provides tournaments, expiring points and idempotency keys that the scheduler works with.
+type Store interface {
+	// DueTournaments returns tournaments whose status has to be changed at passed time
+	// according to their schedule.
+	DueTournaments(ctx context.Context, now time.Time) (*sts.DueTournaments, error)
+
+	// TryLock tries to take the lock that is shared by every scheduler working with the store.
+	// If the lock is taken, function returns function that releases it. Otherwise it returns nil.
+	TryLock(ctx context.Context) (func() error, error)
+
+	OpenRegistration(ctx context.Context, tournamentID int64) error
+	StartTournament(ctx context.Context, tournamentID int64) error
+	CancelTournament(ctx context.Context, tournamentID int64) error
+
+	// ExpirePoints takes points that have expired by passed time from their owners
+	// and records the expiry in the ledger.
+	ExpirePoints(ctx context.Context, now time.Time) error
+
+	// DeleteExpiredIdempotencyKeys deletes idempotency keys that have expired by passed time.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error
+}
+
+// Scheduler periodically opens registration, starts and cancels tournaments according
+// to their schedule, expires credited points and deletes expired idempotency keys.
+type Scheduler struct {
+	store    Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New constructs a Scheduler that checks tournaments in store every interval.
+func New(store Store, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run checks tournaments every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		err := s.Tick(ctx)
+		if err != nil {
+			log.Printf("scheduler: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick changes status of every due tournament once, expires points and deletes expired idempotency keys.
+// It does nothing if another scheduler holds the lock. Errors of single tournaments are logged and
+// don't stop the tick.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	unlock, err := s.store.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if unlock == nil {
+		return nil
+	}
+	defer func() {
+		err := unlock()
+		if err != nil {
+			log.Printf("scheduler: %s", err)
+		}
+	}()
+
+	now := s.now()
+	due, err := s.store.DueTournaments(ctx, now)
+	if err != nil {
+		return err
+	}
+	s.apply(ctx, "open registration of", due.Open, s.store.OpenRegistration)
+	s.apply(ctx, "start", due.Start, s.start)
+	s.apply(ctx, "cancel", due.Cancel, s.store.CancelTournament)
+	err = s.store.ExpirePoints(ctx, now)
+	if err != nil {
+		return err
+	}
+	return s.store.DeleteExpiredIdempotencyKeys(ctx, now)
+}
+
+// start starts tournament with passed id. Tournament that turns out not to have enough players
+// is cancelled, so it doesn't stay due on every tick.
+func (s *Scheduler) start(ctx context.Context, tournamentID int64) error {
+	err := s.store.StartTournament(ctx, tournamentID)
+	if err == sts.ErrNotEnoughPlayers {
+		return s.store.CancelTournament(ctx, tournamentID)
+	}
+	return err
+}
+
+func (s *Scheduler) apply(ctx context.Context, action string, ids []int64,
+	change func(ctx context.Context, tournamentID int64) error) {
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		err := change(ctx, id)
+		if err != nil {
+			log.Printf("scheduler: couldn't %s tournament [%d]: %s", action, id, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+type store struct {
+	locked  bool
+	now     time.Time
+	due     sts.DueTournaments
+	changes []string
+	expired time.Time
+	cleaned time.Time
+	// underfilled holds tournaments that don't have enough players to start.
+	underfilled map[int64]bool
+}
+
+func (s *store) DueTournaments(ctx context.Context, now time.Time) (*sts.DueTournaments, error) {
+	s.now = now
+	return &s.due, nil
+}
+
+func (s *store) TryLock(ctx context.Context) (func() error, error) {
+	if s.locked {
+		return nil, nil
+	}
+	s.locked = true
+	return func() error {
+		s.locked = false
+		return nil
+	}, nil
+}
+
+func (s *store) change(action string, id int64) error {
+	s.changes = append(s.changes, fmt.Sprintf("%s %d", action, id))
+	if id == 3 {
+		return errors.New("tournament is broken")
+	}
+	return nil
+}
+
+func (s *store) OpenRegistration(ctx context.Context, id int64) error {
+	return s.change("open", id)
+}
+
+func (s *store) StartTournament(ctx context.Context, id int64) error {
+	err := s.change("start", id)
+	if err != nil {
+		return err
+	}
+	if s.underfilled[id] {
+		return sts.ErrNotEnoughPlayers
+	}
+	return nil
+}
+
+func (s *store) CancelTournament(ctx context.Context, id int64) error {
+	return s.change("cancel", id)
+}
+
+func (s *store) ExpirePoints(ctx context.Context, now time.Time) error {
+	s.expired = now
+	return nil
+}
+
+func (s *store) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	s.cleaned = now
+	return nil
+}
+
+func TestTick(t *testing.T) {
+	now := time.Date(2019, 9, 16, 12, 0, 0, 0, time.UTC)
+	st := &store{
+		due: sts.DueTournaments{
+			Open:   []int64{1},
+			Start:  []int64{3, 2},
+			Cancel: []int64{4},
+		},
+	}
+	s := New(st, time.Minute)
+	s.now = func() time.Time {
+		return now
+	}
+	err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !st.now.Equal(now) {
+		t.Fatalf("expected due tournaments at %s, got %s", now, st.now)
+	}
+	if !st.expired.Equal(now) {
+		t.Fatalf("expected points to expire at %s, got %s", now, st.expired)
+	}
+	if !st.cleaned.Equal(now) {
+		t.Fatalf("expected idempotency keys to expire at %s, got %s", now, st.cleaned)
+	}
+	expected := []string{"open 1", "start 3", "start 2", "cancel 4"}
+	if !reflect.DeepEqual(st.changes, expected) {
+		t.Fatalf("expected %v, got %v", expected, st.changes)
+	}
+	if st.locked {
+		t.Fatalf("expected lock to be released")
+	}
+}
+
+func TestTickUnderfilledTeamTournament(t *testing.T) {
+	// team tournament 5 has enough members but too few teams, so it can't start
+	st := &store{
+		due: sts.DueTournaments{
+			Start: []int64{5},
+		},
+		underfilled: map[int64]bool{5: true},
+	}
+	err := New(st, time.Minute).Tick(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"start 5", "cancel 5"}
+	if !reflect.DeepEqual(st.changes, expected) {
+		t.Fatalf("expected %v, got %v", expected, st.changes)
+	}
+}
+
+func TestTickLocked(t *testing.T) {
+	st := &store{
+		locked: true,
+		due: sts.DueTournaments{
+			Open: []int64{1},
+		},
+	}
+	err := New(st, time.Minute).Tick(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(st.changes) != 0 {
+		t.Fatalf("expected no changes while another scheduler holds the lock, got %v", st.changes)
+	}
+	if !st.expired.IsZero() {
+		t.Fatalf("expected no expiry while another scheduler holds the lock")
+	}
+}
@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+func (r *Resolver) Bracket(ctx context.Context, args tournamentArgs) ([]*MatchResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	bracket, err := r.s.GetBracket(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get bracket of tournament [%d]", id)
+	}
+	matches := make([]*MatchResolver, 0, len(bracket))
+	for _, m := range bracket {
+		matches = append(matches, &MatchResolver{
+			match: m,
+		})
+	}
+	return matches, nil
+}
+
+type generateBracketArgs struct {
+	ID      graphql.ID
+	Seeding *[]graphql.ID
+}
+
+func (r *Resolver) GenerateBracket(ctx context.Context, args generateBracketArgs) ([]*MatchResolver, error) {
+	tID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
+	}
+	var seeding []int64
+	if args.Seeding != nil {
+		for _, id := range *args.Seeding {
+			userID, err := decodeID(id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "couldn't decode user id [%s]", id)
+			}
+			seeding = append(seeding, userID)
+		}
+	}
+	err = r.s.GenerateBracket(ctx, tID, seeding)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't generate bracket of tournament [%d]", tID)
+	}
+	return r.Bracket(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+}
+
+type reportMatchArgs struct {
+	ID       graphql.ID
+	Round    int32
+	Position int32
+	Winner   graphql.ID
+}
+
+func (r *Resolver) ReportMatch(ctx context.Context, args reportMatchArgs) ([]*MatchResolver, error) {
+	tID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
+	}
+	winnerID, err := decodeID(args.Winner)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.Winner)
+	}
+	if args.Round < 1 || args.Position < 1 {
+		return nil, errors.Errorf("invalid match: round %d, position %d", args.Round, args.Position)
+	}
+	err = r.s.ReportMatch(ctx, tID, uint32(args.Round), uint32(args.Position), winnerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't report match of tournament [%d]", tID)
+	}
+	return r.Bracket(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+}
+
+type MatchResolver struct {
+	match sts.Match
+}
+
+func (mr *MatchResolver) Round() int32 {
+	return int32(mr.match.Round)
+}
+
+func (mr *MatchResolver) Position() int32 {
+	return int32(mr.match.Position)
+}
+
+func (mr *MatchResolver) User1() *graphql.ID {
+	return optionalID(mr.match.User1)
+}
+
+func (mr *MatchResolver) User2() *graphql.ID {
+	return optionalID(mr.match.User2)
+}
+
+func (mr *MatchResolver) Winner() *graphql.ID {
+	return optionalID(mr.match.Winner)
+}
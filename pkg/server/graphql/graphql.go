@@ -1,12 +1,14 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
-	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 	"github.com/pkg/errors"
 )
@@ -36,28 +38,51 @@ func NewResolver(db sts.Service, userSchemeFile, tournamentSchemeFile string) (*
 		return nil, errors.Wrap(err, "couldn't parse user schema")
 	}
 
-	t := func(w http.ResponseWriter, req *http.Request) {
-		tHandler := relay.Handler{
-			Schema: tSchema,
-		}
-		tHandler.ServeHTTP(w, req)
-	}
-	u := func(w http.ResponseWriter, req *http.Request) {
-		uHandler := relay.Handler{
-			Schema: uSchema,
-		}
-		uHandler.ServeHTTP(w, req)
-	}
-
 	mux := http.NewServeMux()
-	mux.HandleFunc("/tournament", t)
-	mux.HandleFunc("/user", u)
+	mux.Handle("/tournament", handler{schema: tSchema})
+	mux.Handle("/user", handler{schema: uSchema})
 	resolver.Handler = mux
 	resolver.s = db
 	return &resolver, nil
 
 }
 
+// handler serves queries to schema like relay.Handler does. Errors returned by resolvers get
+// a stable code of the service in their extensions.
+type handler struct {
+	schema *graphql.Schema
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := h.schema.Exec(req.Context(), params.Query, params.OperationName, params.Variables)
+	for _, queryErr := range response.Errors {
+		if queryErr.ResolverError == nil {
+			continue
+		}
+		if queryErr.Extensions == nil {
+			queryErr.Extensions = make(map[string]interface{})
+		}
+		queryErr.Extensions["code"] = sts.ErrorCode(queryErr.ResolverError)
+	}
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseJSON)
+}
+
 func decodeID(id graphql.ID) (int64, error) {
 	intID, err := strconv.ParseInt(string(id), 10, 64)
 	if err != nil {
@@ -72,3 +97,34 @@ func decodeID(id graphql.ID) (int64, error) {
 func encodeID(id int64) graphql.ID {
 	return graphql.ID(strconv.FormatInt(id, 10))
 }
+
+// optionalID encodes id or returns nil for zero id.
+func optionalID(id int64) *graphql.ID {
+	if id == 0 {
+		return nil
+	}
+	encoded := encodeID(id)
+	return &encoded
+}
+
+func toGraphQLTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+func fromGraphQLTime(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
+
+// idempotent returns a copy of ctx that carries passed idempotency key, if there's one.
+func idempotent(ctx context.Context, key *string) context.Context {
+	if key == nil {
+		return ctx
+	}
+	return sts.WithIdempotencyKey(ctx, *key)
+}
@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/illfate/social-tournaments-service/internal/mockdb"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func newTestServer(t *testing.T, db *mockdb.Connector) *httptest.Server {
+	r, err := NewResolver(db, "../../../user.graphql", "../../../tournament.graphql")
+	if err != nil {
+		t.Fatalf("couldn't create resolver: %v", err)
+	}
+	return httptest.NewServer(r)
+}
+
+func TestCancelTournament(t *testing.T) {
+	tt := []struct {
+		name     string
+		id       string
+		response string
+	}{
+		{
+			name:     "correct test",
+			id:       "1",
+			response: `{"data":{"cancelTournament":{"status":"CANCELLED","prize":0}}}`,
+		},
+		{
+			name: "illegal transition",
+			id:   "2",
+			response: `{"errors":[{"message":"couldn't change status of tournament [2]: tournament can't be ` +
+				`moved from finished to cancelled","path":["cancelTournament"],` +
+				`"extensions":{"code":"invalid_transition"}}],"data":{"cancelTournament":null}}`,
+		},
+		{
+			name: "uncreated tournament",
+			id:   "100",
+			response: `{"errors":[{"message":"couldn't change status of tournament [100]: not found",` +
+				`"path":["cancelTournament"],"extensions":{"code":"not_found"}}],"data":{"cancelTournament":null}}`,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("CancelTournament", int64(1)).Return(nil)
+	db.On("GetTournament", int64(1)).Return(&sts.Tournament{
+		ID:     1,
+		Status: sts.StatusCancelled,
+	}, nil)
+	db.On("CancelTournament", int64(2)).Return(&sts.TransitionError{
+		From: sts.StatusFinished,
+		To:   sts.StatusCancelled,
+	})
+	db.On("CancelTournament", int64(100)).Return(sts.ErrNotFound)
+	server := newTestServer(t, db)
+	defer server.Close()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			query := fmt.Sprintf(`{"query":"mutation { cancelTournament(id: \"%s\") { status prize } }"}`, tc.id)
+			resp, err := http.Post(server.URL+"/tournament", "application/json", strings.NewReader(query))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %v; got %v", http.StatusOK, resp.StatusCode)
+			}
+			if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+				t.Fatalf("expected %s, got %s", tc.response, respBody)
+			}
+		})
+	}
+}
+
+func TestUpdateTournament(t *testing.T) {
+	tt := []struct {
+		name     string
+		args     string
+		response string
+	}{
+		{
+			name:     "renamed tournament",
+			args:     `id: \"1\", name: \"holdem\"`,
+			response: `{"data":{"updateTournament":{"name":"holdem","deposit":100}}}`,
+		},
+		{
+			name: "joined tournament",
+			args: `id: \"1\", deposit: 200`,
+			response: `{"errors":[{"message":"couldn't update tournament [1]: only name of tournament can be ` +
+				`changed after players have joined","path":["updateTournament"],` +
+				`"extensions":{"code":"tournament_locked"}}],"data":{"updateTournament":null}}`,
+		},
+	}
+	name := "holdem"
+	deposit := uint64(200)
+	db := new(mockdb.Connector)
+	db.On("UpdateTournament", int64(1), sts.TournamentUpdate{Name: &name}).Return(nil)
+	db.On("UpdateTournament", int64(1), sts.TournamentUpdate{Deposit: &deposit}).Return(sts.ErrTournamentLocked)
+	db.On("GetTournament", int64(1)).Return(&sts.Tournament{
+		ID: 1,
+		TournamentSettings: sts.TournamentSettings{
+			Name:    "holdem",
+			Deposit: 100,
+		},
+		Status: sts.StatusRegistration,
+	}, nil)
+	server := newTestServer(t, db)
+	defer server.Close()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			query := fmt.Sprintf(`{"query":"mutation { updateTournament(%s) { name deposit } }"}`, tc.args)
+			resp, err := http.Post(server.URL+"/tournament", "application/json", strings.NewReader(query))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %v; got %v", http.StatusOK, resp.StatusCode)
+			}
+			if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+				t.Fatalf("expected %s, got %s", tc.response, respBody)
+			}
+		})
+	}
+}
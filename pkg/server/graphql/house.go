@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+type houseAccountArgs struct {
+	Name     string
+	Currency string
+}
+
+func (r *Resolver) HouseAccount(ctx context.Context, args houseAccountArgs) (*HouseAccountResolver, error) {
+	account, err := r.s.GetHouseAccount(ctx, args.Name, sts.Currency(args.Currency))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get house account [%s]", args.Name)
+	}
+	return &HouseAccountResolver{
+		account: *account,
+	}, nil
+}
+
+type fundHouseAccountArgs struct {
+	Name     string
+	Points   int32
+	Currency string
+}
+
+func (r *Resolver) FundHouseAccount(ctx context.Context, args fundHouseAccountArgs) (*HouseAccountResolver, error) {
+	if args.Points < 0 {
+		return nil, errors.Errorf("invalid points: %d", args.Points)
+	}
+	err := r.s.FundHouseAccount(ctx, args.Name, sts.Currency(args.Currency), uint64(args.Points))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't fund house account [%s]", args.Name)
+	}
+	return r.HouseAccount(ctx, houseAccountArgs{
+		Name:     args.Name,
+		Currency: args.Currency,
+	})
+}
+
+type HouseAccountResolver struct {
+	account sts.HouseAccount
+}
+
+func (hr *HouseAccountResolver) Name() string {
+	return hr.account.Name
+}
+
+func (hr *HouseAccountResolver) Currency() string {
+	return string(hr.account.Currency)
+}
+
+func (hr *HouseAccountResolver) Balance() int32 {
+	return int32(hr.account.Balance)
+}
@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+type inviteCodesArgs struct {
+	ID          graphql.ID
+	OrganizerID graphql.ID
+}
+
+// decodeOrganizerIDs decodes ids of tournament and its organizer.
+func decodeOrganizerIDs(id, organizerID graphql.ID) (int64, int64, error) {
+	tID, err := decodeID(id)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "couldn't decode tournament id [%s]", id)
+	}
+	oID, err := decodeID(organizerID)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "couldn't decode organizer id [%s]", organizerID)
+	}
+	return tID, oID, nil
+}
+
+func (r *Resolver) InviteCodes(ctx context.Context, args inviteCodesArgs) ([]*InviteCodeResolver, error) {
+	tID, organizerID, err := decodeOrganizerIDs(args.ID, args.OrganizerID)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := r.s.GetInviteCodes(ctx, tID, organizerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get invite codes of tournament [%d]", tID)
+	}
+	result := make([]*InviteCodeResolver, 0, len(codes))
+	for _, c := range codes {
+		result = append(result, &InviteCodeResolver{
+			code: c,
+		})
+	}
+	return result, nil
+}
+
+type createInviteCodeArgs struct {
+	ID          graphql.ID
+	OrganizerID graphql.ID
+	ExpiresAt   *graphql.Time
+	MaxUses     *int32
+}
+
+func (r *Resolver) CreateInviteCode(ctx context.Context, args createInviteCodeArgs) (*InviteCodeResolver, error) {
+	tID, organizerID, err := decodeOrganizerIDs(args.ID, args.OrganizerID)
+	if err != nil {
+		return nil, err
+	}
+	settings := sts.InviteSettings{
+		ExpiresAt: fromGraphQLTime(args.ExpiresAt),
+	}
+	if args.MaxUses != nil {
+		if *args.MaxUses < 0 {
+			return nil, errors.Errorf("invalid max uses: %d", *args.MaxUses)
+		}
+		settings.MaxUses = uint32(*args.MaxUses)
+	}
+	invite, err := r.s.CreateInviteCode(ctx, tID, organizerID, settings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't create invite code of tournament [%d]", tID)
+	}
+	return &InviteCodeResolver{
+		code: *invite,
+	}, nil
+}
+
+type inviteCodeArgs struct {
+	ID          graphql.ID
+	OrganizerID graphql.ID
+	Code        string
+}
+
+func (r *Resolver) RevokeInviteCode(ctx context.Context, args inviteCodeArgs) ([]*InviteCodeResolver, error) {
+	tID, organizerID, err := decodeOrganizerIDs(args.ID, args.OrganizerID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.s.RevokeInviteCode(ctx, tID, organizerID, args.Code)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't revoke invite code of tournament [%d]", tID)
+	}
+	return r.InviteCodes(ctx, inviteCodesArgs{
+		ID:          args.ID,
+		OrganizerID: args.OrganizerID,
+	})
+}
+
+func (r *Resolver) RegenerateInviteCode(ctx context.Context, args inviteCodeArgs) (*InviteCodeResolver, error) {
+	tID, organizerID, err := decodeOrganizerIDs(args.ID, args.OrganizerID)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := r.s.RegenerateInviteCode(ctx, tID, organizerID, args.Code)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't regenerate invite code of tournament [%d]", tID)
+	}
+	return &InviteCodeResolver{
+		code: *invite,
+	}, nil
+}
+
+type InviteCodeResolver struct {
+	code sts.InviteCode
+}
+
+func (ir *InviteCodeResolver) Code() string {
+	return ir.code.Code
+}
+
+func (ir *InviteCodeResolver) ExpiresAt() *graphql.Time {
+	return toGraphQLTime(ir.code.ExpiresAt)
+}
+
+func (ir *InviteCodeResolver) MaxUses() int32 {
+	return int32(ir.code.MaxUses)
+}
+
+func (ir *InviteCodeResolver) Uses() int32 {
+	return int32(ir.code.Uses)
+}
+
+func (ir *InviteCodeResolver) Revoked() bool {
+	return ir.code.Revoked
+}
+
+func (ir *InviteCodeResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: ir.code.CreatedAt}
+}
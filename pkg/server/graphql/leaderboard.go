@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+type leaderboardArgs struct {
+	Period string
+	Order  string
+	Offset int32
+	Limit  int32
+	UserID *graphql.ID
+}
+
+func (r *Resolver) Leaderboard(ctx context.Context, args leaderboardArgs) (*LeaderboardResolver, error) {
+	if args.Offset < 0 {
+		return nil, errors.Errorf("invalid offset: %d", args.Offset)
+	}
+	if args.Limit < 1 {
+		return nil, errors.Errorf("invalid limit: %d", args.Limit)
+	}
+	q := sts.LeaderboardQuery{
+		Period: sts.Period(strings.ToLower(args.Period)),
+		Order:  sts.LeaderboardOrder(strings.ToLower(args.Order)),
+		Offset: uint64(args.Offset),
+		Limit:  uint64(args.Limit),
+	}
+	if args.UserID != nil {
+		userID, err := decodeID(*args.UserID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode user id [%s]", *args.UserID)
+		}
+		q.UserID = userID
+	}
+	leaderboard, err := r.s.GetLeaderboard(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get leaderboard")
+	}
+	return &LeaderboardResolver{
+		leaderboard: *leaderboard,
+	}, nil
+}
+
+type LeaderboardResolver struct {
+	leaderboard sts.Leaderboard
+}
+
+func (lr *LeaderboardResolver) Entries() []*LeaderboardEntryResolver {
+	entries := make([]*LeaderboardEntryResolver, 0, len(lr.leaderboard.Entries))
+	for _, e := range lr.leaderboard.Entries {
+		entries = append(entries, &LeaderboardEntryResolver{
+			entry: e,
+		})
+	}
+	return entries
+}
+
+func (lr *LeaderboardResolver) Me() *LeaderboardEntryResolver {
+	if lr.leaderboard.Me == nil {
+		return nil
+	}
+	return &LeaderboardEntryResolver{
+		entry: *lr.leaderboard.Me,
+	}
+}
+
+type LeaderboardEntryResolver struct {
+	entry sts.LeaderboardEntry
+}
+
+func (er *LeaderboardEntryResolver) Rank() int32 {
+	return int32(er.entry.Rank)
+}
+
+func (er *LeaderboardEntryResolver) User() graphql.ID {
+	return encodeID(er.entry.UserID)
+}
+
+func (er *LeaderboardEntryResolver) Prize() int32 {
+	return int32(er.entry.Prize)
+}
+
+func (er *LeaderboardEntryResolver) Wins() int32 {
+	return int32(er.entry.Wins)
+}
+
+func (er *LeaderboardEntryResolver) Tournaments() int32 {
+	return int32(er.entry.Tournaments)
+}
+
+func (er *LeaderboardEntryResolver) Profit() int32 {
+	return int32(er.entry.Profit)
+}
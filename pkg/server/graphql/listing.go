@@ -0,0 +1,187 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+type tournamentsArgs struct {
+	First         int32
+	After         *string
+	Status        *string
+	MinDeposit    *int32
+	MaxDeposit    *int32
+	NamePrefix    *string
+	CreatedAfter  *graphql.Time
+	CreatedBefore *graphql.Time
+	Sort          string
+	ViewerID      *graphql.ID
+}
+
+func (r *Resolver) Tournaments(ctx context.Context, args tournamentsArgs) (*TournamentConnectionResolver, error) {
+	if args.First < 1 {
+		return nil, errors.Errorf("invalid first: %d", args.First)
+	}
+	q := sts.TournamentQuery{
+		Sort:          sts.TournamentSort(strings.ToLower(args.Sort)),
+		CreatedAfter:  fromGraphQLTime(args.CreatedAfter),
+		CreatedBefore: fromGraphQLTime(args.CreatedBefore),
+		Limit:         uint64(args.First),
+	}
+	if args.After != nil {
+		q.After = *args.After
+	}
+	if args.ViewerID != nil {
+		viewerID, err := decodeID(*args.ViewerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode viewer id [%s]", *args.ViewerID)
+		}
+		q.ViewerID = viewerID
+	}
+	if args.Status != nil {
+		q.Status = sts.Status(strings.ToLower(*args.Status))
+	}
+	if args.MinDeposit != nil {
+		q.MinDeposit = uint64(*args.MinDeposit)
+	}
+	if args.MaxDeposit != nil {
+		q.MaxDeposit = uint64(*args.MaxDeposit)
+	}
+	if args.NamePrefix != nil {
+		q.NamePrefix = *args.NamePrefix
+	}
+	page, err := r.s.ListTournaments(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list tournaments")
+	}
+	return &TournamentConnectionResolver{
+		page: *page,
+		sort: q.Sort,
+	}, nil
+}
+
+type TournamentConnectionResolver struct {
+	page sts.TournamentPage
+	sort sts.TournamentSort
+}
+
+func (cr *TournamentConnectionResolver) Edges() []*TournamentEdgeResolver {
+	edges := make([]*TournamentEdgeResolver, 0, len(cr.page.Tournaments))
+	for _, t := range cr.page.Tournaments {
+		edges = append(edges, &TournamentEdgeResolver{
+			cursor:     sts.TournamentCursor(&t, cr.sort),
+			tournament: t,
+		})
+	}
+	return edges
+}
+
+func (cr *TournamentConnectionResolver) PageInfo() *PageInfoResolver {
+	var endCursor string
+	if n := len(cr.page.Tournaments); n > 0 {
+		endCursor = sts.TournamentCursor(&cr.page.Tournaments[n-1], cr.sort)
+	}
+	return &PageInfoResolver{
+		hasNextPage: cr.page.NextCursor != "",
+		endCursor:   endCursor,
+	}
+}
+
+type TournamentEdgeResolver struct {
+	cursor     string
+	tournament sts.Tournament
+}
+
+func (er *TournamentEdgeResolver) Cursor() string {
+	return er.cursor
+}
+
+func (er *TournamentEdgeResolver) Node() *TournamentResolver {
+	return &TournamentResolver{
+		tournament: er.tournament,
+	}
+}
+
+type usersArgs struct {
+	First int32
+	After *string
+}
+
+func (r *Resolver) Users(ctx context.Context, args usersArgs) (*UserConnectionResolver, error) {
+	if args.First < 1 {
+		return nil, errors.Errorf("invalid first: %d", args.First)
+	}
+	q := sts.UserQuery{
+		Limit: uint64(args.First),
+	}
+	if args.After != nil {
+		q.After = *args.After
+	}
+	page, err := r.s.ListUsers(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list users")
+	}
+	return &UserConnectionResolver{
+		page: *page,
+	}, nil
+}
+
+type UserConnectionResolver struct {
+	page sts.UserPage
+}
+
+func (cr *UserConnectionResolver) Edges() []*UserEdgeResolver {
+	edges := make([]*UserEdgeResolver, 0, len(cr.page.Users))
+	for _, u := range cr.page.Users {
+		edges = append(edges, &UserEdgeResolver{
+			user: u,
+		})
+	}
+	return edges
+}
+
+func (cr *UserConnectionResolver) PageInfo() *PageInfoResolver {
+	var endCursor string
+	if n := len(cr.page.Users); n > 0 {
+		endCursor = sts.UserCursor(cr.page.Users[n-1].ID)
+	}
+	return &PageInfoResolver{
+		hasNextPage: cr.page.NextCursor != "",
+		endCursor:   endCursor,
+	}
+}
+
+type UserEdgeResolver struct {
+	user sts.User
+}
+
+func (er *UserEdgeResolver) Cursor() string {
+	return sts.UserCursor(er.user.ID)
+}
+
+func (er *UserEdgeResolver) Node() *UserResolver {
+	return &UserResolver{
+		user: er.user,
+	}
+}
+
+type PageInfoResolver struct {
+	hasNextPage bool
+	endCursor   string
+}
+
+func (pr *PageInfoResolver) HasNextPage() bool {
+	return pr.hasNextPage
+}
+
+// EndCursor returns cursor of the last item of the page or nil for an empty page.
+func (pr *PageInfoResolver) EndCursor() *string {
+	if pr.endCursor == "" {
+		return nil
+	}
+	return &pr.endCursor
+}
@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+func (r *Resolver) Pairings(ctx context.Context, args tournamentArgs) ([]*PairingResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	pairings, err := r.s.GetPairings(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get pairings of tournament [%d]", id)
+	}
+	return pairingResolvers(pairings), nil
+}
+
+func pairingResolvers(pairings sts.Pairings) []*PairingResolver {
+	resolvers := make([]*PairingResolver, 0, len(pairings))
+	for _, p := range pairings {
+		resolvers = append(resolvers, &PairingResolver{
+			pairing: p,
+		})
+	}
+	return resolvers
+}
+
+func (r *Resolver) Standings(ctx context.Context, args tournamentArgs) ([]*StandingResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	standings, err := r.s.GetStandings(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get standings of tournament [%d]", id)
+	}
+	resolvers := make([]*StandingResolver, 0, len(standings))
+	for _, s := range standings {
+		resolvers = append(resolvers, &StandingResolver{
+			standing: s,
+		})
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) PairRound(ctx context.Context, args tournamentArgs) ([]*PairingResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	pairings, err := r.s.PairRound(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't pair round of tournament [%d]", id)
+	}
+	return pairingResolvers(pairings), nil
+}
+
+type reportResultArgs struct {
+	ID     graphql.ID
+	Round  int32
+	Board  int32
+	Result string
+}
+
+func (r *Resolver) ReportResult(ctx context.Context, args reportResultArgs) ([]*PairingResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	if args.Round < 1 || args.Board < 1 {
+		return nil, errors.Errorf("invalid game: round %d, board %d", args.Round, args.Board)
+	}
+	result := sts.Result(strings.ToLower(args.Result))
+	err = r.s.ReportResult(ctx, id, uint32(args.Round), uint32(args.Board), result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't report result of tournament [%d]", id)
+	}
+	return r.Pairings(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+}
+
+type PairingResolver struct {
+	pairing sts.Pairing
+}
+
+func (pr *PairingResolver) Round() int32 {
+	return int32(pr.pairing.Round)
+}
+
+func (pr *PairingResolver) Board() int32 {
+	return int32(pr.pairing.Board)
+}
+
+func (pr *PairingResolver) User1() graphql.ID {
+	return encodeID(pr.pairing.User1)
+}
+
+func (pr *PairingResolver) User2() *graphql.ID {
+	return optionalID(pr.pairing.User2)
+}
+
+func (pr *PairingResolver) Result() *string {
+	if pr.pairing.Result == sts.ResultNone {
+		return nil
+	}
+	result := strings.ToUpper(string(pr.pairing.Result))
+	return &result
+}
+
+type StandingResolver struct {
+	standing sts.Standing
+}
+
+func (sr *StandingResolver) User() graphql.ID {
+	return encodeID(sr.standing.UserID)
+}
+
+func (sr *StandingResolver) Points() float64 {
+	return sr.standing.Points
+}
+
+func (sr *StandingResolver) Buchholz() float64 {
+	return sr.standing.Buchholz
+}
+
+func (sr *StandingResolver) Wins() int32 {
+	return int32(sr.standing.Wins)
+}
+
+func (sr *StandingResolver) Draws() int32 {
+	return int32(sr.standing.Draws)
+}
+
+func (sr *StandingResolver) Losses() int32 {
+	return int32(sr.standing.Losses)
+}
@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+type teamArgs struct {
+	ID graphql.ID
+}
+
+func (r *Resolver) Team(ctx context.Context, args teamArgs) (*TeamResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	team, err := r.s.GetTeam(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get team [%d]", id)
+	}
+	return &TeamResolver{
+		team: *team,
+	}, nil
+}
+
+type createTeamArgs struct {
+	Name      string
+	CaptainID graphql.ID
+}
+
+func (r *Resolver) CreateTeam(ctx context.Context, args createTeamArgs) (*TeamResolver, error) {
+	captainID, err := decodeID(args.CaptainID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode captain id [%s]", args.CaptainID)
+	}
+	id, err := r.s.CreateTeam(ctx, args.Name, captainID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't create team [%s]", args.Name)
+	}
+	return r.Team(ctx, teamArgs{
+		ID: encodeID(id),
+	})
+}
+
+type teamUserArgs struct {
+	ID     graphql.ID
+	UserID graphql.ID
+}
+
+func (r *Resolver) InviteToTeam(ctx context.Context, args teamUserArgs) (*TeamResolver, error) {
+	return r.changeTeam(ctx, args, r.s.InviteToTeam)
+}
+
+func (r *Resolver) JoinTeam(ctx context.Context, args teamUserArgs) (*TeamResolver, error) {
+	return r.changeTeam(ctx, args, r.s.JoinTeam)
+}
+
+func (r *Resolver) changeTeam(ctx context.Context, args teamUserArgs,
+	change func(ctx context.Context, teamID, userID int64) error) (*TeamResolver, error) {
+	teamID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode team id [%s]", args.ID)
+	}
+	userID, err := decodeID(args.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.UserID)
+	}
+	err = change(ctx, teamID, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't change team [%d]", teamID)
+	}
+	return r.Team(ctx, teamArgs{
+		ID: args.ID,
+	})
+}
+
+type teamShareInput struct {
+	User  graphql.ID
+	Share int32
+}
+
+type setTeamSharesArgs struct {
+	ID     graphql.ID
+	Shares []teamShareInput
+}
+
+func (r *Resolver) SetTeamShares(ctx context.Context, args setTeamSharesArgs) (*TeamResolver, error) {
+	teamID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode team id [%s]", args.ID)
+	}
+	shares := make([]sts.TeamMember, 0, len(args.Shares))
+	for _, s := range args.Shares {
+		userID, err := decodeID(s.User)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode user id [%s]", s.User)
+		}
+		if s.Share < 0 {
+			return nil, errors.Errorf("invalid share: %d", s.Share)
+		}
+		shares = append(shares, sts.TeamMember{
+			UserID: userID,
+			Share:  uint32(s.Share),
+		})
+	}
+	err = r.s.SetTeamShares(ctx, teamID, shares)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't set shares of team [%d]", teamID)
+	}
+	return r.Team(ctx, teamArgs{
+		ID: args.ID,
+	})
+}
+
+type TeamResolver struct {
+	team sts.Team
+}
+
+func (tr *TeamResolver) ID() graphql.ID {
+	return encodeID(tr.team.ID)
+}
+
+func (tr *TeamResolver) Name() string {
+	return tr.team.Name
+}
+
+func (tr *TeamResolver) Captain() graphql.ID {
+	return encodeID(tr.team.CaptainID)
+}
+
+func (tr *TeamResolver) Members() []*TeamMemberResolver {
+	members := make([]*TeamMemberResolver, 0, len(tr.team.Members))
+	for _, m := range tr.team.Members {
+		members = append(members, &TeamMemberResolver{
+			member: m,
+		})
+	}
+	return members
+}
+
+func (tr *TeamResolver) Invited() []graphql.ID {
+	ids := make([]graphql.ID, 0, len(tr.team.Invited))
+	for _, id := range tr.team.Invited {
+		ids = append(ids, encodeID(id))
+	}
+	return ids
+}
+
+type TeamMemberResolver struct {
+	member sts.TeamMember
+}
+
+func (mr *TeamMemberResolver) User() graphql.ID {
+	return encodeID(mr.member.UserID)
+}
+
+func (mr *TeamMemberResolver) Share() int32 {
+	return int32(mr.member.Share)
+}
@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"context"
+	"strings"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/illfate/social-tournaments-service/pkg/sts"
@@ -27,25 +28,245 @@ func (r *Resolver) Tournament(ctx context.Context, args tournamentArgs) (*Tourna
 }
 
 type createTournamentsArgs struct {
-	Name    string
-	Deposit int32
+	Name        string
+	Deposit     int32
+	PrizeShares *[]int32
+	RakeType    *string
+	Rake        *int32
+	MinPlayers  *int32
+	MaxPlayers  *int32
+
+	RegistrationOpensAt  *graphql.Time
+	RegistrationClosesAt *graphql.Time
+	StartsAt             *graphql.Time
+	Format               *string
+	Rounds               *int32
+	MinRating            *int32
+	MaxRating            *int32
+	TeamBased            *bool
+	Visibility           *string
+	OrganizerID          *graphql.ID
+	GuaranteedPrize      *int32
+	Sponsor              *string
+	Currency             *string
 }
 
 func (r *Resolver) CreateTournament(ctx context.Context, args createTournamentsArgs) (*TournamentResolver, error) {
-	id, err := r.s.AddTournament(ctx, args.Name, uint64(args.Deposit))
+	settings, err := args.settings()
+	if err != nil {
+		return nil, err
+	}
+	id, err := r.s.AddTournament(ctx, settings)
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't add tournament [%s]", args.Name)
 	}
-	return &TournamentResolver{sts.Tournament{
-		ID:      id,
+	result, err := r.Tournament(ctx, tournamentArgs{
+		ID: encodeID(id),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get tournament [%d]", id)
+	}
+	return result, nil
+}
+
+// settings returns tournament settings from arguments. Omitted arguments are left zero.
+func (args createTournamentsArgs) settings() (sts.TournamentSettings, error) {
+	settings := sts.TournamentSettings{
 		Name:    args.Name,
 		Deposit: uint64(args.Deposit),
-	}}, nil
+	}
+	if args.PrizeShares != nil {
+		settings.PrizeShares = make([]uint32, 0, len(*args.PrizeShares))
+		for _, share := range *args.PrizeShares {
+			settings.PrizeShares = append(settings.PrizeShares, uint32(share))
+		}
+	}
+	if args.RakeType != nil && *args.RakeType != "NONE" {
+		settings.RakeType = sts.RakeType(strings.ToLower(*args.RakeType))
+	}
+	if args.Rake != nil {
+		settings.Rake = uint64(*args.Rake)
+	}
+	if args.MinPlayers != nil {
+		settings.MinPlayers = uint32(*args.MinPlayers)
+	}
+	if args.MaxPlayers != nil {
+		settings.MaxPlayers = uint32(*args.MaxPlayers)
+	}
+	settings.RegistrationOpensAt = fromGraphQLTime(args.RegistrationOpensAt)
+	settings.RegistrationClosesAt = fromGraphQLTime(args.RegistrationClosesAt)
+	settings.StartsAt = fromGraphQLTime(args.StartsAt)
+	if args.Format != nil {
+		settings.Format = sts.Format(strings.ToLower(*args.Format))
+	}
+	if args.Rounds != nil {
+		settings.Rounds = uint32(*args.Rounds)
+	}
+	if args.MinRating != nil {
+		settings.MinRating = uint32(*args.MinRating)
+	}
+	if args.MaxRating != nil {
+		settings.MaxRating = uint32(*args.MaxRating)
+	}
+	if args.TeamBased != nil {
+		settings.TeamBased = *args.TeamBased
+	}
+	if args.Visibility != nil {
+		settings.Visibility = sts.Visibility(strings.ToLower(*args.Visibility))
+	}
+	if args.OrganizerID != nil {
+		organizerID, err := decodeID(*args.OrganizerID)
+		if err != nil {
+			return settings, errors.Wrapf(err, "couldn't decode organizer id [%s]", *args.OrganizerID)
+		}
+		settings.OrganizerID = organizerID
+	}
+	if args.GuaranteedPrize != nil {
+		settings.GuaranteedPrize = uint64(*args.GuaranteedPrize)
+	}
+	if args.Sponsor != nil {
+		settings.Sponsor = *args.Sponsor
+	}
+	if args.Currency != nil {
+		settings.Currency = sts.Currency(*args.Currency)
+	}
+	return settings, nil
+}
+
+type updateTournamentArgs struct {
+	ID          graphql.ID
+	Name        *string
+	Deposit     *int32
+	PrizeShares *[]int32
+	RakeType    *string
+	Rake        *int32
+	MinPlayers  *int32
+	MaxPlayers  *int32
+
+	RegistrationOpensAt  *graphql.Time
+	RegistrationClosesAt *graphql.Time
+	StartsAt             *graphql.Time
+	Format               *string
+	Rounds               *int32
+	MinRating            *int32
+	MaxRating            *int32
+	TeamBased            *bool
+	Visibility           *string
+	OrganizerID          *graphql.ID
+	GuaranteedPrize      *int32
+	Sponsor              *string
+	Currency             *string
+}
+
+// UpdateTournament changes settings of tournament that are passed in arguments.
+func (r *Resolver) UpdateTournament(ctx context.Context, args updateTournamentArgs) (*TournamentResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	update, err := args.update()
+	if err != nil {
+		return nil, err
+	}
+	err = r.s.UpdateTournament(ctx, id, update)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't update tournament [%d]", id)
+	}
+	return r.Tournament(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+}
+
+// update returns changes of tournament settings from arguments. Omitted arguments are left nil.
+func (args updateTournamentArgs) update() (sts.TournamentUpdate, error) {
+	update := sts.TournamentUpdate{
+		Name:                 args.Name,
+		RegistrationOpensAt:  fromGraphQLTime(args.RegistrationOpensAt),
+		RegistrationClosesAt: fromGraphQLTime(args.RegistrationClosesAt),
+		StartsAt:             fromGraphQLTime(args.StartsAt),
+		TeamBased:            args.TeamBased,
+		Sponsor:              args.Sponsor,
+	}
+	if args.Deposit != nil {
+		deposit := uint64(*args.Deposit)
+		update.Deposit = &deposit
+	}
+	if args.PrizeShares != nil {
+		shares := make([]uint32, 0, len(*args.PrizeShares))
+		for _, share := range *args.PrizeShares {
+			shares = append(shares, uint32(share))
+		}
+		update.PrizeShares = &shares
+	}
+	if args.RakeType != nil {
+		rakeType := sts.RakeNone
+		if *args.RakeType != "NONE" {
+			rakeType = sts.RakeType(strings.ToLower(*args.RakeType))
+		}
+		update.RakeType = &rakeType
+	}
+	if args.Rake != nil {
+		rake := uint64(*args.Rake)
+		update.Rake = &rake
+	}
+	update.MinPlayers = optionalUint32(args.MinPlayers)
+	update.MaxPlayers = optionalUint32(args.MaxPlayers)
+	if args.Format != nil {
+		format := sts.Format(strings.ToLower(*args.Format))
+		update.Format = &format
+	}
+	update.Rounds = optionalUint32(args.Rounds)
+	update.MinRating = optionalUint32(args.MinRating)
+	update.MaxRating = optionalUint32(args.MaxRating)
+	if args.Visibility != nil {
+		visibility := sts.Visibility(strings.ToLower(*args.Visibility))
+		update.Visibility = &visibility
+	}
+	if args.OrganizerID != nil {
+		organizerID, err := decodeID(*args.OrganizerID)
+		if err != nil {
+			return update, errors.Wrapf(err, "couldn't decode organizer id [%s]", *args.OrganizerID)
+		}
+		update.OrganizerID = &organizerID
+	}
+	if args.GuaranteedPrize != nil {
+		guaranteedPrize := uint64(*args.GuaranteedPrize)
+		update.GuaranteedPrize = &guaranteedPrize
+	}
+	if args.Currency != nil {
+		currency := sts.Currency(*args.Currency)
+		update.Currency = &currency
+	}
+	return update, nil
+}
+
+func optionalUint32(n *int32) *uint32 {
+	if n == nil {
+		return nil
+	}
+	v := uint32(*n)
+	return &v
+}
+
+func (r *Resolver) CloneTournament(ctx context.Context, args tournamentArgs) (*TournamentResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	cloneID, err := r.s.CloneTournament(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't clone tournament [%d]", id)
+	}
+	return r.Tournament(ctx, tournamentArgs{
+		ID: encodeID(cloneID),
+	})
 }
 
 type joinTournamentArgs struct {
-	ID     graphql.ID
-	UserID graphql.ID
+	ID             graphql.ID
+	UserID         graphql.ID
+	InviteCode     *string
+	IdempotencyKey *string
 }
 
 func (r *Resolver) JoinTournament(ctx context.Context, args joinTournamentArgs) (*TournamentResolver, error) {
@@ -53,11 +274,49 @@ func (r *Resolver) JoinTournament(ctx context.Context, args joinTournamentArgs)
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
 	}
-	userID, err := decodeID(args.ID)
+	userID, err := decodeID(args.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.UserID)
+	}
+	var code string
+	if args.InviteCode != nil {
+		code = *args.InviteCode
+	}
+	_, err = r.s.JoinTournament(idempotent(ctx, args.IdempotencyKey), tID, userID, code)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't join tournament [%d]", tID)
+	}
+	result, err := r.Tournament(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get tournament [%s]", args.ID)
+	}
+	return result, nil
+}
+
+type joinTournamentAsTeamArgs struct {
+	ID             graphql.ID
+	TeamID         graphql.ID
+	InviteCode     *string
+	IdempotencyKey *string
+}
+
+func (r *Resolver) JoinTournamentAsTeam(ctx context.Context, args joinTournamentAsTeamArgs) (*TournamentResolver,
+	error) {
+	tID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
+	}
+	teamID, err := decodeID(args.TeamID)
 	if err != nil {
-		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.ID)
+		return nil, errors.Wrapf(err, "couldn't decode team id [%s]", args.TeamID)
+	}
+	var code string
+	if args.InviteCode != nil {
+		code = *args.InviteCode
 	}
-	err = r.s.JoinTournament(ctx, tID, userID)
+	err = r.s.JoinTournamentAsTeam(idempotent(ctx, args.IdempotencyKey), tID, teamID, code)
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't join tournament [%d]", tID)
 	}
@@ -70,6 +329,90 @@ func (r *Resolver) JoinTournament(ctx context.Context, args joinTournamentArgs)
 	return result, nil
 }
 
+func (r *Resolver) LeaveTournament(ctx context.Context, args joinTournamentArgs) (*TournamentResolver, error) {
+	tID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
+	}
+	userID, err := decodeID(args.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.UserID)
+	}
+	err = r.s.LeaveTournament(ctx, tID, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't leave tournament [%d]", tID)
+	}
+	result, err := r.Tournament(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get tournament [%s]", args.ID)
+	}
+	return result, nil
+}
+
+type finishTournamentArgs struct {
+	ID      graphql.ID
+	Ranking []graphql.ID
+}
+
+func (r *Resolver) FinishTournament(ctx context.Context, args finishTournamentArgs) (*TournamentResolver, error) {
+	tID, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", args.ID)
+	}
+	ranking := make([]int64, 0, len(args.Ranking))
+	for _, id := range args.Ranking {
+		userID, err := decodeID(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode user id [%s]", id)
+		}
+		ranking = append(ranking, userID)
+	}
+	err = r.s.FinishTournament(ctx, tID, ranking)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't finish tournament [%d]", tID)
+	}
+	result, err := r.Tournament(ctx, tournamentArgs{
+		ID: args.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get tournament [%s]", args.ID)
+	}
+	return result, nil
+}
+
+func (r *Resolver) OpenTournamentRegistration(ctx context.Context, args tournamentArgs) (*TournamentResolver, error) {
+	return r.changeStatus(ctx, args.ID, r.s.OpenRegistration)
+}
+
+func (r *Resolver) StartTournament(ctx context.Context, args tournamentArgs) (*TournamentResolver, error) {
+	return r.changeStatus(ctx, args.ID, r.s.StartTournament)
+}
+
+func (r *Resolver) CancelTournament(ctx context.Context, args tournamentArgs) (*TournamentResolver, error) {
+	return r.changeStatus(ctx, args.ID, r.s.CancelTournament)
+}
+
+func (r *Resolver) changeStatus(ctx context.Context, id graphql.ID,
+	change func(ctx context.Context, tournamentID int64) error) (*TournamentResolver, error) {
+	tID, err := decodeID(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode tournament id [%s]", id)
+	}
+	err = change(ctx, tID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't change status of tournament [%d]", tID)
+	}
+	result, err := r.Tournament(ctx, tournamentArgs{
+		ID: id,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get tournament [%s]", id)
+	}
+	return result, nil
+}
+
 type TournamentResolver struct {
 	tournament sts.Tournament
 }
@@ -86,15 +429,109 @@ func (tr *TournamentResolver) Deposit() int32 {
 	return int32(tr.tournament.Deposit)
 }
 
+func (tr *TournamentResolver) Currency() string {
+	return string(tr.tournament.Currency)
+}
+
+func (tr *TournamentResolver) RakeType() string {
+	if tr.tournament.RakeType == sts.RakeNone {
+		return "NONE"
+	}
+	return strings.ToUpper(string(tr.tournament.RakeType))
+}
+
+func (tr *TournamentResolver) Rake() int32 {
+	return int32(tr.tournament.Rake)
+}
+
+func (tr *TournamentResolver) MinPlayers() int32 {
+	return int32(tr.tournament.MinPlayers)
+}
+
+func (tr *TournamentResolver) MaxPlayers() int32 {
+	return int32(tr.tournament.MaxPlayers)
+}
+
+func (tr *TournamentResolver) RegistrationOpensAt() *graphql.Time {
+	return toGraphQLTime(tr.tournament.RegistrationOpensAt)
+}
+
+func (tr *TournamentResolver) RegistrationClosesAt() *graphql.Time {
+	return toGraphQLTime(tr.tournament.RegistrationClosesAt)
+}
+
+func (tr *TournamentResolver) StartsAt() *graphql.Time {
+	return toGraphQLTime(tr.tournament.StartsAt)
+}
+
+func (tr *TournamentResolver) Format() string {
+	return strings.ToUpper(string(tr.tournament.Format))
+}
+
+func (tr *TournamentResolver) Rounds() int32 {
+	return int32(tr.tournament.Rounds)
+}
+
+func (tr *TournamentResolver) MinRating() int32 {
+	return int32(tr.tournament.MinRating)
+}
+
+func (tr *TournamentResolver) MaxRating() int32 {
+	return int32(tr.tournament.MaxRating)
+}
+
+func (tr *TournamentResolver) TeamBased() bool {
+	return tr.tournament.TeamBased
+}
+
+func (tr *TournamentResolver) Visibility() string {
+	return strings.ToUpper(string(tr.tournament.Visibility))
+}
+
+func (tr *TournamentResolver) Organizer() *graphql.ID {
+	return optionalID(tr.tournament.OrganizerID)
+}
+
+func (tr *TournamentResolver) Status() string {
+	return strings.ToUpper(string(tr.tournament.Status))
+}
+
+func (tr *TournamentResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: tr.tournament.CreatedAt}
+}
+
+func (tr *TournamentResolver) GrossPrize() int32 {
+	return int32(tr.tournament.GrossPrize)
+}
+
 func (tr *TournamentResolver) Prize() int32 {
 	return int32(tr.tournament.Prize)
 }
 
+func (tr *TournamentResolver) GuaranteedPrize() int32 {
+	return int32(tr.tournament.GuaranteedPrize)
+}
+
+func (tr *TournamentResolver) Sponsor() *string {
+	if tr.tournament.Sponsor == "" {
+		return nil
+	}
+	return &tr.tournament.Sponsor
+}
+
+func (tr *TournamentResolver) Overlay() int32 {
+	return int32(tr.tournament.Overlay)
+}
+
 func (tr *TournamentResolver) Winner() *graphql.ID {
 	id := encodeID(tr.tournament.Winner)
 	return &id
 }
 
+func (tr *TournamentResolver) WinnerTeam() *graphql.ID {
+	return optionalID(tr.tournament.WinnerTeam)
+}
+
 func (tr *TournamentResolver) Users() *[]*graphql.ID {
 	idSlice := make([]*graphql.ID, 0, len(tr.tournament.Users))
 	for _, id := range tr.tournament.Users {
@@ -103,3 +540,57 @@ func (tr *TournamentResolver) Users() *[]*graphql.ID {
 	}
 	return &idSlice
 }
+
+func (tr *TournamentResolver) Teams() []graphql.ID {
+	ids := make([]graphql.ID, 0, len(tr.tournament.Teams))
+	for _, id := range tr.tournament.Teams {
+		ids = append(ids, encodeID(id))
+	}
+	return ids
+}
+
+func (tr *TournamentResolver) Waitlist() []graphql.ID {
+	ids := make([]graphql.ID, 0, len(tr.tournament.Waitlist))
+	for _, id := range tr.tournament.Waitlist {
+		ids = append(ids, encodeID(id))
+	}
+	return ids
+}
+
+func (tr *TournamentResolver) Underfilled() bool {
+	return tr.tournament.Underfilled
+}
+
+func (tr *TournamentResolver) Payouts() []*PayoutResolver {
+	payouts := make([]*PayoutResolver, 0, len(tr.tournament.Payouts))
+	for _, p := range tr.tournament.Payouts {
+		payouts = append(payouts, &PayoutResolver{
+			payout: p,
+		})
+	}
+	return payouts
+}
+
+type PayoutResolver struct {
+	payout sts.Payout
+}
+
+func (pr *PayoutResolver) Place() int32 {
+	return int32(pr.payout.Place)
+}
+
+func (pr *PayoutResolver) Share() int32 {
+	return int32(pr.payout.Share)
+}
+
+func (pr *PayoutResolver) Amount() int32 {
+	return int32(pr.payout.Amount)
+}
+
+func (pr *PayoutResolver) User() *graphql.ID {
+	return optionalID(pr.payout.UserID)
+}
+
+func (pr *PayoutResolver) Team() *graphql.ID {
+	return optionalID(pr.payout.TeamID)
+}
@@ -2,6 +2,8 @@ package graphql
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/illfate/social-tournaments-service/pkg/sts"
@@ -35,11 +37,40 @@ func (r *Resolver) CreateUser(ctx context.Context, args createUserArgs) (*UserRe
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't add user [%s]", args.Name)
 	}
-	return &UserResolver{sts.User{
-		ID:      id,
-		Name:    args.Name,
-		Balance: 0,
-	}}, nil
+	return r.User(ctx, userArgs{
+		ID: encodeID(id),
+	})
+}
+
+type updateUserArgs struct {
+	ID          graphql.ID
+	Name        *string
+	DisplayName *string
+	AvatarURL   *string
+	Country     *string
+	Bio         *string
+}
+
+// UpdateUser changes passed fields of user profile. Omitted fields are left as they are, empty
+// strings clear optional fields.
+func (r *Resolver) UpdateUser(ctx context.Context, args updateUserArgs) (*UserResolver, error) {
+	id, err := decodeID(args.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
+	}
+	err = r.s.UpdateUser(ctx, id, sts.UserUpdate{
+		Name:        args.Name,
+		DisplayName: args.DisplayName,
+		AvatarURL:   args.AvatarURL,
+		Country:     args.Country,
+		Bio:         args.Bio,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't update user [%d]", id)
+	}
+	return r.User(ctx, userArgs{
+		ID: args.ID,
+	})
 }
 
 func (r *Resolver) DeleteUser(ctx context.Context, args userArgs) (*graphql.ID, error) {
@@ -55,8 +86,10 @@ func (r *Resolver) DeleteUser(ctx context.Context, args userArgs) (*graphql.ID,
 }
 
 type userPointsArgs struct {
-	ID     graphql.ID
-	Points int32
+	ID             graphql.ID
+	Points         int32
+	Currency       string
+	IdempotencyKey *string
 }
 
 func (r *Resolver) TakeUserPoints(ctx context.Context, args userPointsArgs) (*UserResolver, error) {
@@ -64,7 +97,10 @@ func (r *Resolver) TakeUserPoints(ctx context.Context, args userPointsArgs) (*Us
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
 	}
-	err = r.s.AddPoints(ctx, id, int64(-args.Points))
+	if args.Points < 0 {
+		return nil, errors.Errorf("invalid points: %d", args.Points)
+	}
+	err = r.s.Debit(idempotent(ctx, args.IdempotencyKey), id, sts.Currency(args.Currency), uint64(args.Points))
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't take points from user [%d]", id)
 	}
@@ -78,12 +114,31 @@ func (r *Resolver) TakeUserPoints(ctx context.Context, args userPointsArgs) (*Us
 	return result, nil
 }
 
-func (r *Resolver) AddUserPoints(ctx context.Context, args userPointsArgs) (*UserResolver, error) {
+type addUserPointsArgs struct {
+	ID             graphql.ID
+	Points         int32
+	Currency       string
+	ExpiresIn      *string
+	IdempotencyKey *string
+}
+
+func (r *Resolver) AddUserPoints(ctx context.Context, args addUserPointsArgs) (*UserResolver, error) {
 	id, err := decodeID(args.ID)
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't decode id [%s]", args.ID)
 	}
-	err = r.s.AddPoints(ctx, id, int64(args.Points))
+	if args.Points < 0 {
+		return nil, errors.Errorf("invalid points: %d", args.Points)
+	}
+	var expiresIn time.Duration
+	if args.ExpiresIn != nil {
+		expiresIn, err = time.ParseDuration(*args.ExpiresIn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid expiry [%s]", *args.ExpiresIn)
+		}
+	}
+	err = r.s.Credit(idempotent(ctx, args.IdempotencyKey), id, sts.Currency(args.Currency), uint64(args.Points),
+		expiresIn)
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't add points to user [%d]", id)
 	}
@@ -97,6 +152,90 @@ func (r *Resolver) AddUserPoints(ctx context.Context, args userPointsArgs) (*Use
 	return result, nil
 }
 
+type transferPointsArgs struct {
+	FromID   graphql.ID
+	ToID     graphql.ID
+	Points   int32
+	Currency string
+}
+
+func (r *Resolver) TransferPoints(ctx context.Context, args transferPointsArgs) (*UserResolver, error) {
+	fromID, err := decodeID(args.FromID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode sender id [%s]", args.FromID)
+	}
+	toID, err := decodeID(args.ToID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode recipient id [%s]", args.ToID)
+	}
+	if args.Points < 0 {
+		return nil, errors.Errorf("invalid points: %d", args.Points)
+	}
+	err = r.s.TransferPoints(ctx, fromID, toID, sts.Currency(args.Currency), uint64(args.Points))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't transfer points from user [%d] to user [%d]", fromID, toID)
+	}
+	return r.User(ctx, userArgs{
+		ID: args.FromID,
+	})
+}
+
+type transactionsArgs struct {
+	UserID graphql.ID
+	Before *graphql.ID
+	Limit  int32
+}
+
+func (r *Resolver) Transactions(ctx context.Context, args transactionsArgs) ([]*TransactionResolver, error) {
+	userID, err := decodeID(args.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.UserID)
+	}
+	var beforeID int64
+	if args.Before != nil {
+		beforeID, err = decodeID(*args.Before)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't decode transaction id [%s]", *args.Before)
+		}
+	}
+	if args.Limit < 1 {
+		return nil, errors.Errorf("invalid limit: %d", args.Limit)
+	}
+	transactions, err := r.s.GetUserTransactions(ctx, userID, beforeID, uint64(args.Limit))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get transactions of user [%d]", userID)
+	}
+	result := make([]*TransactionResolver, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, &TransactionResolver{
+			transaction: t,
+		})
+	}
+	return result, nil
+}
+
+type ratingHistoryArgs struct {
+	UserID graphql.ID
+}
+
+func (r *Resolver) RatingHistory(ctx context.Context, args ratingHistoryArgs) ([]*RatingChangeResolver, error) {
+	userID, err := decodeID(args.UserID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode user id [%s]", args.UserID)
+	}
+	history, err := r.s.GetRatingHistory(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get rating history of user [%d]", userID)
+	}
+	result := make([]*RatingChangeResolver, 0, len(history))
+	for _, c := range history {
+		result = append(result, &RatingChangeResolver{
+			change: c,
+		})
+	}
+	return result, nil
+}
+
 type UserResolver struct {
 	user sts.User
 }
@@ -109,6 +248,147 @@ func (ur *UserResolver) Name() string {
 	return ur.user.Name
 }
 
-func (ur *UserResolver) Balance() int32 {
-	return int32(ur.user.Balance)
+func (ur *UserResolver) DisplayName() *string {
+	return optional(ur.user.DisplayName)
+}
+
+func (ur *UserResolver) AvatarURL() *string {
+	return optional(ur.user.AvatarURL)
+}
+
+func (ur *UserResolver) Country() *string {
+	return optional(ur.user.Country)
+}
+
+func (ur *UserResolver) Bio() *string {
+	return optional(ur.user.Bio)
+}
+
+// optional returns nil for an empty string.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (ur *UserResolver) Balances() []*BalanceResolver {
+	result := make([]*BalanceResolver, 0, len(ur.user.Balances))
+	for _, b := range ur.user.Balances {
+		result = append(result, &BalanceResolver{
+			balance: b,
+		})
+	}
+	return result
+}
+
+func (ur *UserResolver) Rating() float64 {
+	return ur.user.Rating
+}
+
+func (ur *UserResolver) RatingDeviation() float64 {
+	return ur.user.RatingDeviation
+}
+
+func (ur *UserResolver) Games() int32 {
+	return int32(ur.user.Games)
+}
+
+func (ur *UserResolver) Expirations() []*ExpirationResolver {
+	result := make([]*ExpirationResolver, 0, len(ur.user.Expirations))
+	for _, e := range ur.user.Expirations {
+		result = append(result, &ExpirationResolver{
+			expiration: e,
+		})
+	}
+	return result
+}
+
+type BalanceResolver struct {
+	balance sts.Balance
+}
+
+func (br *BalanceResolver) Currency() string {
+	return string(br.balance.Currency)
+}
+
+func (br *BalanceResolver) Amount() int32 {
+	return int32(br.balance.Amount)
+}
+
+type ExpirationResolver struct {
+	expiration sts.Expiration
+}
+
+func (er *ExpirationResolver) Currency() string {
+	return string(er.expiration.Currency)
+}
+
+func (er *ExpirationResolver) Amount() int32 {
+	return int32(er.expiration.Amount)
+}
+
+func (er *ExpirationResolver) ExpiresAt() graphql.Time {
+	return graphql.Time{Time: er.expiration.ExpiresAt}
+}
+
+type TransactionResolver struct {
+	transaction sts.Transaction
+}
+
+func (tr *TransactionResolver) ID() graphql.ID {
+	return encodeID(tr.transaction.ID)
+}
+
+func (tr *TransactionResolver) Amount() int32 {
+	return int32(tr.transaction.Amount)
+}
+
+func (tr *TransactionResolver) Currency() string {
+	return string(tr.transaction.Currency)
+}
+
+func (tr *TransactionResolver) Reason() string {
+	return strings.ToUpper(string(tr.transaction.Reason))
+}
+
+func (tr *TransactionResolver) Tournament() *graphql.ID {
+	if tr.transaction.TournamentID == 0 {
+		return nil
+	}
+	id := encodeID(tr.transaction.TournamentID)
+	return &id
+}
+
+// Peer returns the user who sent or received transferred points.
+func (tr *TransactionResolver) Peer() *graphql.ID {
+	return optionalID(tr.transaction.PeerID)
+}
+
+func (tr *TransactionResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: tr.transaction.CreatedAt}
+}
+
+type RatingChangeResolver struct {
+	change sts.RatingChange
+}
+
+func (rr *RatingChangeResolver) ID() graphql.ID {
+	return encodeID(rr.change.ID)
+}
+
+func (rr *RatingChangeResolver) Tournament() *graphql.ID {
+	return optionalID(rr.change.TournamentID)
+}
+
+func (rr *RatingChangeResolver) Rating() float64 {
+	return rr.change.Rating
+}
+
+func (rr *RatingChangeResolver) RatingDeviation() float64 {
+	return rr.change.Deviation
+}
+
+func (rr *RatingChangeResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: rr.change.CreatedAt}
 }
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *Server) GenerateBracket(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	body := struct {
+		Seeding []int64 `json:"seeding"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&body)
+	if err != nil && err != io.EOF {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.GenerateBracket(req.Context(), tournamentID, body.Seeding)
+	if err != nil {
+		writeError(w, err, "couldn't generate bracket")
+		return
+	}
+}
+
+func (s *Server) GetBracket(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	bracket, err := s.service.GetBracket(req.Context(), tournamentID)
+	if err != nil {
+		writeError(w, err, "couldn't get bracket")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(bracket)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) ReportMatch(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	round, err := strconv.ParseUint(vars["round"], 10, 32)
+	if err != nil {
+		badRequest(w, "incorrect round: %s", err)
+		return
+	}
+	position, err := strconv.ParseUint(vars["position"], 10, 32)
+	if err != nil {
+		badRequest(w, "incorrect position: %s", err)
+		return
+	}
+	result := struct {
+		Winner int64 `json:"winner"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&result)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.ReportMatch(req.Context(), tournamentID, uint32(round), uint32(position), result.Winner)
+	if err != nil {
+		writeError(w, err, "couldn't report match")
+		return
+	}
+}
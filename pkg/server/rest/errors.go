@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+	"github.com/pkg/errors"
+)
+
+// codeBadRequest is a code of errors in requests that couldn't be parsed.
+const codeBadRequest = "bad_request"
+
+// errorStatuses holds HTTP statuses of known service errors. Other errors are internal.
+var errorStatuses = map[error]int{
+	sts.ErrNotFound: http.StatusNotFound,
+
+	sts.ErrInsufficientFunds:  http.StatusPaymentRequired,
+	sts.ErrInsufficientBudget: http.StatusPaymentRequired,
+
+	sts.ErrRatingOutOfRange:  http.StatusForbidden,
+	sts.ErrInvalidInviteCode: http.StatusForbidden,
+	sts.ErrNotOrganizer:      http.StatusForbidden,
+	sts.ErrNotInvited:        http.StatusForbidden,
+
+	sts.ErrTournamentClosed:     http.StatusConflict,
+	sts.ErrNotEnoughPlayers:     http.StatusConflict,
+	sts.ErrTournamentNotRunning: http.StatusConflict,
+	sts.ErrBracketExists:        http.StatusConflict,
+	sts.ErrMatchNotReady:        http.StatusConflict,
+	sts.ErrMatchReported:        http.StatusConflict,
+	sts.ErrUnsupportedFormat:    http.StatusConflict,
+	sts.ErrRoundNotFinished:     http.StatusConflict,
+	sts.ErrTeamTournament:       http.StatusConflict,
+	sts.ErrSoloTournament:       http.StatusConflict,
+	sts.ErrTournamentFull:       http.StatusConflict,
+	sts.ErrTeamFull:             http.StatusConflict,
+	sts.ErrAlreadyJoined:        http.StatusConflict,
+	sts.ErrPublicTournament:     http.StatusConflict,
+	sts.ErrTransferCapExceeded:  http.StatusConflict,
+	sts.ErrHandleTaken:          http.StatusConflict,
+	sts.ErrTournamentLocked:     http.StatusConflict,
+
+	sts.ErrValidation:            http.StatusUnprocessableEntity,
+	sts.ErrNotParticipant:        http.StatusUnprocessableEntity,
+	sts.ErrInvalidPrizeShares:    http.StatusUnprocessableEntity,
+	sts.ErrInvalidRake:           http.StatusUnprocessableEntity,
+	sts.ErrInvalidCapacity:       http.StatusUnprocessableEntity,
+	sts.ErrInvalidSchedule:       http.StatusUnprocessableEntity,
+	sts.ErrInvalidRanking:        http.StatusUnprocessableEntity,
+	sts.ErrInvalidSeeding:        http.StatusUnprocessableEntity,
+	sts.ErrInvalidFormat:         http.StatusUnprocessableEntity,
+	sts.ErrInvalidResult:         http.StatusUnprocessableEntity,
+	sts.ErrInvalidRatingRange:    http.StatusUnprocessableEntity,
+	sts.ErrInvalidLeaderboard:    http.StatusUnprocessableEntity,
+	sts.ErrInvalidTeamShares:     http.StatusUnprocessableEntity,
+	sts.ErrInvalidVisibility:     http.StatusUnprocessableEntity,
+	sts.ErrInvalidGuarantee:      http.StatusUnprocessableEntity,
+	sts.ErrInvalidCurrency:       http.StatusUnprocessableEntity,
+	sts.ErrInvalidExpiry:         http.StatusUnprocessableEntity,
+	sts.ErrInvalidTransfer:       http.StatusUnprocessableEntity,
+	sts.ErrInvalidIdempotencyKey: http.StatusUnprocessableEntity,
+	sts.ErrIdempotencyKeyReused:  http.StatusUnprocessableEntity,
+	sts.ErrInvalidListing:        http.StatusUnprocessableEntity,
+	sts.ErrInvalidHandle:         http.StatusUnprocessableEntity,
+	sts.ErrInvalidProfile:        http.StatusUnprocessableEntity,
+}
+
+// errorStatus returns HTTP status of err.
+func errorStatus(err error) int {
+	if _, ok := errors.Cause(err).(*sts.TransitionError); ok {
+		return http.StatusConflict
+	}
+	if status, ok := errorStatuses[errors.Cause(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// errorBody is written in response to failed requests.
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError responds with status and code of service error err. Message describes what has failed.
+func writeError(w http.ResponseWriter, err error, message string) {
+	writeErrorBody(w, errorStatus(err), errorBody{
+		Code:    sts.ErrorCode(err),
+		Message: fmt.Sprintf("%s: %s", message, err),
+	})
+}
+
+// badRequest responds to a request that couldn't be parsed.
+func badRequest(w http.ResponseWriter, format string, args ...interface{}) {
+	writeErrorBody(w, http.StatusBadRequest, errorBody{
+		Code:    codeBadRequest,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func writeErrorBody(w http.ResponseWriter, status int, body errorBody) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+	}
+}
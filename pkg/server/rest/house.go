@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func (s *Server) GetHouseAccount(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	currency := sts.Currency(req.URL.Query().Get("currency"))
+	if currency == "" {
+		currency = sts.DefaultCurrency
+	}
+	account, err := s.service.GetHouseAccount(req.Context(), vars["name"], currency)
+	if err != nil {
+		writeError(w, err, "couldn't get house account")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(account)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) FundHouseAccount(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	bonus := struct {
+		Points   uint64       `json:"points"`
+		Currency sts.Currency `json:"currency"`
+	}{}
+	err := json.NewDecoder(req.Body).Decode(&bonus)
+	if err != nil {
+		badRequest(w, "couldn't decode json: %s", err)
+		return
+	}
+	if bonus.Currency == "" {
+		bonus.Currency = sts.DefaultCurrency
+	}
+	err = s.service.FundHouseAccount(req.Context(), vars["name"], bonus.Currency, bonus.Points)
+	if err != nil {
+		writeError(w, err, "couldn't fund house account")
+		return
+	}
+}
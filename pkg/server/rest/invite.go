@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func (s *Server) CreateInviteCode(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	request := struct {
+		OrganizerID int64 `json:"organizerId"`
+		sts.InviteSettings
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&request)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	invite, err := s.service.CreateInviteCode(req.Context(), tournamentID, request.OrganizerID,
+		request.InviteSettings)
+	if err != nil {
+		writeError(w, err, "couldn't create invite code")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(invite)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) GetInviteCodes(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	organizer := req.URL.Query().Get("organizerId")
+	organizerID, err := strconv.ParseInt(organizer, 10, 64)
+	if err != nil || organizerID < 1 {
+		badRequest(w, "incorrect organizer id: %s", organizer)
+		return
+	}
+	codes, err := s.service.GetInviteCodes(req.Context(), tournamentID, organizerID)
+	if err != nil {
+		writeError(w, err, "couldn't get invite codes")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(codes)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) RevokeInviteCode(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	organizer := struct {
+		ID int64 `json:"organizerId"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&organizer)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.RevokeInviteCode(req.Context(), tournamentID, organizer.ID, vars["code"])
+	if err != nil {
+		writeError(w, err, "couldn't revoke invite code")
+		return
+	}
+}
+
+func (s *Server) RegenerateInviteCode(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	organizer := struct {
+		ID int64 `json:"organizerId"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&organizer)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	invite, err := s.service.RegenerateInviteCode(req.Context(), tournamentID, organizer.ID, vars["code"])
+	if err != nil {
+		writeError(w, err, "couldn't regenerate invite code")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(invite)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
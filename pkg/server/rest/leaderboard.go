@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func (s *Server) GetLeaderboard(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	q := sts.LeaderboardQuery{
+		Period: sts.Period(query.Get("period")),
+		Order:  sts.LeaderboardOrder(query.Get("order")),
+		Limit:  defaultPageLimit,
+	}
+	var err error
+	if offset := query.Get("offset"); offset != "" {
+		q.Offset, err = strconv.ParseUint(offset, 10, 64)
+		if err != nil {
+			badRequest(w, "incorrect offset: %s", offset)
+			return
+		}
+	}
+	if l := query.Get("limit"); l != "" {
+		q.Limit, err = strconv.ParseUint(l, 10, 64)
+		if err != nil || q.Limit == 0 || q.Limit > maxPageLimit {
+			badRequest(w, "incorrect limit: %s", l)
+			return
+		}
+	}
+	if userID := query.Get("userId"); userID != "" {
+		q.UserID, err = strconv.ParseInt(userID, 10, 64)
+		if err != nil || q.UserID < 1 {
+			badRequest(w, "incorrect user id: %s", userID)
+			return
+		}
+	}
+	leaderboard, err := s.service.GetLeaderboard(req.Context(), q)
+	if err != nil {
+		writeError(w, err, "couldn't get leaderboard")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(leaderboard)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
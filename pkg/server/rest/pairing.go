@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func (s *Server) PairRound(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	pairings, err := s.service.PairRound(req.Context(), tournamentID)
+	if err != nil {
+		writeError(w, err, "couldn't pair round")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(pairings)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) GetPairings(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	pairings, err := s.service.GetPairings(req.Context(), tournamentID)
+	if err != nil {
+		writeError(w, err, "couldn't get pairings")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(pairings)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) ReportResult(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	round, err := strconv.ParseUint(vars["round"], 10, 32)
+	if err != nil {
+		badRequest(w, "incorrect round: %s", err)
+		return
+	}
+	board, err := strconv.ParseUint(vars["board"], 10, 32)
+	if err != nil {
+		badRequest(w, "incorrect board: %s", err)
+		return
+	}
+	body := struct {
+		Result sts.Result `json:"result"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.ReportResult(req.Context(), tournamentID, uint32(round), uint32(board), body.Result)
+	if err != nil {
+		writeError(w, err, "couldn't report result")
+		return
+	}
+}
+
+func (s *Server) GetStandings(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	standings, err := s.service.GetStandings(req.Context(), tournamentID)
+	if err != nil {
+		writeError(w, err, "couldn't get standings")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(standings)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
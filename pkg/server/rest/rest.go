@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -20,11 +21,57 @@ func New(db sts.Service) *Server {
 		Handler: r,
 	}
 	r.HandleFunc("/user", s.AddUser).Methods("POST")
+	r.HandleFunc("/user", s.ListUsers).Methods("GET")
 	r.HandleFunc("/user/{id:[1-9]+[0-9]*}", s.GetUser).Methods("GET")
 	r.HandleFunc("/user/{id:[1-9]+[0-9]*}", s.DeleteUser).Methods("DELETE")
+	r.HandleFunc("/user/{id:[1-9]+[0-9]*}", s.UpdateUser).Methods("PATCH")
 	r.HandleFunc("/user/{id:[1-9]+[0-9]*}/{action:(?:fund|take)}", s.AddPoints).Methods("POST")
+	r.HandleFunc("/user/{id:[1-9]+[0-9]*}/transfer", s.TransferPoints).Methods("POST")
+	r.HandleFunc("/user/{id:[1-9]+[0-9]*}/transactions", s.GetUserTransactions).Methods("GET")
+	r.HandleFunc("/user/{id:[1-9]+[0-9]*}/ratings", s.GetRatingHistory).Methods("GET")
 	r.HandleFunc("/tournament", s.AddTournament).Methods("POST")
+	r.HandleFunc("/tournament", s.ListTournaments).Methods("GET")
 	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}", s.GetTournament).Methods("GET")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}", s.UpdateTournament).Methods("PATCH")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/clone", s.CloneTournament).Methods("POST")
 	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/join", s.JoinTournament).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/join", s.LeaveTournament).Methods("DELETE")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/finish", s.FinishTournament).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/{action:(?:open|start|cancel)}", s.ChangeTournamentStatus).
+		Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/bracket", s.GenerateBracket).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/bracket", s.GetBracket).Methods("GET")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/bracket/{round:[1-9]+[0-9]*}/{position:[1-9]+[0-9]*}",
+		s.ReportMatch).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/invites", s.CreateInviteCode).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/invites", s.GetInviteCodes).Methods("GET")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/invites/{code:[a-z2-7]+}", s.RevokeInviteCode).Methods("DELETE")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/invites/{code:[a-z2-7]+}/regenerate", s.RegenerateInviteCode).
+		Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/rounds", s.PairRound).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/pairings", s.GetPairings).Methods("GET")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/pairings/{round:[1-9]+[0-9]*}/{board:[1-9]+[0-9]*}",
+		s.ReportResult).Methods("POST")
+	r.HandleFunc("/tournament/{id:[1-9]+[0-9]*}/standings", s.GetStandings).Methods("GET")
+	r.HandleFunc("/team", s.CreateTeam).Methods("POST")
+	r.HandleFunc("/team/{id:[1-9]+[0-9]*}", s.GetTeam).Methods("GET")
+	r.HandleFunc("/team/{id:[1-9]+[0-9]*}/invite", s.InviteToTeam).Methods("POST")
+	r.HandleFunc("/team/{id:[1-9]+[0-9]*}/join", s.JoinTeam).Methods("POST")
+	r.HandleFunc("/team/{id:[1-9]+[0-9]*}/shares", s.SetTeamShares).Methods("PUT")
+	r.HandleFunc("/leaderboard", s.GetLeaderboard).Methods("GET")
+	r.HandleFunc("/house/{name}", s.GetHouseAccount).Methods("GET")
+	r.HandleFunc("/house/{name}/fund", s.FundHouseAccount).Methods("POST")
 	return &s
 }
+
+// idempotencyKeyHeader holds a key that makes retries of a request take effect once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotent returns context of req that carries its idempotency key, if request has one.
+func idempotent(req *http.Request) context.Context {
+	key := req.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		return req.Context()
+	}
+	return sts.WithIdempotencyKey(req.Context(), key)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 
@@ -37,7 +38,7 @@ func TestAddUser(t *testing.T) {
 			method:      http.MethodPost,
 			request:     `{  : "i" }`,
 			status:      http.StatusBadRequest,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
 		},
 		{
 			name:    "incorrect method",
@@ -91,9 +92,11 @@ func TestGetUser(t *testing.T) {
 		contentType string
 	}{
 		{
-			name:        "correct test",
-			id:          "1",
-			response:    `{"id":1,"name":"ilya","balance":0}`,
+			name: "correct test",
+			id:   "1",
+			response: `{"id":1,"name":"ilya","balances":[{"currency":"bonus","amount":250},` +
+				`{"currency":"premium","amount":3}],"rating":1532.5,"ratingDeviation":84.25,"games":12,` +
+				`"expirations":[{"currency":"bonus","amount":50,"expiresAt":"2019-12-18T12:00:00Z"}]}`,
 			status:      http.StatusOK,
 			contentType: "application/json",
 		},
@@ -104,16 +107,26 @@ func TestGetUser(t *testing.T) {
 			contentType: "text/plain; charset=utf-8",
 		},
 		{
-			name:   "uncreated account",
-			id:     "1000",
-			status: http.StatusNotFound,
+			name:        "uncreated account",
+			id:          "1000",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
 		},
 	}
 	db := new(mockdb.Connector)
 	db.On("GetUser", int64(1)).Return(&sts.User{
-		ID:      1,
-		Name:    "ilya",
-		Balance: 0,
+		ID:   1,
+		Name: "ilya",
+		Balances: []sts.Balance{
+			{Currency: sts.DefaultCurrency, Amount: 250},
+			{Currency: "premium", Amount: 3},
+		},
+		Rating:          1532.5,
+		RatingDeviation: 84.25,
+		Games:           12,
+		Expirations: []sts.Expiration{
+			{Currency: sts.DefaultCurrency, Amount: 50, ExpiresAt: time.Date(2019, 12, 18, 12, 0, 0, 0, time.UTC)},
+		},
 	}, nil)
 	db.On("GetUser", int64(1000)).Return((*sts.User)(nil), sts.ErrNotFound)
 	s := New(db)
@@ -156,6 +169,7 @@ func TestFund(t *testing.T) {
 		name        string
 		id          string
 		request     string
+		key         string
 		status      int
 		contentType string
 	}{
@@ -177,12 +191,67 @@ func TestFund(t *testing.T) {
 			id:          "10",
 			request:     `{ "name" : "max" }`,
 			status:      http.StatusNotFound,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
+		},
+		{
+			name:        "other currency",
+			id:          "1",
+			request:     `{ "points" : 7, "currency" : "premium" }`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "unknown currency",
+			id:          "1",
+			request:     `{ "points" : 7, "currency" : "gold" }`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "negative points",
+			id:          "1",
+			request:     `{ "points" : -7 }`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "expiring points",
+			id:          "1",
+			request:     `{ "points" : 5, "expiresIn" : "720h" }`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect expiry",
+			id:          "1",
+			request:     `{ "points" : 5, "expiresIn" : "month" }`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "negative expiry",
+			id:          "1",
+			request:     `{ "points" : 5, "expiresIn" : "-1h" }`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "reused idempotency key",
+			id:          "2",
+			request:     `{ "points" : 7 }`,
+			key:         "9d2e",
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
 		},
 	}
 	db := new(mockdb.Connector)
-	db.On("AddPoints", int64(1), int64(7)).Return(nil)
-	db.On("AddPoints", int64(10), int64(0)).Return(sts.ErrNotFound)
+	db.On("Credit", int64(1), sts.DefaultCurrency, uint64(7), time.Duration(0)).Return(nil)
+	db.On("Credit", int64(1), sts.Currency("premium"), uint64(7), time.Duration(0)).Return(nil)
+	db.On("Credit", int64(1), sts.Currency("gold"), uint64(7), time.Duration(0)).Return(sts.ErrInvalidCurrency)
+	db.On("Credit", int64(10), sts.DefaultCurrency, uint64(0), time.Duration(0)).Return(sts.ErrNotFound)
+	db.On("Credit", int64(1), sts.DefaultCurrency, uint64(5), 720*time.Hour).Return(nil)
+	db.On("Credit", int64(1), sts.DefaultCurrency, uint64(5), -time.Hour).Return(sts.ErrInvalidExpiry)
+	db.On("Credit", int64(2), sts.DefaultCurrency, uint64(7), time.Duration(0)).Return(sts.ErrIdempotencyKeyReused)
 	s := New(db)
 
 	server := httptest.NewServer(s)
@@ -194,6 +263,9 @@ func TestFund(t *testing.T) {
 			if err != nil {
 				t.Fatalf("could not create request: %v", err)
 			}
+			if tc.key != "" {
+				req.Header.Set("Idempotency-Key", tc.key)
+			}
 			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				t.Fatalf("couldnt get response: %s", err)
@@ -235,20 +307,28 @@ func TestTake(t *testing.T) {
 			id:          "1000",
 			request:     `{ "points" : 7 }`,
 			status:      http.StatusNotFound,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
 		},
 		{
 			name:        "incorrect bonus request",
 			id:          "1",
 			request:     `{ "points" : 7000 }`,
 			status:      http.StatusInternalServerError,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
+		},
+		{
+			name:        "not enough points",
+			id:          "1",
+			request:     `{ "points" : 700 }`,
+			status:      http.StatusPaymentRequired,
+			contentType: "application/json",
 		},
 	}
 	db := new(mockdb.Connector)
-	db.On("AddPoints", int64(1), int64(-7)).Return(nil)
-	db.On("AddPoints", int64(1000), int64(-7)).Return(sts.ErrNotFound)
-	db.On("AddPoints", int64(1), int64(-7000)).Return(sql.ErrNoRows)
+	db.On("Debit", int64(1), sts.DefaultCurrency, uint64(7)).Return(nil)
+	db.On("Debit", int64(1000), sts.DefaultCurrency, uint64(7)).Return(sts.ErrNotFound)
+	db.On("Debit", int64(1), sts.DefaultCurrency, uint64(7000)).Return(sql.ErrNoRows)
+	db.On("Debit", int64(1), sts.DefaultCurrency, uint64(700)).Return(sts.ErrInsufficientFunds)
 	s := New(db)
 
 	server := httptest.NewServer(s)
@@ -277,6 +357,93 @@ func TestTake(t *testing.T) {
 	}
 }
 
+func TestTransferPoints(t *testing.T) {
+	tt := []struct {
+		name        string
+		id          string
+		request     string
+		status      int
+		contentType string
+	}{
+		{
+			name:        "correct test",
+			id:          "1",
+			request:     `{ "to" : 2, "points" : 7 }`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "other currency",
+			id:          "1",
+			request:     `{ "to" : 2, "points" : 7, "currency" : "premium" }`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "uncreated recipient",
+			id:          "1",
+			request:     `{ "to" : 1000, "points" : 7 }`,
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "same user",
+			id:          "1",
+			request:     `{ "to" : 1, "points" : 7 }`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "not enough points",
+			id:          "1",
+			request:     `{ "to" : 2, "points" : 700 }`,
+			status:      http.StatusPaymentRequired,
+			contentType: "application/json",
+		},
+		{
+			name:        "daily cap",
+			id:          "1",
+			request:     `{ "to" : 3, "points" : 70 }`,
+			status:      http.StatusConflict,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect json",
+			id:          "1",
+			request:     `{ "to" : "max" }`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("TransferPoints", int64(1), int64(2), sts.DefaultCurrency, uint64(7)).Return(nil)
+	db.On("TransferPoints", int64(1), int64(2), sts.Currency("premium"), uint64(7)).Return(nil)
+	db.On("TransferPoints", int64(1), int64(1000), sts.DefaultCurrency, uint64(7)).Return(sts.ErrNotFound)
+	db.On("TransferPoints", int64(1), int64(1), sts.DefaultCurrency, uint64(7)).Return(sts.ErrInvalidTransfer)
+	db.On("TransferPoints", int64(1), int64(2), sts.DefaultCurrency, uint64(700)).Return(sts.ErrInsufficientFunds)
+	db.On("TransferPoints", int64(1), int64(3), sts.DefaultCurrency, uint64(70)).Return(sts.ErrTransferCapExceeded)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(fmt.Sprintf("%s/user/%s/transfer", server.URL, tc.id), "application/json",
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); tc.contentType != contentType {
+				t.Fatalf("expected status %v; got %v", tc.contentType, contentType)
+			}
+		})
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	tt := []struct {
 		name        string
@@ -296,9 +463,10 @@ func TestDeleteUser(t *testing.T) {
 			contentType: "text/plain; charset=utf-8",
 		},
 		{
-			name:   "uncreated user",
-			id:     "100",
-			status: http.StatusNotFound,
+			name:        "uncreated user",
+			id:          "100",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
 		},
 	}
 	db := new(mockdb.Connector)
@@ -331,6 +499,92 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
+func TestUpdateUser(t *testing.T) {
+	tt := []struct {
+		name        string
+		id          string
+		request     string
+		status      int
+		contentType string
+	}{
+		{
+			name:    "correct test",
+			id:      "1",
+			request: `{"name":"ahoi","bio":"chess","country":null}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:        "taken name",
+			id:          "1",
+			request:     `{"name":"Taken"}`,
+			status:      http.StatusConflict,
+			contentType: "application/json",
+		},
+		{
+			name:        "invalid profile",
+			id:          "1",
+			request:     `{"avatarUrl":"ftp://avatar"}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "unknown field",
+			id:          "1",
+			request:     `{"rating":3000}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect field",
+			id:          "1",
+			request:     `{"bio":5}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "uncreated user",
+			id:          "100",
+			request:     `{"bio":"chess"}`,
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+	}
+	name, bio, clear := "ahoi", "chess", ""
+	taken := "Taken"
+	avatar := "ftp://avatar"
+	db := new(mockdb.Connector)
+	db.On("UpdateUser", int64(1), sts.UserUpdate{Name: &name, Country: &clear, Bio: &bio}).Return(nil)
+	db.On("UpdateUser", int64(1), sts.UserUpdate{Name: &taken}).Return(sts.ErrHandleTaken)
+	db.On("UpdateUser", int64(1), sts.UserUpdate{AvatarURL: &avatar}).Return(sts.ErrInvalidProfile)
+	db.On("UpdateUser", int64(100), sts.UserUpdate{Bio: &bio}).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PATCH", fmt.Sprintf("%s/user/%s", server.URL, tc.id),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); tc.contentType != contentType {
+				t.Fatalf("expected status %v; got %v", tc.contentType, contentType)
+			}
+		})
+	}
+}
+
 func TestAddTournament(t *testing.T) {
 	tt := []struct {
 		name        string
@@ -353,14 +607,80 @@ func TestAddTournament(t *testing.T) {
 			method:      http.MethodPost,
 			request:     `{  :  }`,
 			status:      http.StatusBadRequest,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
 		},
 		{
 			name:        "incorrect deposit",
 			method:      http.MethodPost,
 			request:     `{"name": "football","deposit": -1000}`,
 			status:      http.StatusBadRequest,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect prize shares",
+			method:      http.MethodPost,
+			request:     `{"name": "chess","deposit": 1000,"prizeShares":[50,30]}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:   "scheduled tournament",
+			method: http.MethodPost,
+			request: `{"name": "darts","deposit": 100,"registrationClosesAt":"2019-09-10T12:00:00Z",` +
+				`"startsAt":"2019-09-10T13:00:00Z"}`,
+			response:    `{"id":2}`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:   "incorrect schedule",
+			method: http.MethodPost,
+			request: `{"name": "darts","deposit": 100,"startsAt":"2019-09-10T11:00:00Z",` +
+				`"registrationClosesAt":"2019-09-10T12:00:00Z"}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect capacity",
+			method:      http.MethodPost,
+			request:     `{"name": "bridge","deposit": 1000,"minPlayers":8,"maxPlayers":4}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect rake",
+			method:      http.MethodPost,
+			request:     `{"name": "go","deposit": 1000,"rakeType":"percent","rake":150}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect format",
+			method:      http.MethodPost,
+			request:     `{"name": "chess","deposit": 1000,"format":"round_robin","rounds":3}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "unknown currency",
+			method:      http.MethodPost,
+			request:     `{"name": "poker","deposit": 100,"currency":"gold"}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "unknown sponsor",
+			method:      http.MethodPost,
+			request:     `{"name": "poker","deposit": 0,"guaranteedPrize":1000,"sponsor":"casino"}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect visibility",
+			method:      http.MethodPost,
+			request:     `{"name": "chess","deposit": 1000,"visibility":"private"}`,
+			status:      http.StatusUnprocessableEntity,
+			contentType: "application/json",
 		},
 		{
 			name:    "incorrect method",
@@ -370,7 +690,63 @@ func TestAddTournament(t *testing.T) {
 		},
 	}
 	db := new(mockdb.Connector)
-	db.On("AddTournament", "poker", uint64(1000)).Return(int64(1), nil)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:    "chess",
+		Deposit: 1000,
+		Format:  sts.FormatRoundRobin,
+		Rounds:  3,
+	}).Return(int64(0), sts.ErrInvalidFormat)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:    "poker",
+		Deposit: 1000,
+	}).Return(int64(1), nil)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:        "chess",
+		Deposit:     1000,
+		PrizeShares: []uint32{50, 30},
+	}).Return(int64(0), sts.ErrInvalidPrizeShares)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:     "go",
+		Deposit:  1000,
+		RakeType: sts.RakePercent,
+		Rake:     150,
+	}).Return(int64(0), sts.ErrInvalidRake)
+	closes := time.Date(2019, 9, 10, 12, 0, 0, 0, time.UTC)
+	starts := closes.Add(time.Hour)
+	early := closes.Add(-time.Hour)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:                 "darts",
+		Deposit:              100,
+		RegistrationClosesAt: &closes,
+		StartsAt:             &starts,
+	}).Return(int64(2), nil)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:                 "darts",
+		Deposit:              100,
+		RegistrationClosesAt: &closes,
+		StartsAt:             &early,
+	}).Return(int64(0), sts.ErrInvalidSchedule)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:       "bridge",
+		Deposit:    1000,
+		MinPlayers: 8,
+		MaxPlayers: 4,
+	}).Return(int64(0), sts.ErrInvalidCapacity)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:     "poker",
+		Deposit:  100,
+		Currency: "gold",
+	}).Return(int64(0), sts.ErrInvalidCurrency)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:            "poker",
+		GuaranteedPrize: 1000,
+		Sponsor:         "casino",
+	}).Return(int64(0), sts.ErrInvalidGuarantee)
+	db.On("AddTournament", sts.TournamentSettings{
+		Name:       "chess",
+		Deposit:    1000,
+		Visibility: sts.VisibilityPrivate,
+	}).Return(int64(0), sts.ErrInvalidVisibility)
 	s := New(db)
 
 	server := httptest.NewServer(s)
@@ -415,9 +791,12 @@ func TestGetTournament(t *testing.T) {
 		contentType string
 	}{
 		{
-			name:        "correct test",
-			id:          "1",
-			response:    `{"id":1,"name":"poker","deposit":1000,"prize":0,"winner":0,"users":[2,3]}`,
+			name: "correct test",
+			id:   "1",
+			response: `{"id":1,"name":"poker","deposit":1000,"prizeShares":[70,30],"rakeType":"fixed","rake":100,` +
+				`"minPlayers":2,"maxPlayers":2,"startsAt":"2019-09-10T13:00:00Z","status":"registration",` +
+				`"createdAt":"2019-09-01T10:00:00Z","grossPrize":2000,"prize":1800,"winner":0,` +
+				`"users":[2,3],"waitlist":[4],"underfilled":false,"payouts":[{"place":1,"share":70,"amount":1260},{"place":2,"share":30,"amount":540}]}`,
 			status:      http.StatusOK,
 			contentType: "application/json",
 		},
@@ -431,17 +810,34 @@ func TestGetTournament(t *testing.T) {
 			name:        "uncreated account",
 			id:          "1000",
 			status:      http.StatusNotFound,
-			contentType: "text/plain; charset=utf-8",
+			contentType: "application/json",
 		},
 	}
+	startsAt := time.Date(2019, 9, 10, 13, 0, 0, 0, time.UTC)
 	db := new(mockdb.Connector)
 	db.On("GetTournament", int64(1)).Return(&sts.Tournament{
-		ID:      1,
-		Name:    "poker",
-		Deposit: 1000,
-		Prize:   0,
-		Winner:  0,
-		Users:   []int64{2, 3},
+		ID: 1,
+		TournamentSettings: sts.TournamentSettings{
+			Name:        "poker",
+			Deposit:     1000,
+			PrizeShares: []uint32{70, 30},
+			RakeType:    sts.RakeFixed,
+			Rake:        100,
+			MinPlayers:  2,
+			MaxPlayers:  2,
+			StartsAt:    &startsAt,
+		},
+		Status:     sts.StatusRegistration,
+		CreatedAt:  time.Date(2019, 9, 1, 10, 0, 0, 0, time.UTC),
+		GrossPrize: 2000,
+		Prize:      1800,
+		Winner:     0,
+		Users:      []int64{2, 3},
+		Waitlist:   []int64{4},
+		Payouts: []sts.Payout{
+			{Place: 1, Share: 70, Amount: 1260},
+			{Place: 2, Share: 30, Amount: 540},
+		},
 	}, nil)
 	db.On("GetTournament", int64(1000)).Return((*sts.Tournament)(nil), sts.ErrNotFound)
 	s := New(db)
@@ -479,42 +875,286 @@ func TestGetTournament(t *testing.T) {
 	}
 }
 
-func TestJoinTournament(t *testing.T) {
+func TestUpdateTournament(t *testing.T) {
 	tt := []struct {
-		name         string
-		tournamentID string
-		request      string
-		status       int
+		name        string
+		id          string
+		request     string
+		status      int
+		contentType string
 	}{
 		{
-			name:         "correct test",
-			tournamentID: "1",
-			request:      `{"userId":1}`,
-			status:       http.StatusOK,
+			name:    "correct test",
+			id:      "1",
+			request: `{"name": "poker","deposit": 1000}`,
+			status:  http.StatusOK,
 		},
 		{
-			name:         "incorrect request userID",
-			tournamentID: "1",
-			request:      `{"userId":-111}`,
-			status:       http.StatusNotFound,
+			name:    "cleared schedule",
+			id:      "1",
+			request: `{"startsAt": null}`,
+			status:  http.StatusOK,
 		},
 		{
-			name:         "incorrect tournamentID",
-			tournamentID: "lol",
-			request:      `{"userId":1}`,
-			status:       http.StatusNotFound,
-		},
+			name:        "null deposit",
+			id:          "1",
+			request:     `{"deposit": null}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "null prize shares",
+			id:          "1",
+			request:     `{"prizeShares": null}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "unknown field",
+			id:          "1",
+			request:     `{"prize": 1000}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect field",
+			id:          "1",
+			request:     `{"deposit": "1000"}`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "incorrect request",
+			id:          "1",
+			request:     `{  :  }`,
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "joined tournament",
+			id:          "1",
+			request:     `{"name": "poker","deposit": 500}`,
+			status:      http.StatusConflict,
+			contentType: "application/json",
+		},
+		{
+			name:        "started tournament",
+			id:          "2",
+			request:     `{"name": "poker","deposit": 1000}`,
+			status:      http.StatusConflict,
+			contentType: "application/json",
+		},
+		{
+			name:        "uncreated tournament",
+			id:          "100",
+			request:     `{"name": "poker","deposit": 1000}`,
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+	}
+	update := func(name string, deposit uint64) sts.TournamentUpdate {
+		return sts.TournamentUpdate{Name: &name, Deposit: &deposit}
+	}
+	db := new(mockdb.Connector)
+	db.On("UpdateTournament", int64(1), update("poker", 1000)).Return(nil)
+	db.On("UpdateTournament", int64(1), update("poker", 500)).Return(sts.ErrTournamentLocked)
+	db.On("UpdateTournament", int64(2), update("poker", 1000)).Return(sts.ErrTournamentClosed)
+	db.On("UpdateTournament", int64(100), update("poker", 1000)).Return(sts.ErrNotFound)
+	db.On("UpdateTournament", int64(1), sts.TournamentUpdate{
+		StartsAt: new(time.Time),
+	}).Return(nil)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PATCH", fmt.Sprintf("%s/tournament/%s", server.URL, tc.id),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); tc.contentType != contentType {
+				t.Fatalf("expected status %v; got %v", tc.contentType, contentType)
+			}
+		})
+	}
+}
+
+func TestCloneTournament(t *testing.T) {
+	tt := []struct {
+		name        string
+		id          string
+		response    string
+		status      int
+		contentType string
+	}{
+		{
+			name:        "correct test",
+			id:          "1",
+			response:    `{"id":2}`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name:        "uncreated tournament",
+			id:          "100",
+			status:      http.StatusNotFound,
+			contentType: "application/json",
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("CloneTournament", int64(1)).Return(int64(2), nil)
+	db.On("CloneTournament", int64(100)).Return(int64(0), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(fmt.Sprintf("%s/tournament/%s/clone", server.URL, tc.id), "", nil)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); tc.contentType != contentType {
+				t.Fatalf("expected status %v; got %v", tc.contentType, contentType)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestJoinTournament(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		request      string
+		key          string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			request:      `{"userId":1}`,
+			status:       http.StatusOK,
+		},
+		{
+			name:         "full tournament",
+			tournamentID: "1",
+			request:      `{"userId":2}`,
+			status:       http.StatusAccepted,
+		},
+		{
+			name:         "incorrect request userID",
+			tournamentID: "1",
+			request:      `{"userId":-111}`,
+			status:       http.StatusNotFound,
+		},
+		{
+			name:         "incorrect tournamentID",
+			tournamentID: "lol",
+			request:      `{"userId":1}`,
+			status:       http.StatusNotFound,
+		},
 		{
 			name:         "uncreated account",
 			tournamentID: "100",
 			request:      `{"userId":1}`,
 			status:       http.StatusNotFound,
 		},
+		{
+			name:         "closed tournament",
+			tournamentID: "2",
+			request:      `{"userId":1}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "rating out of range",
+			tournamentID: "1",
+			request:      `{"userId":3}`,
+			status:       http.StatusForbidden,
+		},
+		{
+			name:         "team",
+			tournamentID: "3",
+			request:      `{"teamId":1}`,
+			status:       http.StatusOK,
+		},
+		{
+			name:         "team in solo tournament",
+			tournamentID: "1",
+			request:      `{"teamId":1}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "user in team tournament",
+			tournamentID: "3",
+			request:      `{"userId":1}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "invalid invite code",
+			tournamentID: "4",
+			request:      `{"userId":1,"inviteCode":"abc"}`,
+			status:       http.StatusForbidden,
+		},
+		{
+			name:         "team with invalid invite code",
+			tournamentID: "4",
+			request:      `{"teamId":1,"inviteCode":"abc"}`,
+			status:       http.StatusForbidden,
+		},
+		{
+			name:         "retry",
+			tournamentID: "1",
+			request:      `{"userId":1}`,
+			key:          "f3c1",
+			status:       http.StatusOK,
+		},
+		{
+			name:         "reused idempotency key",
+			tournamentID: "1",
+			request:      `{"userId":5}`,
+			key:          "f3c1",
+			status:       http.StatusUnprocessableEntity,
+		},
 	}
 	db := new(mockdb.Connector)
-	db.On("JoinTournament", int64(1), int64(1)).Return(nil)
-	db.On("JoinTournament", int64(1), int64(-111)).Return(sts.ErrNotFound)
-	db.On("JoinTournament", int64(100), int64(1)).Return(sts.ErrNotFound)
+	db.On("JoinTournamentAsTeam", int64(3), int64(1), "").Return(nil)
+	db.On("JoinTournamentAsTeam", int64(1), int64(1), "").Return(sts.ErrSoloTournament)
+	db.On("JoinTournament", int64(3), int64(1), "").Return(false, sts.ErrTeamTournament)
+	db.On("JoinTournament", int64(1), int64(1), "").Return(false, nil)
+	db.On("JoinTournament", int64(1), int64(3), "").Return(false, sts.ErrRatingOutOfRange)
+	db.On("JoinTournament", int64(1), int64(2), "").Return(true, nil)
+	db.On("JoinTournament", int64(2), int64(1), "").Return(false, sts.ErrTournamentClosed)
+	db.On("JoinTournament", int64(1), int64(-111), "").Return(false, sts.ErrNotFound)
+	db.On("JoinTournament", int64(100), int64(1), "").Return(false, sts.ErrNotFound)
+	db.On("JoinTournament", int64(4), int64(1), "abc").Return(false, sts.ErrInvalidInviteCode)
+	db.On("JoinTournamentAsTeam", int64(4), int64(1), "abc").Return(sts.ErrInvalidInviteCode)
+	db.On("JoinTournament", int64(1), int64(5), "").Return(false, sts.ErrIdempotencyKeyReused)
 	s := New(db)
 
 	server := httptest.NewServer(s)
@@ -527,6 +1167,9 @@ func TestJoinTournament(t *testing.T) {
 			if err != nil {
 				t.Fatalf("could not create request: %v", err)
 			}
+			if tc.key != "" {
+				req.Header.Set("Idempotency-Key", tc.key)
+			}
 			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				t.Fatalf("couldnt get response: %s", err)
@@ -541,3 +1184,1548 @@ func TestJoinTournament(t *testing.T) {
 		})
 	}
 }
+
+func TestFinishTournament(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		request      string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			request:      `{"ranking":[1,2]}`,
+			status:       http.StatusOK,
+		},
+		{
+			name:         "winner isn't a participant",
+			tournamentID: "1",
+			request:      `{"ranking":[5,2]}`,
+			status:       http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "incomplete ranking",
+			tournamentID: "1",
+			request:      `{"ranking":[2]}`,
+			status:       http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "finished tournament",
+			tournamentID: "2",
+			request:      `{"ranking":[1,2]}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "incorrect request",
+			tournamentID: "1",
+			request:      `{"ranking":`,
+			status:       http.StatusBadRequest,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			request:      `{"ranking":[1,2]}`,
+			status:       http.StatusNotFound,
+		},
+		{
+			name:         "sponsor can't pay overlay",
+			tournamentID: "3",
+			request:      `{"ranking":[1,2]}`,
+			status:       http.StatusPaymentRequired,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("FinishTournament", int64(3), []int64{1, 2}).Return(sts.ErrInsufficientBudget)
+	db.On("FinishTournament", int64(1), []int64{1, 2}).Return(nil)
+	db.On("FinishTournament", int64(1), []int64{5, 2}).Return(sts.ErrNotParticipant)
+	db.On("FinishTournament", int64(1), []int64{2}).Return(sts.ErrInvalidRanking)
+	db.On("FinishTournament", int64(2), []int64{1, 2}).Return(&sts.TransitionError{
+		From: sts.StatusFinished,
+		To:   sts.StatusFinished,
+	})
+	db.On("FinishTournament", int64(100), []int64{1, 2}).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/%s/finish", server.URL, tc.tournamentID),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestChangeTournamentStatus(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		action       string
+		status       int
+	}{
+		{
+			name:         "open registration",
+			tournamentID: "1",
+			action:       "open",
+			status:       http.StatusOK,
+		},
+		{
+			name:         "start tournament",
+			tournamentID: "1",
+			action:       "start",
+			status:       http.StatusOK,
+		},
+		{
+			name:         "cancel tournament",
+			tournamentID: "4",
+			action:       "cancel",
+			status:       http.StatusOK,
+		},
+		{
+			name:         "illegal transition",
+			tournamentID: "2",
+			action:       "cancel",
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "not enough players",
+			tournamentID: "3",
+			action:       "start",
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "unknown action",
+			tournamentID: "1",
+			action:       "pause",
+			status:       http.StatusNotFound,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			action:       "open",
+			status:       http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("OpenRegistration", int64(1)).Return(nil)
+	db.On("StartTournament", int64(1)).Return(nil)
+	db.On("CancelTournament", int64(2)).Return(&sts.TransitionError{
+		From: sts.StatusFinished,
+		To:   sts.StatusCancelled,
+	})
+	db.On("StartTournament", int64(3)).Return(sts.ErrNotEnoughPlayers)
+	db.On("CancelTournament", int64(4)).Return(nil)
+	db.On("OpenRegistration", int64(100)).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/%s/%s", server.URL, tc.tournamentID, tc.action), nil)
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestLeaveTournament(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		request      string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			request:      `{"userId":1}`,
+			status:       http.StatusOK,
+		},
+		{
+			name:         "not a participant",
+			tournamentID: "1",
+			request:      `{"userId":2}`,
+			status:       http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "started tournament",
+			tournamentID: "2",
+			request:      `{"userId":1}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			request:      `{"userId":1}`,
+			status:       http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("LeaveTournament", int64(1), int64(1)).Return(nil)
+	db.On("LeaveTournament", int64(1), int64(2)).Return(sts.ErrNotParticipant)
+	db.On("LeaveTournament", int64(2), int64(1)).Return(sts.ErrTournamentClosed)
+	db.On("LeaveTournament", int64(100), int64(1)).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("DELETE",
+				fmt.Sprintf("%s/tournament/%s/join", server.URL, tc.tournamentID),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGetUserTransactions(t *testing.T) {
+	tt := []struct {
+		name     string
+		id       string
+		query    string
+		response string
+		status   int
+	}{
+		{
+			name:  "correct test",
+			id:    "1",
+			query: "?before=10&limit=3",
+			response: `[{"id":9,"userId":1,"amount":-100,"currency":"bonus","reason":"entry_fee","tournamentId":2,` +
+				`"createdAt":"2019-08-19T12:00:00Z"},` +
+				`{"id":7,"userId":1,"amount":-50,"currency":"bonus","reason":"transfer","peerId":3,` +
+				`"createdAt":"2019-08-18T18:00:00Z"},` +
+				`{"id":5,"userId":1,"amount":300,"currency":"premium","reason":"fund",` +
+				`"createdAt":"2019-08-18T12:00:00Z"}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:     "default page",
+			id:       "2",
+			response: `[]`,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "incorrect limit",
+			id:     "1",
+			query:  "?limit=1000",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "uncreated user",
+			id:     "100",
+			status: http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetUserTransactions", int64(1), int64(10), uint64(3)).Return([]sts.Transaction{
+		{
+			ID:           9,
+			UserID:       1,
+			Amount:       -100,
+			Currency:     sts.DefaultCurrency,
+			Reason:       sts.ReasonEntryFee,
+			TournamentID: 2,
+			CreatedAt:    time.Date(2019, 8, 19, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:        7,
+			UserID:    1,
+			Amount:    -50,
+			Currency:  sts.DefaultCurrency,
+			Reason:    sts.ReasonTransfer,
+			PeerID:    3,
+			CreatedAt: time.Date(2019, 8, 18, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:        5,
+			UserID:    1,
+			Amount:    300,
+			Currency:  "premium",
+			Reason:    sts.ReasonFund,
+			CreatedAt: time.Date(2019, 8, 18, 12, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+	db.On("GetUserTransactions", int64(2), int64(0), uint64(20)).Return([]sts.Transaction{}, nil)
+	db.On("GetUserTransactions", int64(100), int64(0), uint64(20)).Return([]sts.Transaction(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET",
+				fmt.Sprintf("%s/user/%s/transactions%s", server.URL, tc.id, tc.query), nil)
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestGetHouseAccount(t *testing.T) {
+	tt := []struct {
+		name     string
+		account  string
+		response string
+		status   int
+	}{
+		{
+			name:     "correct test",
+			account:  "rake",
+			response: `{"name":"rake","currency":"bonus","balance":350}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:    "unknown account",
+			account: "casino",
+			status:  http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetHouseAccount", "rake", sts.DefaultCurrency).Return(&sts.HouseAccount{
+		Name:     "rake",
+		Currency: sts.DefaultCurrency,
+		Balance:  350,
+	}, nil)
+	db.On("GetHouseAccount", "casino", sts.DefaultCurrency).Return((*sts.HouseAccount)(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/house/%s", server.URL, tc.account), nil)
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestFundHouseAccount(t *testing.T) {
+	tt := []struct {
+		name    string
+		account string
+		request string
+		status  int
+	}{
+		{
+			name:    "correct test",
+			account: "sponsor",
+			request: `{"points":5000}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "unknown account",
+			account: "casino",
+			request: `{"points":5000}`,
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "negative points",
+			account: "sponsor",
+			request: `{"points":-5000}`,
+			status:  http.StatusBadRequest,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("FundHouseAccount", "sponsor", sts.DefaultCurrency, uint64(5000)).Return(nil)
+	db.On("FundHouseAccount", "casino", sts.DefaultCurrency, uint64(5000)).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(fmt.Sprintf("%s/house/%s/fund", server.URL, tc.account), "application/json",
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGenerateBracket(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		request      string
+		status       int
+	}{
+		{
+			name:         "random seeding",
+			tournamentID: "1",
+			status:       http.StatusOK,
+		},
+		{
+			name:         "seeding",
+			tournamentID: "1",
+			request:      `{"seeding":[3,2,1]}`,
+			status:       http.StatusOK,
+		},
+		{
+			name:         "incorrect seeding",
+			tournamentID: "1",
+			request:      `{"seeding":[3]}`,
+			status:       http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "existing bracket",
+			tournamentID: "2",
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			status:       http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GenerateBracket", int64(1), []int64(nil)).Return(nil)
+	db.On("GenerateBracket", int64(1), []int64{3, 2, 1}).Return(nil)
+	db.On("GenerateBracket", int64(1), []int64{3}).Return(sts.ErrInvalidSeeding)
+	db.On("GenerateBracket", int64(2), []int64(nil)).Return(sts.ErrBracketExists)
+	db.On("GenerateBracket", int64(100), []int64(nil)).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/%s/bracket", server.URL, tc.tournamentID),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGetBracket(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		response     string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			response: `[{"round":1,"position":1,"user1":1,"user2":2,"winner":2},` +
+				`{"round":1,"position":2,"user1":3,"winner":3},{"round":2,"position":1,"user1":2,"user2":3}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			status:       http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetBracket", int64(1)).Return(sts.Bracket{
+		{Round: 1, Position: 1, User1: 1, User2: 2, Winner: 2},
+		{Round: 1, Position: 2, User1: 3, Winner: 3},
+		{Round: 2, Position: 1, User1: 2, User2: 3},
+	}, nil)
+	db.On("GetBracket", int64(100)).Return(sts.Bracket(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET",
+				fmt.Sprintf("%s/tournament/%s/bracket", server.URL, tc.tournamentID), nil)
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestReportMatch(t *testing.T) {
+	tt := []struct {
+		name    string
+		match   string
+		request string
+		status  int
+	}{
+		{
+			name:    "correct test",
+			match:   "1/1",
+			request: `{"winner":2}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "match isn't ready",
+			match:   "2/1",
+			request: `{"winner":2}`,
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "winner doesn't play",
+			match:   "1/2",
+			request: `{"winner":2}`,
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "incorrect position",
+			match:   "1/0",
+			request: `{"winner":2}`,
+			status:  http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("ReportMatch", int64(1), uint32(1), uint32(1), int64(2)).Return(nil)
+	db.On("ReportMatch", int64(1), uint32(2), uint32(1), int64(2)).Return(sts.ErrMatchNotReady)
+	db.On("ReportMatch", int64(1), uint32(1), uint32(2), int64(2)).Return(sts.ErrNotParticipant)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/1/bracket/%s", server.URL, tc.match),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestPairRound(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		response     string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			response: `[{"round":2,"board":1,"user1":1,"user2":3},` +
+				`{"round":2,"board":2,"user1":2,"result":"win"}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:         "round isn't finished",
+			tournamentID: "2",
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "knockout tournament",
+			tournamentID: "3",
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "uncreated tournament",
+			tournamentID: "100",
+			status:       http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("PairRound", int64(1)).Return(sts.Pairings{
+		{Round: 2, Board: 1, User1: 1, User2: 3},
+		{Round: 2, Board: 2, User1: 2, Result: sts.ResultWin},
+	}, nil)
+	db.On("PairRound", int64(2)).Return(sts.Pairings(nil), sts.ErrRoundNotFinished)
+	db.On("PairRound", int64(3)).Return(sts.Pairings(nil), sts.ErrUnsupportedFormat)
+	db.On("PairRound", int64(100)).Return(sts.Pairings(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/%s/rounds", server.URL, tc.tournamentID), nil)
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestReportResult(t *testing.T) {
+	tt := []struct {
+		name    string
+		game    string
+		request string
+		status  int
+	}{
+		{
+			name:    "correct test",
+			game:    "1/1",
+			request: `{"result":"draw"}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "incorrect result",
+			game:    "1/1",
+			request: `{"result":"tie"}`,
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "reported twice",
+			game:    "1/2",
+			request: `{"result":"win"}`,
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "unknown game",
+			game:    "5/1",
+			request: `{"result":"loss"}`,
+			status:  http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("ReportResult", int64(1), uint32(1), uint32(1), sts.ResultDraw).Return(nil)
+	db.On("ReportResult", int64(1), uint32(1), uint32(1), sts.Result("tie")).Return(sts.ErrInvalidResult)
+	db.On("ReportResult", int64(1), uint32(1), uint32(2), sts.ResultWin).Return(sts.ErrMatchReported)
+	db.On("ReportResult", int64(1), uint32(5), uint32(1), sts.ResultLoss).Return(sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST",
+				fmt.Sprintf("%s/tournament/1/pairings/%s", server.URL, tc.game),
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGetStandings(t *testing.T) {
+	db := new(mockdb.Connector)
+	db.On("GetStandings", int64(1)).Return([]sts.Standing{
+		{UserID: 2, Points: 1.5, Buchholz: 0.5, Wins: 1, Draws: 1},
+		{UserID: 1, Points: 0.5, Buchholz: 1.5, Draws: 1, Losses: 1},
+	}, nil)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	resp, err := http.Get(fmt.Sprintf("%s/tournament/1/standings", server.URL))
+	if err != nil {
+		t.Fatalf("couldnt get response: %s", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %v; got %v", http.StatusOK, resp.StatusCode)
+	}
+	expected := `[{"userId":2,"points":1.5,"buchholz":0.5,"wins":1,"draws":1,"losses":0},` +
+		`{"userId":1,"points":0.5,"buchholz":1.5,"wins":0,"draws":1,"losses":1}]`
+	if respBody := string(bytes.TrimSpace(b)); respBody != expected {
+		t.Fatalf("expected %s, got %s", expected, respBody)
+	}
+}
+
+func TestGetRatingHistory(t *testing.T) {
+	tt := []struct {
+		name     string
+		userID   string
+		response string
+		status   int
+	}{
+		{
+			name:   "correct test",
+			userID: "1",
+			response: `[{"id":1,"userId":1,"tournamentId":2,"rating":1662.3,"ratingDeviation":290.3,` +
+				`"createdAt":"2019-10-07T12:00:00Z"}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "uncreated user",
+			userID: "100",
+			status: http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetRatingHistory", int64(1)).Return([]sts.RatingChange{
+		{
+			ID:           1,
+			UserID:       1,
+			TournamentID: 2,
+			Rating:       1662.3,
+			Deviation:    290.3,
+			CreatedAt:    time.Date(2019, 10, 7, 12, 0, 0, 0, time.UTC),
+		},
+	}, nil)
+	db.On("GetRatingHistory", int64(100)).Return([]sts.RatingChange(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/user/%s/ratings", server.URL, tc.userID))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		response string
+		status   int
+	}{
+		{
+			name:  "correct test",
+			query: "?period=month&order=wins&offset=10&limit=2&userId=7",
+			response: `{"entries":[{"rank":11,"userId":3,"prize":500,"wins":4,"tournaments":6,"profit":200},` +
+				`{"rank":11,"userId":5,"prize":300,"wins":4,"tournaments":9,"profit":-150}],` +
+				`"me":{"rank":40,"userId":7,"prize":0,"wins":1,"tournaments":2,"profit":-100}}`,
+			status: http.StatusOK,
+		},
+		{
+			name:     "default query",
+			response: `{"entries":[]}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "incorrect period",
+			query:  "?period=year",
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "incorrect limit",
+			query:  "?limit=1000",
+			status: http.StatusBadRequest,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetLeaderboard", sts.LeaderboardQuery{
+		Period: sts.PeriodMonth,
+		Order:  sts.OrderWins,
+		Offset: 10,
+		Limit:  2,
+		UserID: 7,
+	}).Return(&sts.Leaderboard{
+		Entries: []sts.LeaderboardEntry{
+			{Rank: 11, UserID: 3, Prize: 500, Wins: 4, Tournaments: 6, Profit: 200},
+			{Rank: 11, UserID: 5, Prize: 300, Wins: 4, Tournaments: 9, Profit: -150},
+		},
+		Me: &sts.LeaderboardEntry{Rank: 40, UserID: 7, Wins: 1, Tournaments: 2, Profit: -100},
+	}, nil)
+	db.On("GetLeaderboard", sts.LeaderboardQuery{
+		Limit: 20,
+	}).Return(&sts.Leaderboard{
+		Entries: []sts.LeaderboardEntry{},
+	}, nil)
+	db.On("GetLeaderboard", sts.LeaderboardQuery{
+		Period: "year",
+		Limit:  20,
+	}).Return((*sts.Leaderboard)(nil), sts.ErrInvalidLeaderboard)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/leaderboard%s", server.URL, tc.query))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestListTournaments(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		response string
+		status   int
+	}{
+		{
+			name: "correct test",
+			query: "?status=registration&minDeposit=10&maxDeposit=100&name=Fri&createdAfter=2019-12-01T00:00:00Z" +
+				"&sort=prize&after=MTAwOjM&limit=1&viewerId=3",
+			response: `{"tournaments":[{"id":2,"name":"Friday","deposit":50,` +
+				`"status":"registration","createdAt":"2019-12-02T10:00:00Z","grossPrize":0,"prize":90,"winner":0,` +
+				`"users":null,"waitlist":null,"underfilled":false,"payouts":null}],"nextCursor":"OTA6Mg"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:     "default query",
+			response: `{"tournaments":[]}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "incorrect sort",
+			query:  "?sort=oldest",
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "incorrect viewer id",
+			query:  "?viewerId=0",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "incorrect deposit",
+			query:  "?minDeposit=-1",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "incorrect creation time",
+			query:  "?createdBefore=yesterday",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "incorrect limit",
+			query:  "?limit=0",
+			status: http.StatusBadRequest,
+		},
+	}
+	createdAfter := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	db := new(mockdb.Connector)
+	db.On("ListTournaments", sts.TournamentQuery{
+		ViewerID:     3,
+		Status:       sts.StatusRegistration,
+		MinDeposit:   10,
+		MaxDeposit:   100,
+		NamePrefix:   "Fri",
+		CreatedAfter: &createdAfter,
+		Sort:         sts.SortLargestPrize,
+		After:        "MTAwOjM",
+		Limit:        1,
+	}).Return(&sts.TournamentPage{
+		Tournaments: []sts.Tournament{{
+			ID: 2,
+			TournamentSettings: sts.TournamentSettings{
+				Name:    "Friday",
+				Deposit: 50,
+			},
+			Status:    sts.StatusRegistration,
+			CreatedAt: time.Date(2019, 12, 2, 10, 0, 0, 0, time.UTC),
+			Prize:     90,
+		}},
+		NextCursor: "OTA6Mg",
+	}, nil)
+	db.On("ListTournaments", sts.TournamentQuery{
+		Limit: 20,
+	}).Return(&sts.TournamentPage{
+		Tournaments: []sts.Tournament{},
+	}, nil)
+	db.On("ListTournaments", sts.TournamentQuery{
+		Sort:  "oldest",
+		Limit: 20,
+	}).Return((*sts.TournamentPage)(nil), sts.ErrInvalidListing)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/tournament%s", server.URL, tc.query))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		response string
+		status   int
+	}{
+		{
+			name:  "correct test",
+			query: "?after=Mzoz&limit=1",
+			response: `{"users":[{"id":4,"name":"ilya","balances":null,"rating":1500,"ratingDeviation":350,` +
+				`"games":0,"expirations":null}],"nextCursor":"NDo0"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "malformed cursor",
+			query:  "?after=!",
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "incorrect limit",
+			query:  "?limit=many",
+			status: http.StatusBadRequest,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("ListUsers", sts.UserQuery{
+		After: "Mzoz",
+		Limit: 1,
+	}).Return(&sts.UserPage{
+		Users:      []sts.User{{ID: 4, Name: "ilya", Rating: 1500, RatingDeviation: 350}},
+		NextCursor: "NDo0",
+	}, nil)
+	db.On("ListUsers", sts.UserQuery{
+		After: "!",
+		Limit: 20,
+	}).Return((*sts.UserPage)(nil), sts.ErrInvalidListing)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/user%s", server.URL, tc.query))
+			if err != nil {
+				t.Fatalf("couldnt get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTeam(t *testing.T) {
+	tt := []struct {
+		name     string
+		request  string
+		status   int
+		response string
+	}{
+		{
+			name:     "correct test",
+			request:  `{"name":"team","captainId":1}`,
+			status:   http.StatusOK,
+			response: `{"id":1}`,
+		},
+		{
+			name:    "unknown captain",
+			request: `{"name":"team","captainId":2}`,
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "incorrect json",
+			request: `{"name":`,
+			status:  http.StatusBadRequest,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("CreateTeam", "team", int64(1)).Return(int64(1), nil)
+	db.On("CreateTeam", "team", int64(2)).Return(int64(0), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/team", "application/json", strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.response == "" {
+				return
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if strings.TrimSpace(string(body)) != tc.response {
+				t.Fatalf("expected response %s; got %s", tc.response, body)
+			}
+		})
+	}
+}
+
+func TestGetTeam(t *testing.T) {
+	tt := []struct {
+		name     string
+		id       string
+		status   int
+		response string
+	}{
+		{
+			name:   "correct test",
+			id:     "1",
+			status: http.StatusOK,
+			response: `{"id":1,"name":"team","captainId":1,"members":[{"userId":1,"share":60},` +
+				`{"userId":2,"share":40}],"invited":[3]}`,
+		},
+		{
+			name:   "unknown team",
+			id:     "2",
+			status: http.StatusNotFound,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetTeam", int64(1)).Return(&sts.Team{
+		ID:        1,
+		Name:      "team",
+		CaptainID: 1,
+		Members:   []sts.TeamMember{{UserID: 1, Share: 60}, {UserID: 2, Share: 40}},
+		Invited:   []int64{3},
+	}, nil)
+	db.On("GetTeam", int64(2)).Return((*sts.Team)(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/team/%s", server.URL, tc.id))
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.response == "" {
+				return
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if strings.TrimSpace(string(body)) != tc.response {
+				t.Fatalf("expected response %s; got %s", tc.response, body)
+			}
+		})
+	}
+}
+
+func TestJoinTeam(t *testing.T) {
+	tt := []struct {
+		name    string
+		action  string
+		request string
+		status  int
+	}{
+		{
+			name:    "invite",
+			action:  "invite",
+			request: `{"userId":2}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "invite unknown user",
+			action:  "invite",
+			request: `{"userId":3}`,
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "join",
+			action:  "join",
+			request: `{"userId":2}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "join without invitation",
+			action:  "join",
+			request: `{"userId":4}`,
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "join full team",
+			action:  "join",
+			request: `{"userId":5}`,
+			status:  http.StatusConflict,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("InviteToTeam", int64(1), int64(2)).Return(nil)
+	db.On("InviteToTeam", int64(1), int64(3)).Return(sts.ErrNotFound)
+	db.On("JoinTeam", int64(1), int64(2)).Return(nil)
+	db.On("JoinTeam", int64(1), int64(4)).Return(sts.ErrNotInvited)
+	db.On("JoinTeam", int64(1), int64(5)).Return(sts.ErrTeamFull)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(fmt.Sprintf("%s/team/1/%s", server.URL, tc.action), "application/json",
+				strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestSetTeamShares(t *testing.T) {
+	tt := []struct {
+		name    string
+		request string
+		status  int
+	}{
+		{
+			name:    "correct test",
+			request: `[{"userId":1,"share":70},{"userId":2,"share":30}]`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "invalid shares",
+			request: `[{"userId":1,"share":70}]`,
+			status:  http.StatusUnprocessableEntity,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("SetTeamShares", int64(1), []sts.TeamMember{{UserID: 1, Share: 70}, {UserID: 2, Share: 30}}).Return(nil)
+	db.On("SetTeamShares", int64(1), []sts.TeamMember{{UserID: 1, Share: 70}}).Return(sts.ErrInvalidTeamShares)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PUT", server.URL+"/team/1/shares", strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCreateInviteCode(t *testing.T) {
+	tt := []struct {
+		name         string
+		tournamentID string
+		request      string
+		response     string
+		status       int
+	}{
+		{
+			name:         "correct test",
+			tournamentID: "1",
+			request:      `{"organizerId":1,"maxUses":10}`,
+			response: `{"code":"abc","tournamentId":1,"maxUses":10,"uses":0,"revoked":false,` +
+				`"createdAt":"2019-10-28T12:00:00Z"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:         "not organizer",
+			tournamentID: "1",
+			request:      `{"organizerId":2}`,
+			status:       http.StatusForbidden,
+		},
+		{
+			name:         "public tournament",
+			tournamentID: "2",
+			request:      `{"organizerId":1}`,
+			status:       http.StatusConflict,
+		},
+		{
+			name:         "unknown tournament",
+			tournamentID: "100",
+			request:      `{"organizerId":1}`,
+			status:       http.StatusNotFound,
+		},
+		{
+			name:         "incorrect request",
+			tournamentID: "1",
+			request:      `{  :  }`,
+			status:       http.StatusBadRequest,
+		},
+	}
+	created := time.Date(2019, 10, 28, 12, 0, 0, 0, time.UTC)
+	db := new(mockdb.Connector)
+	db.On("CreateInviteCode", int64(1), int64(1), sts.InviteSettings{MaxUses: 10}).Return(&sts.InviteCode{
+		Code:         "abc",
+		TournamentID: 1,
+		MaxUses:      10,
+		CreatedAt:    created,
+	}, nil)
+	db.On("CreateInviteCode", int64(1), int64(2), sts.InviteSettings{}).
+		Return((*sts.InviteCode)(nil), sts.ErrNotOrganizer)
+	db.On("CreateInviteCode", int64(2), int64(1), sts.InviteSettings{}).
+		Return((*sts.InviteCode)(nil), sts.ErrPublicTournament)
+	db.On("CreateInviteCode", int64(100), int64(1), sts.InviteSettings{}).
+		Return((*sts.InviteCode)(nil), sts.ErrNotFound)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(fmt.Sprintf("%s/tournament/%s/invites", server.URL, tc.tournamentID),
+				"application/json", strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("could not read response: %v", err)
+			}
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusOK {
+				if respBody := string(bytes.TrimSpace(b)); tc.response != respBody {
+					t.Fatalf("expected %s, got %s", tc.response, respBody)
+				}
+			}
+		})
+	}
+}
+
+func TestGetInviteCodes(t *testing.T) {
+	tt := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{
+			name:   "correct test",
+			query:  "?organizerId=1",
+			status: http.StatusOK,
+		},
+		{
+			name:   "not organizer",
+			query:  "?organizerId=2",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "missing organizer",
+			status: http.StatusBadRequest,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("GetInviteCodes", int64(1), int64(1)).Return([]sts.InviteCode{{Code: "abc", TournamentID: 1}}, nil)
+	db.On("GetInviteCodes", int64(1), int64(2)).Return([]sts.InviteCode(nil), sts.ErrNotOrganizer)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/tournament/1/invites" + tc.query)
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRevokeInviteCode(t *testing.T) {
+	tt := []struct {
+		name    string
+		method  string
+		path    string
+		request string
+		status  int
+	}{
+		{
+			name:    "revoke",
+			method:  http.MethodDelete,
+			path:    "/tournament/1/invites/abc",
+			request: `{"organizerId":1}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "revoke unknown code",
+			method:  http.MethodDelete,
+			path:    "/tournament/1/invites/xyz",
+			request: `{"organizerId":1}`,
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "regenerate",
+			method:  http.MethodPost,
+			path:    "/tournament/1/invites/abc/regenerate",
+			request: `{"organizerId":1}`,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "regenerate by stranger",
+			method:  http.MethodPost,
+			path:    "/tournament/1/invites/abc/regenerate",
+			request: `{"organizerId":2}`,
+			status:  http.StatusForbidden,
+		},
+	}
+	db := new(mockdb.Connector)
+	db.On("RevokeInviteCode", int64(1), int64(1), "abc").Return(nil)
+	db.On("RevokeInviteCode", int64(1), int64(1), "xyz").Return(sts.ErrNotFound)
+	db.On("RegenerateInviteCode", int64(1), int64(1), "abc").Return(&sts.InviteCode{Code: "def", TournamentID: 1}, nil)
+	db.On("RegenerateInviteCode", int64(1), int64(2), "abc").Return((*sts.InviteCode)(nil), sts.ErrNotOrganizer)
+	s := New(db)
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.request))
+			if err != nil {
+				t.Fatalf("could not create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("couldn't get response: %s", err)
+			}
+			defer resp.Body.Close()
+			if tc.status != resp.StatusCode {
+				t.Fatalf("expected status %v; got %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tt := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			name:   "insufficient funds",
+			err:    sts.ErrInsufficientFunds,
+			status: http.StatusPaymentRequired,
+			body:   `{"code":"insufficient_funds","message":"couldn't update user: user doesn't have enough points"}`,
+		},
+		{
+			name:   "already joined",
+			err:    sts.ErrAlreadyJoined,
+			status: http.StatusConflict,
+			body:   `{"code":"already_joined","message":"couldn't update user: user has already joined tournament"}`,
+		},
+		{
+			name:   "validation",
+			err:    sts.ErrValidation,
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code":"validation","message":"couldn't update user: data breaks validation rules"}`,
+		},
+		{
+			name:   "unknown",
+			err:    sql.ErrConnDone,
+			status: http.StatusInternalServerError,
+			body:   `{"code":"internal","message":"couldn't update user: sql: connection is already closed"}`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, tc.err, "couldn't update user")
+			if tc.status != w.Code {
+				t.Fatalf("expected status %v; got %v", tc.status, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Fatalf("expected content type application/json; got %v", contentType)
+			}
+			if body := strings.TrimSpace(w.Body.String()); tc.body != body {
+				t.Fatalf("expected %s, got %s", tc.body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/illfate/social-tournaments-service/pkg/sts"
+)
+
+func (s *Server) CreateTeam(w http.ResponseWriter, req *http.Request) {
+	var team sts.Team
+	err := json.NewDecoder(req.Body).Decode(&team)
+	if err != nil {
+		badRequest(w, "couldn't decode json: %s", err)
+		return
+	}
+	team.ID, err = s.service.CreateTeam(req.Context(), team.Name, team.CaptainID)
+	if err != nil {
+		writeError(w, err, "couldn't create team")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(struct {
+		ID int64 `json:"id"`
+	}{
+		ID: team.ID,
+	})
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) GetTeam(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	team, err := s.service.GetTeam(req.Context(), id)
+	if err != nil {
+		writeError(w, err, "couldn't get team")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(team)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) InviteToTeam(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	teamID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	user := struct {
+		ID int64 `json:"userId"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&user)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.InviteToTeam(req.Context(), teamID, user.ID)
+	if err != nil {
+		writeError(w, err, "couldn't invite user to team")
+		return
+	}
+}
+
+func (s *Server) JoinTeam(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	teamID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	user := struct {
+		ID int64 `json:"userId"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&user)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.JoinTeam(req.Context(), teamID, user.ID)
+	if err != nil {
+		writeError(w, err, "couldn't join team")
+		return
+	}
+}
+
+func (s *Server) SetTeamShares(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	teamID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	var shares []sts.TeamMember
+	err = json.NewDecoder(req.Body).Decode(&shares)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.SetTeamShares(req.Context(), teamID, shares)
+	if err != nil {
+		writeError(w, err, "couldn't set team shares")
+		return
+	}
+}
@@ -5,29 +5,29 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/illfate/social-tournaments-service/pkg/sts"
 )
 
 func (s *Server) AddTournament(w http.ResponseWriter, req *http.Request) {
-	var t sts.Tournament
-	err := json.NewDecoder(req.Body).Decode(&t)
+	var settings sts.TournamentSettings
+	err := json.NewDecoder(req.Body).Decode(&settings)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "couldn't decode json: %s", err)
+		badRequest(w, "couldn't decode json: %s", err)
 		return
 	}
-	t.ID, err = s.service.AddTournament(req.Context(), t.Name, t.Deposit)
+	id, err := s.service.AddTournament(req.Context(), settings)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "couldn't add tournament: %s", err)
+		writeError(w, err, "couldn't add tournament")
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(struct {
 		ID int64 `json:"id"`
 	}{
-		ID: t.ID,
+		ID: id,
 	})
 	if err != nil {
 		w.Header().Set("Content-Type", "text/plain")
@@ -41,23 +41,118 @@ func (s *Server) GetTournament(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "incorrect id: %s", err)
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
 	t, err := s.service.GetTournament(req.Context(), id)
-	if err == sts.ErrNotFound {
-		w.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(w, "couldn't get tournament: %s", err)
+	if err != nil {
+		writeError(w, err, "couldn't get tournament")
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(t)
 	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "couldn't get tournament: %s", err)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+// UpdateTournament applies a JSON merge patch to settings of tournament. Null clears schedule
+// times, other settings can't be null.
+func (s *Server) UpdateTournament(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	var patch map[string]json.RawMessage
+	err = json.NewDecoder(req.Body).Decode(&patch)
+	if err != nil {
+		badRequest(w, "couldn't decode json: %s", err)
+		return
+	}
+	var update sts.TournamentUpdate
+	schedule := map[string]**time.Time{
+		"registrationOpensAt":  &update.RegistrationOpensAt,
+		"registrationClosesAt": &update.RegistrationClosesAt,
+		"startsAt":             &update.StartsAt,
+	}
+	fields := map[string]interface{}{
+		"name":            &update.Name,
+		"deposit":         &update.Deposit,
+		"currency":        &update.Currency,
+		"prizeShares":     &update.PrizeShares,
+		"rakeType":        &update.RakeType,
+		"rake":            &update.Rake,
+		"minPlayers":      &update.MinPlayers,
+		"maxPlayers":      &update.MaxPlayers,
+		"format":          &update.Format,
+		"rounds":          &update.Rounds,
+		"minRating":       &update.MinRating,
+		"maxRating":       &update.MaxRating,
+		"teamBased":       &update.TeamBased,
+		"visibility":      &update.Visibility,
+		"organizerId":     &update.OrganizerID,
+		"guaranteedPrize": &update.GuaranteedPrize,
+		"sponsor":         &update.Sponsor,
+	}
+	for key, value := range patch {
+		if field, ok := schedule[key]; ok {
+			var v *time.Time
+			err = json.Unmarshal(value, &v)
+			if err != nil {
+				badRequest(w, "incorrect %s: %s", key, err)
+				return
+			}
+			if v == nil {
+				v = new(time.Time)
+			}
+			*field = v
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			badRequest(w, "unknown field: %s", key)
+			return
+		}
+		if string(value) == "null" {
+			badRequest(w, "%s can't be null", key)
+			return
+		}
+		err = json.Unmarshal(value, field)
+		if err != nil {
+			badRequest(w, "incorrect %s: %s", key, err)
+			return
+		}
+	}
+	err = s.service.UpdateTournament(req.Context(), id, update)
+	if err != nil {
+		writeError(w, err, "couldn't update tournament")
+		return
+	}
+}
+
+func (s *Server) CloneTournament(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	cloneID, err := s.service.CloneTournament(req.Context(), id)
+	if err != nil {
+		writeError(w, err, "couldn't clone tournament")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(t)
+	err = json.NewEncoder(w).Encode(struct {
+		ID int64 `json:"id"`
+	}{
+		ID: cloneID,
+	})
 	if err != nil {
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -70,8 +165,47 @@ func (s *Server) JoinTournament(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "incorrect id: %s", err)
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	entry := struct {
+		ID         int64  `json:"userId"`
+		TeamID     int64  `json:"teamId"`
+		InviteCode string `json:"inviteCode"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&entry)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	if entry.TeamID != 0 {
+		s.joinTournamentAsTeam(w, req, tournamentID, entry.TeamID, entry.InviteCode)
+		return
+	}
+	waitlisted, err := s.service.JoinTournament(idempotent(req), tournamentID, entry.ID, entry.InviteCode)
+	if err != nil {
+		writeError(w, err, "couldn't join tournament")
+		return
+	}
+	if waitlisted {
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (s *Server) joinTournamentAsTeam(w http.ResponseWriter, req *http.Request, tournamentID, teamID int64,
+	inviteCode string) {
+	err := s.service.JoinTournamentAsTeam(idempotent(req), tournamentID, teamID, inviteCode)
+	if err != nil {
+		writeError(w, err, "couldn't join tournament")
+		return
+	}
+}
+
+func (s *Server) LeaveTournament(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
 	user := struct {
@@ -79,19 +213,124 @@ func (s *Server) JoinTournament(w http.ResponseWriter, req *http.Request) {
 	}{}
 	err = json.NewDecoder(req.Body).Decode(&user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "can't decode json: %s", err)
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.LeaveTournament(req.Context(), tournamentID, user.ID)
+	if err != nil {
+		writeError(w, err, "couldn't leave tournament")
+		return
+	}
+}
+
+func (s *Server) FinishTournament(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	result := struct {
+		Ranking []int64 `json:"ranking"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&result)
+	if err != nil {
+		badRequest(w, "can't decode json: %s", err)
+		return
+	}
+	err = s.service.FinishTournament(req.Context(), tournamentID, result.Ranking)
+	if err != nil {
+		writeError(w, err, "couldn't finish tournament")
 		return
 	}
-	err = s.service.JoinTournament(req.Context(), tournamentID, user.ID)
-	if err == sts.ErrNotFound {
-		w.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(w, "couldn't join tournament: %s", err)
+}
+
+func (s *Server) ChangeTournamentStatus(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	tournamentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
+	switch vars["action"] {
+	case "open":
+		err = s.service.OpenRegistration(req.Context(), tournamentID)
+	case "start":
+		err = s.service.StartTournament(req.Context(), tournamentID)
+	case "cancel":
+		err = s.service.CancelTournament(req.Context(), tournamentID)
+	}
 	if err != nil {
+		writeError(w, err, "couldn't change tournament status")
+		return
+	}
+}
+
+func (s *Server) ListTournaments(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	q := sts.TournamentQuery{
+		Status:     sts.Status(query.Get("status")),
+		NamePrefix: query.Get("name"),
+		Sort:       sts.TournamentSort(query.Get("sort")),
+		After:      query.Get("after"),
+		Limit:      defaultPageLimit,
+	}
+	var err error
+	if viewer := query.Get("viewerId"); viewer != "" {
+		q.ViewerID, err = strconv.ParseInt(viewer, 10, 64)
+		if err != nil || q.ViewerID < 1 {
+			badRequest(w, "incorrect viewer id: %s", viewer)
+			return
+		}
+	}
+	if minDeposit := query.Get("minDeposit"); minDeposit != "" {
+		q.MinDeposit, err = strconv.ParseUint(minDeposit, 10, 64)
+		if err != nil {
+			badRequest(w, "incorrect min deposit: %s", minDeposit)
+			return
+		}
+	}
+	if maxDeposit := query.Get("maxDeposit"); maxDeposit != "" {
+		q.MaxDeposit, err = strconv.ParseUint(maxDeposit, 10, 64)
+		if err != nil {
+			badRequest(w, "incorrect max deposit: %s", maxDeposit)
+			return
+		}
+	}
+	if createdAfter := query.Get("createdAfter"); createdAfter != "" {
+		t, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			badRequest(w, "incorrect created after: %s", createdAfter)
+			return
+		}
+		q.CreatedAfter = &t
+	}
+	if createdBefore := query.Get("createdBefore"); createdBefore != "" {
+		t, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			badRequest(w, "incorrect created before: %s", createdBefore)
+			return
+		}
+		q.CreatedBefore = &t
+	}
+	if l := query.Get("limit"); l != "" {
+		q.Limit, err = strconv.ParseUint(l, 10, 64)
+		if err != nil || q.Limit == 0 || q.Limit > maxPageLimit {
+			badRequest(w, "incorrect limit: %s", l)
+			return
+		}
+	}
+	page, err := s.service.ListTournaments(req.Context(), q)
+	if err != nil {
+		writeError(w, err, "couldn't list tournaments")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "couldn't join tournament: %s", err)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
 		return
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/illfate/social-tournaments-service/pkg/sts"
@@ -14,14 +15,12 @@ func (s *Server) AddUser(w http.ResponseWriter, req *http.Request) {
 	var user sts.User
 	err := json.NewDecoder(req.Body).Decode(&user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "couldn't decode json: %s", err)
+		badRequest(w, "couldn't decode json: %s", err)
 		return
 	}
 	user.ID, err = s.service.AddUser(req.Context(), user.Name)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "couldn't add user: %s", err)
+		writeError(w, err, "couldn't add user")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -42,17 +41,12 @@ func (s *Server) GetUser(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "incorrect id: %s", err)
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
 	user, err := s.service.GetUser(req.Context(), id)
-	if err == sts.ErrNotFound {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, err, "couldn't get user")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -69,17 +63,86 @@ func (s *Server) DeleteUser(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "incorrect id: %s", err)
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
 	err = s.service.DeleteUser(req.Context(), id)
-	if err == sts.ErrNotFound {
-		w.WriteHeader(http.StatusNotFound)
+	if err != nil {
+		writeError(w, err, "couldn't delete user")
 		return
 	}
+}
+
+// UpdateUser applies a JSON merge patch to profile of user. Null clears a field.
+func (s *Server) UpdateUser(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	var patch map[string]json.RawMessage
+	err = json.NewDecoder(req.Body).Decode(&patch)
+	if err != nil {
+		badRequest(w, "couldn't decode json: %s", err)
+		return
+	}
+	var update sts.UserUpdate
+	fields := map[string]**string{
+		"name":        &update.Name,
+		"displayName": &update.DisplayName,
+		"avatarUrl":   &update.AvatarURL,
+		"country":     &update.Country,
+		"bio":         &update.Bio,
+	}
+	for key, value := range patch {
+		field, ok := fields[key]
+		if !ok {
+			badRequest(w, "unknown field: %s", key)
+			return
+		}
+		var v *string
+		err = json.Unmarshal(value, &v)
+		if err != nil {
+			badRequest(w, "incorrect %s: %s", key, err)
+			return
+		}
+		if v == nil {
+			v = new(string)
+		}
+		*field = v
+	}
+	err = s.service.UpdateUser(req.Context(), id, update)
+	if err != nil {
+		writeError(w, err, "couldn't update user")
+		return
+	}
+}
+
+func (s *Server) ListUsers(w http.ResponseWriter, req *http.Request) {
+	q := sts.UserQuery{
+		After: req.URL.Query().Get("after"),
+		Limit: defaultPageLimit,
+	}
+	if l := req.URL.Query().Get("limit"); l != "" {
+		var err error
+		q.Limit, err = strconv.ParseUint(l, 10, 64)
+		if err != nil || q.Limit == 0 || q.Limit > maxPageLimit {
+			badRequest(w, "incorrect limit: %s", l)
+			return
+		}
+	}
+	page, err := s.service.ListUsers(req.Context(), q)
+	if err != nil {
+		writeError(w, err, "couldn't list users")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
 		return
 	}
 }
@@ -88,32 +151,131 @@ func (s *Server) AddPoints(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "incorrect id: %s", err)
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
 	bonus := struct {
-		Points int64 `json:"points"`
+		Points   uint64       `json:"points"`
+		Currency sts.Currency `json:"currency"`
+		// ExpiresIn is a duration like "720h" after which funded points expire.
+		ExpiresIn string `json:"expiresIn"`
 	}{}
 	err = json.NewDecoder(req.Body).Decode(&bonus)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "couldn't decode json: %s", err)
+		badRequest(w, "couldn't decode json: %s", err)
 		return
 	}
+	if bonus.Currency == "" {
+		bonus.Currency = sts.DefaultCurrency
+	}
+	var expiresIn time.Duration
+	if bonus.ExpiresIn != "" {
+		expiresIn, err = time.ParseDuration(bonus.ExpiresIn)
+		if err != nil {
+			badRequest(w, "incorrect expiry: %s", err)
+			return
+		}
+	}
 	if vars["action"] == "take" {
-		bonus.Points = -bonus.Points
+		err = s.service.Debit(idempotent(req), id, bonus.Currency, bonus.Points)
+	} else {
+		err = s.service.Credit(idempotent(req), id, bonus.Currency, bonus.Points, expiresIn)
 	}
-	err = s.service.AddPoints(req.Context(), id, bonus.Points)
-	if err == sts.ErrNotFound {
-		w.WriteHeader(http.StatusNotFound)
-		fmt.Fprintf(w, "couldn't update user: %s", err)
+	if err != nil {
+		writeError(w, err, "couldn't update user")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+}
+
+func (s *Server) TransferPoints(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
 		return
 	}
+	transfer := struct {
+		To       int64        `json:"to"`
+		Points   uint64       `json:"points"`
+		Currency sts.Currency `json:"currency"`
+	}{}
+	err = json.NewDecoder(req.Body).Decode(&transfer)
 	if err != nil {
+		badRequest(w, "couldn't decode json: %s", err)
+		return
+	}
+	if transfer.Currency == "" {
+		transfer.Currency = sts.DefaultCurrency
+	}
+	err = s.service.TransferPoints(req.Context(), id, transfer.To, transfer.Currency, transfer.Points)
+	if err != nil {
+		writeError(w, err, "couldn't transfer points")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+func (s *Server) GetUserTransactions(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	var beforeID int64
+	if before := req.URL.Query().Get("before"); before != "" {
+		beforeID, err = strconv.ParseInt(before, 10, 64)
+		if err != nil || beforeID < 0 {
+			badRequest(w, "incorrect before: %s", before)
+			return
+		}
+	}
+	limit := uint64(defaultPageLimit)
+	if l := req.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.ParseUint(l, 10, 64)
+		if err != nil || limit == 0 || limit > maxPageLimit {
+			badRequest(w, "incorrect limit: %s", l)
+			return
+		}
+	}
+	transactions, err := s.service.GetUserTransactions(req.Context(), id, beforeID, limit)
+	if err != nil {
+		writeError(w, err, "couldn't get transactions")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(transactions)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "couldn't update user: %s", err)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
+}
+
+func (s *Server) GetRatingHistory(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		badRequest(w, "incorrect id: %s", err)
+		return
+	}
+	history, err := s.service.GetRatingHistory(req.Context(), id)
+	if err != nil {
+		writeError(w, err, "couldn't get rating history")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(history)
+	if err != nil {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "couldn't encode json: %s\n", err)
+		return
+	}
 }
@@ -0,0 +1,190 @@
+package sts
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Match represents a single game of a single-elimination bracket. Zero user means
+// that the slot is still waiting for the winner of a previous round or is a bye.
+type Match struct {
+	Round    uint32 `json:"round"`
+	Position uint32 `json:"position"`
+	User1    int64  `json:"user1,omitempty"`
+	User2    int64  `json:"user2,omitempty"`
+	Winner   int64  `json:"winner,omitempty"`
+}
+
+// Bracket holds matches of a single-elimination tournament ordered by round and position.
+type Bracket []Match
+
+// Seed returns order in which participants are placed into a bracket, starting from the
+// strongest one. Empty seeding means random order. Otherwise seeding must contain every
+// participant exactly once, or function returns ErrInvalidSeeding.
+func Seed(participants, seeding []int64) ([]int64, error) {
+	if len(seeding) == 0 {
+		seeded := append([]int64(nil), participants...)
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+		rnd.Shuffle(len(seeded), func(i, j int) {
+			seeded[i], seeded[j] = seeded[j], seeded[i]
+		})
+		return seeded, nil
+	}
+	err := ValidateRanking(seeding, participants)
+	if err != nil {
+		return nil, ErrInvalidSeeding
+	}
+	return seeding, nil
+}
+
+// NewBracket generates single-elimination bracket for users in passed seeding order.
+// If number of users isn't a power of two, the strongest seeds get byes to the second round.
+// At least two users are needed, otherwise function returns ErrNotEnoughPlayers.
+func NewBracket(seeding []int64) (Bracket, error) {
+	if len(seeding) < 2 {
+		return nil, ErrNotEnoughPlayers
+	}
+	size := 1
+	for size < len(seeding) {
+		size *= 2
+	}
+	var bracket Bracket
+	for round, matches := uint32(1), size/2; matches > 0; round, matches = round+1, matches/2 {
+		for position := 1; position <= matches; position++ {
+			bracket = append(bracket, Match{
+				Round:    round,
+				Position: uint32(position),
+			})
+		}
+	}
+
+	slots := seedSlots(size)
+	seeded := func(slot int) int64 {
+		if slots[slot] > len(seeding) {
+			return 0
+		}
+		return seeding[slots[slot]-1]
+	}
+	for i := 0; i < size/2; i++ {
+		m := &bracket[i]
+		m.User1 = seeded(2 * i)
+		m.User2 = seeded(2*i + 1)
+		if m.User2 == 0 {
+			m.Winner = m.User1
+			bracket.advance(*m)
+		}
+	}
+	return bracket, nil
+}
+
+// seedSlots returns seed numbers in the order of bracket slots, so that the strongest
+// seeds meet each other as late as possible, e.g. 1, 8, 4, 5, 2, 7, 3, 6 for 8 slots.
+func seedSlots(size int) []int {
+	slots := []int{1}
+	for len(slots) < size {
+		next := make([]int, 0, 2*len(slots))
+		for _, seed := range slots {
+			next = append(next, seed, 2*len(slots)+1-seed)
+		}
+		slots = next
+	}
+	return slots
+}
+
+// Match returns match of passed round and position or nil if there is no such match.
+func (b Bracket) Match(round, position uint32) *Match {
+	for i := range b {
+		if b[i].Round == round && b[i].Position == position {
+			return &b[i]
+		}
+	}
+	return nil
+}
+
+// Final returns the last match of the bracket.
+func (b Bracket) Final() *Match {
+	if len(b) == 0 {
+		return nil
+	}
+	return &b[len(b)-1]
+}
+
+// advance puts the winner of passed match into the match of the next round and returns it.
+// For the final function returns nil.
+func (b Bracket) advance(m Match) *Match {
+	next := b.Match(m.Round+1, (m.Position+1)/2)
+	if next == nil {
+		return nil
+	}
+	if m.Position%2 == 1 {
+		next.User1 = m.Winner
+	} else {
+		next.User2 = m.Winner
+	}
+	return next
+}
+
+// Report records the winner of match with passed round and position and advances the winner
+// to the next round. It returns the match of the next round or nil for the final.
+// If match isn't found, function returns ErrNotFound. If match doesn't have both users yet,
+// function returns ErrMatchNotReady. If match has already been reported, function returns
+// ErrMatchReported. If winner doesn't play in match, function returns ErrNotParticipant.
+func (b Bracket) Report(round, position uint32, winner int64) (*Match, error) {
+	m := b.Match(round, position)
+	if m == nil {
+		return nil, ErrNotFound
+	}
+	if m.Winner != 0 {
+		return nil, ErrMatchReported
+	}
+	if m.User1 == 0 || m.User2 == 0 {
+		return nil, ErrMatchNotReady
+	}
+	if winner != m.User1 && winner != m.User2 {
+		return nil, ErrNotParticipant
+	}
+	m.Winner = winner
+	return b.advance(*m), nil
+}
+
+// Ranking returns users of finished bracket from the winner to the first losers. Users
+// that have lost in the same round are ordered by match position.
+func (b Bracket) Ranking() []int64 {
+	final := b.Final()
+	if final == nil || final.Winner == 0 {
+		return nil
+	}
+	ranking := []int64{final.Winner}
+	for i := len(b) - 1; i >= 0; i-- {
+		round := b[i].Round
+		start := i
+		for start > 0 && b[start-1].Round == round {
+			start--
+		}
+		for _, m := range b[start : i+1] {
+			if loser := m.loser(); loser != 0 {
+				ranking = append(ranking, loser)
+			}
+		}
+		i = start
+	}
+	return ranking
+}
+
+func (m Match) loser() int64 {
+	switch m.Winner {
+	case 0:
+		return 0
+	case m.User1:
+		return m.User2
+	}
+	return m.User1
+}
+
+// Score returns the result of User1 in reported match: 1 for a win and 0 for a loss.
+func (m Match) Score() float64 {
+	if m.Winner == m.User1 {
+		return 1
+	}
+	return 0
+}
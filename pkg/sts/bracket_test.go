@@ -0,0 +1,90 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeedSlots(t *testing.T) {
+	expected := []int{1, 8, 4, 5, 2, 7, 3, 6}
+	if slots := seedSlots(8); !reflect.DeepEqual(slots, expected) {
+		t.Fatalf("expected %v, got %v", expected, slots)
+	}
+}
+
+func TestNewBracket(t *testing.T) {
+	bracket, err := NewBracket([]int64{11, 12, 13, 14, 15})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := Bracket{
+		{Round: 1, Position: 1, User1: 11, Winner: 11},
+		{Round: 1, Position: 2, User1: 14, User2: 15},
+		{Round: 1, Position: 3, User1: 12, Winner: 12},
+		{Round: 1, Position: 4, User1: 13, Winner: 13},
+		{Round: 2, Position: 1, User1: 11},
+		{Round: 2, Position: 2, User1: 12, User2: 13},
+		{Round: 3, Position: 1},
+	}
+	if !reflect.DeepEqual(bracket, expected) {
+		t.Fatalf("expected %v, got %v", expected, bracket)
+	}
+
+	_, err = NewBracket([]int64{11})
+	if err != ErrNotEnoughPlayers {
+		t.Fatalf("expected %v, got %v", ErrNotEnoughPlayers, err)
+	}
+}
+
+func TestBracketReport(t *testing.T) {
+	bracket, err := NewBracket([]int64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tt := []struct {
+		name     string
+		round    uint32
+		position uint32
+		winner   int64
+		err      error
+		final    bool
+	}{
+		{name: "final isn't ready", round: 2, position: 1, winner: 1, err: ErrMatchNotReady},
+		{name: "unknown match", round: 1, position: 3, winner: 1, err: ErrNotFound},
+		{name: "winner doesn't play", round: 1, position: 1, winner: 2, err: ErrNotParticipant},
+		{name: "first semifinal", round: 1, position: 1, winner: 4},
+		{name: "reported twice", round: 1, position: 1, winner: 4, err: ErrMatchReported},
+		{name: "second semifinal", round: 1, position: 2, winner: 2},
+		{name: "final", round: 2, position: 1, winner: 2, final: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := bracket.Report(tc.round, tc.position, tc.winner)
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if err == nil && (next == nil) != tc.final {
+				t.Fatalf("expected final %v, got next match %v", tc.final, next)
+			}
+		})
+	}
+	expected := []int64{2, 4, 1, 3}
+	if ranking := bracket.Ranking(); !reflect.DeepEqual(ranking, expected) {
+		t.Fatalf("expected ranking %v, got %v", expected, ranking)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	participants := []int64{1, 2, 3}
+	seeded, err := Seed(participants, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ValidateRanking(seeded, participants) != nil {
+		t.Fatalf("expected every participant once, got %v", seeded)
+	}
+	_, err = Seed(participants, []int64{1, 2})
+	if err != ErrInvalidSeeding {
+		t.Fatalf("expected %v, got %v", ErrInvalidSeeding, err)
+	}
+}
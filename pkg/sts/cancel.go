@@ -0,0 +1,41 @@
+package sts
+
+// Refund is a deposit that is returned to user when tournament is cancelled.
+type Refund struct {
+	UserID int64
+	Amount uint64
+}
+
+// Cancel moves tournament to cancelled status, empties its prize and waitlist and returns
+// deposits that are refunded to participants in Users. Deposits of passed team entries of
+// team-based tournament are refunded to members by their shares instead. If tournament can't
+// be cancelled, method returns TransitionError.
+func (t *Tournament) Cancel(teams []TeamEntry) ([]Refund, error) {
+	err := CheckTransition(t.Status, StatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	var refunds []Refund
+	if t.TeamBased {
+		for _, team := range teams {
+			for i, deposit := range team.Split(t.Deposit) {
+				refunds = append(refunds, Refund{
+					UserID: team.Members[i].UserID,
+					Amount: deposit,
+				})
+			}
+		}
+	} else {
+		for _, userID := range t.Users {
+			refunds = append(refunds, Refund{
+				UserID: userID,
+				Amount: t.Deposit,
+			})
+		}
+	}
+	t.Status = StatusCancelled
+	t.GrossPrize = 0
+	t.Prize = 0
+	t.Waitlist = nil
+	return refunds, nil
+}
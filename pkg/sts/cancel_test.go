@@ -0,0 +1,70 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCancel(t *testing.T) {
+	tournament := Tournament{
+		TournamentSettings: TournamentSettings{Deposit: 100, RakeType: RakePercent, Rake: 10},
+		Status:             StatusRegistration,
+		GrossPrize:         300,
+		Prize:              270,
+		Users:              []int64{1, 2, 3},
+		Waitlist:           []int64{4},
+	}
+	refunds, err := tournament.Cancel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Refund{{UserID: 1, Amount: 100}, {UserID: 2, Amount: 100}, {UserID: 3, Amount: 100}}
+	if !reflect.DeepEqual(refunds, expected) {
+		t.Fatalf("expected refunds %v, got %v", expected, refunds)
+	}
+	if tournament.Status != StatusCancelled || tournament.GrossPrize != 0 || tournament.Prize != 0 ||
+		tournament.Waitlist != nil {
+		t.Fatalf("expected cancelled tournament without prize and waitlist, got %+v", tournament)
+	}
+}
+
+func TestCancelTeams(t *testing.T) {
+	tournament := Tournament{
+		TournamentSettings: TournamentSettings{Deposit: 100, TeamBased: true},
+		Status:             StatusRunning,
+		GrossPrize:         200,
+		Prize:              200,
+		Users:              []int64{1, 2, 3},
+	}
+	teams := []TeamEntry{
+		{TeamID: 1, Members: []TeamMember{{UserID: 1, Share: 70}, {UserID: 2, Share: 30}}},
+		{TeamID: 2, Members: []TeamMember{{UserID: 3, Share: 100}}},
+	}
+	refunds, err := tournament.Cancel(teams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Refund{{UserID: 1, Amount: 70}, {UserID: 2, Amount: 30}, {UserID: 3, Amount: 100}}
+	if !reflect.DeepEqual(refunds, expected) {
+		t.Fatalf("expected refunds %v, got %v", expected, refunds)
+	}
+	if tournament.Status != StatusCancelled || tournament.Prize != 0 {
+		t.Fatalf("expected cancelled tournament without prize, got %+v", tournament)
+	}
+}
+
+func TestCancelFinished(t *testing.T) {
+	tournament := Tournament{
+		TournamentSettings: TournamentSettings{Deposit: 100},
+		Status:             StatusFinished,
+		Prize:              100,
+		Users:              []int64{1},
+	}
+	refunds, err := tournament.Cancel(nil)
+	if _, ok := err.(*TransitionError); !ok || refunds != nil {
+		t.Fatalf("expected transition error, got %v and %v", refunds, err)
+	}
+	if tournament.Status != StatusFinished || tournament.Prize != 100 {
+		t.Fatalf("expected unchanged tournament, got %+v", tournament)
+	}
+}
@@ -0,0 +1,18 @@
+package sts
+
+// IsFull reports whether tournament with passed number of players has no free seats.
+func (s TournamentSettings) IsFull(players int) bool {
+	return s.MaxPlayers != 0 && players >= int(s.MaxPlayers)
+}
+
+// HasEnoughPlayers reports whether passed number of players reaches tournament minimum.
+func (s TournamentSettings) HasEnoughPlayers(players int) bool {
+	return players >= int(s.MinPlayers)
+}
+
+func validateCapacity(s TournamentSettings) error {
+	if s.MaxPlayers != 0 && s.MinPlayers > s.MaxPlayers {
+		return ErrInvalidCapacity
+	}
+	return nil
+}
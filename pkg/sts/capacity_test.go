@@ -0,0 +1,40 @@
+package sts
+
+import "testing"
+
+func TestCapacity(t *testing.T) {
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		players  int
+		full     bool
+		enough   bool
+	}{
+		{name: "unlimited", settings: TournamentSettings{}, players: 1000, enough: true},
+		{name: "below minimum", settings: TournamentSettings{MinPlayers: 4, MaxPlayers: 8}, players: 3},
+		{name: "minimum", settings: TournamentSettings{MinPlayers: 4, MaxPlayers: 8}, players: 4, enough: true},
+		{name: "full", settings: TournamentSettings{MinPlayers: 4, MaxPlayers: 8}, players: 8, full: true,
+			enough: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if full := tc.settings.IsFull(tc.players); full != tc.full {
+				t.Fatalf("expected full %v, got %v", tc.full, full)
+			}
+			if enough := tc.settings.HasEnoughPlayers(tc.players); enough != tc.enough {
+				t.Fatalf("expected enough players %v, got %v", tc.enough, enough)
+			}
+		})
+	}
+}
+
+func TestValidateCapacity(t *testing.T) {
+	s := TournamentSettings{MinPlayers: 8, MaxPlayers: 4}
+	if err := s.Validate(); err != ErrInvalidCapacity {
+		t.Fatalf("expected %v, got %v", ErrInvalidCapacity, err)
+	}
+	s = TournamentSettings{MinPlayers: 8}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package sts
+
+// Currency is a code of a kind of points. Every user keeps a separate balance of each currency.
+// Available currencies are registered in db.
+type Currency string
+
+// DefaultCurrency is used when tournament is created or points are moved without a currency.
+const DefaultCurrency Currency = "bonus"
+
+// Balance represents points of a single currency that belong to user.
+type Balance struct {
+	Currency Currency `json:"currency"`
+	Amount   uint64   `json:"amount"`
+}
+
+// BalanceOf returns amount of points of passed currency that belong to user.
+func (u User) BalanceOf(currency Currency) uint64 {
+	for _, b := range u.Balances {
+		if b.Currency == currency {
+			return b.Amount
+		}
+	}
+	return 0
+}
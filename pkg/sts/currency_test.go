@@ -0,0 +1,37 @@
+package sts
+
+import "testing"
+
+func TestBalanceOf(t *testing.T) {
+	user := User{Balances: []Balance{
+		{Currency: DefaultCurrency, Amount: 100},
+		{Currency: "premium", Amount: 5},
+	}}
+	tt := []struct {
+		name     string
+		currency Currency
+		amount   uint64
+	}{
+		{name: "default currency", currency: DefaultCurrency, amount: 100},
+		{name: "other currency", currency: "premium", amount: 5},
+		{name: "missing wallet", currency: "play"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if amount := user.BalanceOf(tc.currency); amount != tc.amount {
+				t.Fatalf("expected %d, got %d", tc.amount, amount)
+			}
+		})
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	settings := TournamentSettings{Name: "poker", Deposit: 100}
+	err := settings.Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Currency != DefaultCurrency {
+		t.Fatalf("expected currency %s, got %s", DefaultCurrency, settings.Currency)
+	}
+}
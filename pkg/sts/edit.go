@@ -0,0 +1,156 @@
+package sts
+
+import (
+	"reflect"
+	"time"
+)
+
+// TournamentUpdate holds changes of tournament settings. Nil fields are left as they are.
+// Zero schedule times are cleared, other zero fields are reset like omitted settings of a new
+// tournament.
+type TournamentUpdate struct {
+	Name                 *string
+	Deposit              *uint64
+	Currency             *Currency
+	PrizeShares          *[]uint32
+	RakeType             *RakeType
+	Rake                 *uint64
+	MinPlayers           *uint32
+	MaxPlayers           *uint32
+	RegistrationOpensAt  *time.Time
+	RegistrationClosesAt *time.Time
+	StartsAt             *time.Time
+	Format               *Format
+	Rounds               *uint32
+	MinRating            *uint32
+	MaxRating            *uint32
+	TeamBased            *bool
+	Visibility           *Visibility
+	OrganizerID          *int64
+	GuaranteedPrize      *uint64
+	Sponsor              *string
+}
+
+// Apply returns passed settings with changes of the update. Result must be validated.
+func (u TournamentUpdate) Apply(s TournamentSettings) TournamentSettings {
+	if u.Name != nil {
+		s.Name = *u.Name
+	}
+	if u.Deposit != nil {
+		s.Deposit = *u.Deposit
+	}
+	if u.Currency != nil {
+		s.Currency = *u.Currency
+	}
+	if u.PrizeShares != nil {
+		s.PrizeShares = *u.PrizeShares
+	}
+	if u.RakeType != nil {
+		s.RakeType = *u.RakeType
+	}
+	if u.Rake != nil {
+		s.Rake = *u.Rake
+	}
+	if u.MinPlayers != nil {
+		s.MinPlayers = *u.MinPlayers
+	}
+	if u.MaxPlayers != nil {
+		s.MaxPlayers = *u.MaxPlayers
+	}
+	if u.RegistrationOpensAt != nil {
+		s.RegistrationOpensAt = scheduled(*u.RegistrationOpensAt)
+	}
+	if u.RegistrationClosesAt != nil {
+		s.RegistrationClosesAt = scheduled(*u.RegistrationClosesAt)
+	}
+	if u.StartsAt != nil {
+		s.StartsAt = scheduled(*u.StartsAt)
+	}
+	if u.Format != nil {
+		s.Format = *u.Format
+	}
+	if u.Rounds != nil {
+		s.Rounds = *u.Rounds
+	}
+	if u.MinRating != nil {
+		s.MinRating = *u.MinRating
+	}
+	if u.MaxRating != nil {
+		s.MaxRating = *u.MaxRating
+	}
+	if u.TeamBased != nil {
+		s.TeamBased = *u.TeamBased
+	}
+	if u.Visibility != nil {
+		s.Visibility = *u.Visibility
+	}
+	if u.OrganizerID != nil {
+		s.OrganizerID = *u.OrganizerID
+	}
+	if u.GuaranteedPrize != nil {
+		s.GuaranteedPrize = *u.GuaranteedPrize
+	}
+	if u.Sponsor != nil {
+		s.Sponsor = *u.Sponsor
+	}
+	return s
+}
+
+func scheduled(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// CheckUpdate returns error if settings of tournament can't be replaced with passed ones.
+// Settings must be validated. Tournament that has started can't be changed, it returns
+// ErrTournamentClosed. Once players have joined tournament or its waitlist, only its name can
+// be changed, other changes return ErrTournamentLocked.
+func (t Tournament) CheckUpdate(settings TournamentSettings) error {
+	if t.Status != StatusDraft && t.Status != StatusRegistration {
+		return ErrTournamentClosed
+	}
+	if len(t.Users) == 0 && len(t.Teams) == 0 && len(t.Waitlist) == 0 {
+		return nil
+	}
+	if !reflect.DeepEqual(t.TournamentSettings.locked(), settings.locked()) {
+		return ErrTournamentLocked
+	}
+	return nil
+}
+
+// locked returns settings without cosmetic ones, which can be changed at any time before
+// tournament starts. Times are truncated to seconds that every db keeps.
+func (s TournamentSettings) locked() TournamentSettings {
+	s.Name = ""
+	s.RegistrationOpensAt = truncate(s.RegistrationOpensAt)
+	s.RegistrationClosesAt = truncate(s.RegistrationClosesAt)
+	s.StartsAt = truncate(s.StartsAt)
+	return s
+}
+
+func truncate(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	truncated := t.UTC().Truncate(time.Second)
+	return &truncated
+}
+
+// Clone returns a copy of settings for a new tournament. Schedule times that have already
+// passed at now are dropped, so the copy isn't opened or cancelled right after creation.
+func (s TournamentSettings) Clone(now time.Time) TournamentSettings {
+	s.PrizeShares = append([]uint32(nil), s.PrizeShares...)
+	s.RegistrationOpensAt = upcoming(s.RegistrationOpensAt, now)
+	s.RegistrationClosesAt = upcoming(s.RegistrationClosesAt, now)
+	s.StartsAt = upcoming(s.StartsAt, now)
+	return s
+}
+
+func upcoming(t *time.Time, now time.Time) *time.Time {
+	if t == nil || !t.After(now) {
+		return nil
+	}
+	return t
+}
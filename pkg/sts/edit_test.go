@@ -0,0 +1,137 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckUpdate(t *testing.T) {
+	startsAt := time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC)
+	settings := TournamentSettings{
+		Name:        "poker",
+		Deposit:     100,
+		PrizeShares: []uint32{100},
+		StartsAt:    &startsAt,
+	}
+	renamed := settings
+	renamed.Name = "holdem"
+	moved := settings
+	local := startsAt.In(time.FixedZone("MSK", 3*60*60)).Add(300 * time.Millisecond)
+	moved.StartsAt = &local
+	raised := settings
+	raised.Deposit = 200
+	tt := []struct {
+		name       string
+		tournament Tournament
+		settings   TournamentSettings
+		expected   error
+	}{
+		{
+			name:       "empty tournament",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRegistration},
+			settings:   raised,
+			expected:   nil,
+		},
+		{
+			name:       "renamed tournament",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRegistration, Users: []int64{1}},
+			settings:   renamed,
+			expected:   nil,
+		},
+		{
+			name:       "same start in another zone",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRegistration, Users: []int64{1}},
+			settings:   moved,
+			expected:   nil,
+		},
+		{
+			name:       "joined tournament",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRegistration, Users: []int64{1}},
+			settings:   raised,
+			expected:   ErrTournamentLocked,
+		},
+		{
+			name:       "waitlisted tournament",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRegistration, Waitlist: []int64{1}},
+			settings:   raised,
+			expected:   ErrTournamentLocked,
+		},
+		{
+			name:       "running tournament",
+			tournament: Tournament{TournamentSettings: settings, Status: StatusRunning},
+			settings:   renamed,
+			expected:   ErrTournamentClosed,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.tournament.CheckUpdate(tc.settings); err != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	opensAt := time.Date(2019, 12, 20, 12, 0, 0, 0, time.UTC)
+	startsAt := time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC)
+	settings := TournamentSettings{
+		Name:                "poker",
+		Deposit:             100,
+		PrizeShares:         []uint32{70, 30},
+		MaxPlayers:          8,
+		RegistrationOpensAt: &opensAt,
+		StartsAt:            &startsAt,
+		Sponsor:             "marketing",
+	}
+	name := "holdem"
+	deposit := uint64(200)
+	shares := []uint32{100}
+	moved := startsAt.Add(time.Hour)
+	var (
+		noOpening time.Time
+		noSponsor string
+	)
+	update := TournamentUpdate{
+		Name:                &name,
+		Deposit:             &deposit,
+		PrizeShares:         &shares,
+		RegistrationOpensAt: &noOpening,
+		StartsAt:            &moved,
+		Sponsor:             &noSponsor,
+	}
+	expected := TournamentSettings{
+		Name:        "holdem",
+		Deposit:     200,
+		PrizeShares: []uint32{100},
+		MaxPlayers:  8,
+		StartsAt:    &moved,
+	}
+	if result := update.Apply(settings); !reflect.DeepEqual(expected, result) {
+		t.Fatalf("expected %+v, got %+v", expected, result)
+	}
+	if result := (TournamentUpdate{}).Apply(settings); !reflect.DeepEqual(settings, result) {
+		t.Fatalf("expected %+v, got %+v", settings, result)
+	}
+}
+
+func TestClone(t *testing.T) {
+	now := time.Date(2019, 12, 16, 12, 0, 0, 0, time.UTC)
+	opensAt := now.Add(-time.Hour)
+	startsAt := now.Add(time.Hour)
+	settings := TournamentSettings{
+		Name:                "poker",
+		PrizeShares:         []uint32{70, 30},
+		RegistrationOpensAt: &opensAt,
+		StartsAt:            &startsAt,
+	}
+	clone := settings.Clone(now)
+	if clone.RegistrationOpensAt != nil || clone.StartsAt == nil || !clone.StartsAt.Equal(startsAt) {
+		t.Fatalf("expected only upcoming start, got %v and %v", clone.RegistrationOpensAt, clone.StartsAt)
+	}
+	clone.PrizeShares[0] = 50
+	if settings.PrizeShares[0] != 70 {
+		t.Fatalf("expected prize shares to be copied, got %v", settings.PrizeShares)
+	}
+}
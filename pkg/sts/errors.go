@@ -0,0 +1,74 @@
+package sts
+
+// CodeInternal is a code of errors that aren't known to the service.
+const CodeInternal = "internal"
+
+// errorCodes holds stable codes that clients use to tell known errors apart.
+var errorCodes = map[error]string{
+	ErrNotFound:              "not_found",
+	ErrTournamentClosed:      "tournament_closed",
+	ErrNotParticipant:        "not_participant",
+	ErrInvalidPrizeShares:    "invalid_prize_shares",
+	ErrInvalidRake:           "invalid_rake",
+	ErrInvalidCapacity:       "invalid_capacity",
+	ErrInvalidSchedule:       "invalid_schedule",
+	ErrNotEnoughPlayers:      "not_enough_players",
+	ErrInvalidRanking:        "invalid_ranking",
+	ErrTournamentNotRunning:  "tournament_not_running",
+	ErrInvalidSeeding:        "invalid_seeding",
+	ErrBracketExists:         "bracket_exists",
+	ErrMatchNotReady:         "match_not_ready",
+	ErrMatchReported:         "match_reported",
+	ErrInvalidFormat:         "invalid_format",
+	ErrUnsupportedFormat:     "unsupported_format",
+	ErrRoundNotFinished:      "round_not_finished",
+	ErrInvalidResult:         "invalid_result",
+	ErrInvalidRatingRange:    "invalid_rating_range",
+	ErrRatingOutOfRange:      "rating_out_of_range",
+	ErrInvalidLeaderboard:    "invalid_leaderboard",
+	ErrNotInvited:            "not_invited",
+	ErrInvalidTeamShares:     "invalid_team_shares",
+	ErrTeamTournament:        "team_tournament",
+	ErrSoloTournament:        "solo_tournament",
+	ErrTournamentFull:        "tournament_full",
+	ErrTeamFull:              "team_full",
+	ErrAlreadyJoined:         "already_joined",
+	ErrInvalidVisibility:     "invalid_visibility",
+	ErrInvalidInviteCode:     "invalid_invite_code",
+	ErrNotOrganizer:          "not_organizer",
+	ErrPublicTournament:      "public_tournament",
+	ErrInvalidGuarantee:      "invalid_guarantee",
+	ErrInsufficientBudget:    "insufficient_budget",
+	ErrInvalidCurrency:       "invalid_currency",
+	ErrInvalidExpiry:         "invalid_expiry",
+	ErrInsufficientFunds:     "insufficient_funds",
+	ErrInvalidTransfer:       "invalid_transfer",
+	ErrTransferCapExceeded:   "transfer_cap_exceeded",
+	ErrIdempotencyKeyReused:  "idempotency_key_reused",
+	ErrValidation:            "validation",
+	ErrInvalidIdempotencyKey: "invalid_idempotency_key",
+	ErrInvalidListing:        "invalid_listing",
+	ErrInvalidHandle:         "invalid_handle",
+	ErrHandleTaken:           "handle_taken",
+	ErrInvalidProfile:        "invalid_profile",
+	ErrTournamentLocked:      "tournament_locked",
+}
+
+// ErrorCode returns a stable code of err, e.g. "insufficient_funds". Wrapped errors are identified
+// by their cause. Errors that aren't known to the service have CodeInternal.
+func ErrorCode(err error) string {
+	for err != nil {
+		if code, ok := errorCodes[err]; ok {
+			return code
+		}
+		if _, ok := err.(*TransitionError); ok {
+			return "invalid_transition"
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return CodeInternal
+}
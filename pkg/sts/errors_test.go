@@ -0,0 +1,30 @@
+package sts
+
+import (
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestErrorCode(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{name: "known error", err: ErrInsufficientFunds, code: "insufficient_funds"},
+		{name: "wrapped error", err: pkgerrors.Wrap(ErrAlreadyJoined, "couldn't join tournament"), code: "already_joined"},
+		{name: "transition error", err: CheckTransition(StatusFinished, StatusRunning), code: "invalid_transition"},
+		{name: "unknown error", err: errors.New("connection refused"), code: CodeInternal},
+		{name: "wrapped unknown error", err: pkgerrors.Wrap(errors.New("timeout"), "couldn't get user"), code: CodeInternal},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			code := ErrorCode(tc.err)
+			if code != tc.code {
+				t.Fatalf("expected code %s, got %s", tc.code, code)
+			}
+		})
+	}
+}
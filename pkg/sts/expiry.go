@@ -0,0 +1,52 @@
+package sts
+
+import "time"
+
+// Expiration represents points of a single credit that will expire at a certain time unless they're spent.
+type Expiration struct {
+	Currency  Currency  `json:"currency"`
+	Amount    uint64    `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Lot is a credit of points that expire at ExpiresAt unless they're spent.
+type Lot struct {
+	ID        int64
+	Amount    uint64
+	ExpiresAt time.Time
+}
+
+// SpendLots takes amount of points from lots that are ordered by expiry, the soonest ones first,
+// and returns how much is taken from each lot. Lots that have expired by now aren't spent, their
+// points are taken by expiry. The rest of amount that lots don't cover is taken from points that
+// never expire.
+func SpendLots(lots []Lot, amount uint64, now time.Time) []Lot {
+	var spent []Lot
+	for _, lot := range lots {
+		if amount == 0 {
+			break
+		}
+		if !lot.ExpiresAt.After(now) || lot.Amount == 0 {
+			continue
+		}
+		if lot.Amount > amount {
+			lot.Amount = amount
+		}
+		amount -= lot.Amount
+		spent = append(spent, lot)
+	}
+	return spent
+}
+
+// ExpiresAt returns the time when points credited at now expire after expiresIn.
+// Zero expiresIn means that points never expire and function returns nil.
+func ExpiresAt(now time.Time, expiresIn time.Duration) (*time.Time, error) {
+	if expiresIn < 0 {
+		return nil, ErrInvalidExpiry
+	}
+	if expiresIn == 0 {
+		return nil, nil
+	}
+	expiresAt := now.Add(expiresIn)
+	return &expiresAt, nil
+}
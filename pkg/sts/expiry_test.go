@@ -0,0 +1,69 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpiresAt(t *testing.T) {
+	now := time.Date(2019, 11, 18, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name      string
+		expiresIn time.Duration
+		expiresAt *time.Time
+		err       error
+	}{
+		{name: "never expires"},
+		{name: "week", expiresIn: 7 * 24 * time.Hour, expiresAt: timePtr(now.Add(7 * 24 * time.Hour))},
+		{name: "negative period", expiresIn: -time.Hour, err: ErrInvalidExpiry},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expiresAt, err := ExpiresAt(now, tc.expiresIn)
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if (expiresAt == nil) != (tc.expiresAt == nil) || expiresAt != nil && !expiresAt.Equal(*tc.expiresAt) {
+				t.Fatalf("expected %v, got %v", tc.expiresAt, expiresAt)
+			}
+		})
+	}
+}
+
+func TestSpendLots(t *testing.T) {
+	now := time.Date(2019, 11, 18, 12, 0, 0, 0, time.UTC)
+	lots := []Lot{
+		{ID: 1, Amount: 50, ExpiresAt: now.Add(-time.Hour)},
+		{ID: 2, Amount: 30, ExpiresAt: now},
+		{ID: 3, Amount: 40, ExpiresAt: now.Add(time.Hour)},
+		{ID: 4, Amount: 40, ExpiresAt: now.Add(2 * time.Hour)},
+	}
+	tt := []struct {
+		name   string
+		amount uint64
+		spent  []Lot
+	}{
+		{name: "nothing", amount: 0},
+		{name: "unswept expired lots", amount: 10, spent: []Lot{{ID: 3, Amount: 10, ExpiresAt: now.Add(time.Hour)}}},
+		{name: "several lots", amount: 60, spent: []Lot{
+			{ID: 3, Amount: 40, ExpiresAt: now.Add(time.Hour)},
+			{ID: 4, Amount: 20, ExpiresAt: now.Add(2 * time.Hour)},
+		}},
+		{name: "more than lots", amount: 200, spent: []Lot{
+			{ID: 3, Amount: 40, ExpiresAt: now.Add(time.Hour)},
+			{ID: 4, Amount: 40, ExpiresAt: now.Add(2 * time.Hour)},
+		}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if spent := SpendLots(lots, tc.amount, now); !reflect.DeepEqual(tc.spent, spent) {
+				t.Fatalf("expected %v, got %v", tc.spent, spent)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
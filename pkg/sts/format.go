@@ -0,0 +1,57 @@
+package sts
+
+// Format defines how tournament games are played and how the final ranking is decided.
+type Format string
+
+const (
+	// FormatPool means that there are no games tracked by the service and the ranking
+	// is passed when tournament is finished.
+	FormatPool Format = "pool"
+	// FormatSingleElimination means that users play a knockout bracket.
+	FormatSingleElimination Format = "single_elimination"
+	// FormatRoundRobin means that every user plays every other user once.
+	FormatRoundRobin Format = "round_robin"
+	// FormatSwiss means that users with similar scores are paired in each round.
+	FormatSwiss Format = "swiss"
+)
+
+// HasPairings reports whether tournament games are played in rounds of pairings.
+func (s TournamentSettings) HasPairings() bool {
+	return s.Format == FormatRoundRobin || s.Format == FormatSwiss
+}
+
+// TotalRounds returns the number of rounds of pairings for passed number of players.
+// Swiss tournament plays Rounds rounds or enough rounds to find a single winner
+// if Rounds is zero. For other formats function returns zero.
+func (s TournamentSettings) TotalRounds(players int) uint32 {
+	switch s.Format {
+	case FormatRoundRobin:
+		if players%2 == 0 {
+			return uint32(players - 1)
+		}
+		return uint32(players)
+	case FormatSwiss:
+		if s.Rounds != 0 {
+			return s.Rounds
+		}
+		var rounds uint32
+		for size := 1; size < players; size *= 2 {
+			rounds++
+		}
+		return rounds
+	}
+	return 0
+}
+
+func validateFormat(s TournamentSettings) error {
+	switch s.Format {
+	case FormatPool, FormatSingleElimination, FormatRoundRobin:
+		if s.Rounds != 0 {
+			return ErrInvalidFormat
+		}
+	case FormatSwiss:
+	default:
+		return ErrInvalidFormat
+	}
+	return nil
+}
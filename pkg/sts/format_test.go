@@ -0,0 +1,40 @@
+package sts
+
+import "testing"
+
+func TestTotalRounds(t *testing.T) {
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		players  int
+		rounds   uint32
+	}{
+		{name: "pool", settings: TournamentSettings{Format: FormatPool}, players: 8},
+		{name: "even round robin", settings: TournamentSettings{Format: FormatRoundRobin}, players: 6, rounds: 5},
+		{name: "odd round robin", settings: TournamentSettings{Format: FormatRoundRobin}, players: 5, rounds: 5},
+		{name: "default swiss", settings: TournamentSettings{Format: FormatSwiss}, players: 9, rounds: 4},
+		{name: "swiss", settings: TournamentSettings{Format: FormatSwiss, Rounds: 7}, players: 9, rounds: 7},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if rounds := tc.settings.TotalRounds(tc.players); rounds != tc.rounds {
+				t.Fatalf("expected %d rounds, got %d", tc.rounds, rounds)
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	s := TournamentSettings{}
+	if err := s.Validate(); err != nil || s.Format != FormatPool {
+		t.Fatalf("expected default pool format, got %q and %v", s.Format, err)
+	}
+	s = TournamentSettings{Format: "knockout"}
+	if err := s.Validate(); err != ErrInvalidFormat {
+		t.Fatalf("expected %v, got %v", ErrInvalidFormat, err)
+	}
+	s = TournamentSettings{Format: FormatRoundRobin, Rounds: 3}
+	if err := s.Validate(); err != ErrInvalidFormat {
+		t.Fatalf("expected %v, got %v", ErrInvalidFormat, err)
+	}
+}
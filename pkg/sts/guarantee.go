@@ -0,0 +1,43 @@
+package sts
+
+// SponsorAccount is a name of the house account that pays overlays of guaranteed prizes
+// when tournament doesn't name another one.
+const SponsorAccount = "sponsor"
+
+// PrizePool returns the prize that is split between paid places: the collected prize or
+// the guaranteed one, whichever is bigger.
+func (t Tournament) PrizePool() uint64 {
+	if t.Prize < t.GuaranteedPrize {
+		return t.GuaranteedPrize
+	}
+	return t.Prize
+}
+
+// PrizeOverlay returns the part of the prize pool that isn't covered by deposits and is paid
+// by the sponsor.
+func (t Tournament) PrizeOverlay() uint64 {
+	return t.PrizePool() - t.Prize
+}
+
+// ReservedBudget returns the part of the sponsor budget that is held for the guaranteed prize of
+// tournament. Guaranteed prize is reserved when registration opens, so the overlay is paid whatever
+// deposits are collected, and released when tournament finishes or is cancelled.
+func (t Tournament) ReservedBudget() uint64 {
+	if t.Status != StatusRegistration && t.Status != StatusRunning {
+		return 0
+	}
+	return t.GuaranteedPrize
+}
+
+func validateGuarantee(s *TournamentSettings) error {
+	if s.GuaranteedPrize == 0 {
+		if s.Sponsor != "" {
+			return ErrInvalidGuarantee
+		}
+		return nil
+	}
+	if s.Sponsor == "" {
+		s.Sponsor = SponsorAccount
+	}
+	return nil
+}
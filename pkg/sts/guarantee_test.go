@@ -0,0 +1,84 @@
+package sts
+
+import "testing"
+
+func TestPrizeOverlay(t *testing.T) {
+	tt := []struct {
+		name       string
+		prize      uint64
+		guaranteed uint64
+		pool       uint64
+		overlay    uint64
+	}{
+		{name: "no guarantee", prize: 500, pool: 500},
+		{name: "deposits fall short", prize: 600, guaranteed: 1000, pool: 1000, overlay: 400},
+		{name: "deposits exceed guarantee", prize: 1200, guaranteed: 1000, pool: 1200},
+		{name: "freeroll", guaranteed: 1000, pool: 1000, overlay: 1000},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tournament := Tournament{
+				TournamentSettings: TournamentSettings{GuaranteedPrize: tc.guaranteed},
+				Prize:              tc.prize,
+			}
+			if pool := tournament.PrizePool(); pool != tc.pool {
+				t.Fatalf("expected pool %d, got %d", tc.pool, pool)
+			}
+			if overlay := tournament.PrizeOverlay(); overlay != tc.overlay {
+				t.Fatalf("expected overlay %d, got %d", tc.overlay, overlay)
+			}
+		})
+	}
+}
+
+func TestReservedBudget(t *testing.T) {
+	tt := []struct {
+		status   Status
+		reserved uint64
+	}{
+		{status: StatusDraft},
+		{status: StatusRegistration, reserved: 1000},
+		{status: StatusRunning, reserved: 1000},
+		{status: StatusFinished},
+		{status: StatusCancelled},
+	}
+	for _, tc := range tt {
+		t.Run(string(tc.status), func(t *testing.T) {
+			tournament := Tournament{
+				TournamentSettings: TournamentSettings{GuaranteedPrize: 1000, Sponsor: "marketing"},
+				Status:             tc.status,
+			}
+			if reserved := tournament.ReservedBudget(); reserved != tc.reserved {
+				t.Fatalf("expected %d, got %d", tc.reserved, reserved)
+			}
+		})
+	}
+}
+
+func TestValidateGuarantee(t *testing.T) {
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		sponsor  string
+		err      error
+	}{
+		{name: "no guarantee", settings: TournamentSettings{Deposit: 100}},
+		{name: "default sponsor", settings: TournamentSettings{Deposit: 100, GuaranteedPrize: 1000},
+			sponsor: SponsorAccount},
+		{name: "freeroll", settings: TournamentSettings{GuaranteedPrize: 1000, Sponsor: "marketing"},
+			sponsor: "marketing"},
+		{name: "sponsor without guarantee", settings: TournamentSettings{Deposit: 100, Sponsor: "marketing"},
+			sponsor: "marketing", err: ErrInvalidGuarantee},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if tc.settings.Sponsor != tc.sponsor {
+				t.Fatalf("expected sponsor %q, got %q", tc.sponsor, tc.settings.Sponsor)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package sts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxIdempotencyKeyLength is the longest idempotency key that can be stored.
+	MaxIdempotencyKeyLength = 255
+
+	// DefaultIdempotencyKeyTTL is how long idempotency keys are kept unless it's configured otherwise.
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+)
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx that makes an operation take effect once however many times
+// it's retried with the same key. Credit, Debit, JoinTournament and JoinTournamentAsTeam accept keys.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKey returns a key that has been put to ctx by WithIdempotencyKey.
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok
+}
+
+// IdempotentRequest describes an operation and its payload, so that an idempotency key that has been used
+// for one request can't be reused for another one.
+func IdempotentRequest(operation string, args ...interface{}) string {
+	var b strings.Builder
+	b.WriteString(operation)
+	for _, arg := range args {
+		fmt.Fprintf(&b, " %v", arg)
+	}
+	return b.String()
+}
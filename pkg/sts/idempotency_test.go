@@ -0,0 +1,44 @@
+package sts
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	_, ok := IdempotencyKey(context.Background())
+	if ok {
+		t.Fatalf("expected no key")
+	}
+	key, ok := IdempotencyKey(WithIdempotencyKey(context.Background(), "a1b2"))
+	if !ok || key != "a1b2" {
+		t.Fatalf("expected key a1b2, got %q", key)
+	}
+}
+
+func TestIdempotentRequest(t *testing.T) {
+	tt := []struct {
+		name      string
+		operation string
+		args      []interface{}
+		request   string
+	}{
+		{name: "no args", operation: "take", request: "take"},
+		{
+			name:      "credit",
+			operation: "fund",
+			args:      []interface{}{int64(1), DefaultCurrency, uint64(7), time.Hour},
+			request:   "fund 1 bonus 7 1h0m0s",
+		},
+		{name: "empty invite code", operation: "join", args: []interface{}{int64(3), int64(1), ""}, request: "join 3 1 "},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := IdempotentRequest(tc.operation, tc.args...)
+			if request != tc.request {
+				t.Fatalf("expected %q, got %q", tc.request, request)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package sts
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+)
+
+// Visibility defines who can see and join a tournament.
+type Visibility string
+
+const (
+	// VisibilityPublic tournaments are listed for everyone and can be joined by anyone.
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate tournaments are listed only for their members and can be joined
+	// with an invite code only.
+	VisibilityPrivate Visibility = "private"
+)
+
+// inviteCodeBytes is a number of random bytes in an invite code.
+const inviteCodeBytes = 20
+
+// InviteCode allows users to join a private tournament. Zero MaxUses means that the code
+// can be used any number of times, nil ExpiresAt means that it never expires.
+type InviteCode struct {
+	Code         string     `json:"code"`
+	TournamentID int64      `json:"tournamentId"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	MaxUses      uint32     `json:"maxUses"`
+	Uses         uint32     `json:"uses"`
+	Revoked      bool       `json:"revoked"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// InviteSettings are limits of a new invite code.
+type InviteSettings struct {
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	MaxUses   uint32     `json:"maxUses"`
+}
+
+// NewInviteCode returns a random invite code that can't be guessed.
+func NewInviteCode() (string, error) {
+	b := make([]byte, inviteCodeBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)), nil
+}
+
+// Valid checks that code isn't revoked, expired or exhausted at passed time.
+func (c InviteCode) Valid(now time.Time) bool {
+	if c.Revoked {
+		return false
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return false
+	}
+	return c.MaxUses == 0 || c.Uses < c.MaxUses
+}
+
+// IsPrivate returns true if tournament can be joined with an invite code only.
+func (s TournamentSettings) IsPrivate() bool {
+	return s.Visibility == VisibilityPrivate
+}
+
+func validateVisibility(s TournamentSettings) error {
+	switch s.Visibility {
+	case VisibilityPublic:
+		return nil
+	case VisibilityPrivate:
+		if s.OrganizerID == 0 {
+			return ErrInvalidVisibility
+		}
+		return nil
+	}
+	return ErrInvalidVisibility
+}
+
+// CheckOrganizer returns ErrPublicTournament if tournament doesn't have invite codes and
+// ErrNotOrganizer if user with passed userID doesn't organize it.
+func (s TournamentSettings) CheckOrganizer(userID int64) error {
+	if !s.IsPrivate() {
+		return ErrPublicTournament
+	}
+	if userID != s.OrganizerID {
+		return ErrNotOrganizer
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package sts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewInviteCode(t *testing.T) {
+	code, err := NewInviteCode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(code) != 32 {
+		t.Fatalf("expected code of 32 characters, got %q", code)
+	}
+	other, err := NewInviteCode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code == other {
+		t.Fatalf("expected different codes, got %q twice", code)
+	}
+}
+
+func TestInviteCodeValid(t *testing.T) {
+	now := time.Date(2019, 10, 28, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	tt := []struct {
+		name  string
+		code  InviteCode
+		valid bool
+	}{
+		{name: "unlimited", code: InviteCode{}, valid: true},
+		{name: "not expired", code: InviteCode{ExpiresAt: &future}, valid: true},
+		{name: "expired", code: InviteCode{ExpiresAt: &past}},
+		{name: "expires now", code: InviteCode{ExpiresAt: &now}},
+		{name: "uses left", code: InviteCode{MaxUses: 2, Uses: 1}, valid: true},
+		{name: "exhausted", code: InviteCode{MaxUses: 2, Uses: 2}},
+		{name: "revoked", code: InviteCode{Revoked: true}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if valid := tc.code.Valid(now); valid != tc.valid {
+				t.Fatalf("expected %v, got %v", tc.valid, valid)
+			}
+		})
+	}
+}
+
+func TestValidateVisibility(t *testing.T) {
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		err      error
+	}{
+		{name: "public", settings: TournamentSettings{Visibility: VisibilityPublic}},
+		{name: "private", settings: TournamentSettings{Visibility: VisibilityPrivate, OrganizerID: 1}},
+		{name: "private without organizer", settings: TournamentSettings{Visibility: VisibilityPrivate},
+			err: ErrInvalidVisibility},
+		{name: "unknown", settings: TournamentSettings{Visibility: "hidden"}, err: ErrInvalidVisibility},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateVisibility(tc.settings); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
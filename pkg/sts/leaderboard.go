@@ -0,0 +1,146 @@
+package sts
+
+import "time"
+
+// Period is a time window of a leaderboard.
+type Period string
+
+const (
+	// PeriodAllTime covers every finished tournament.
+	PeriodAllTime Period = "all"
+	// PeriodMonth covers tournaments finished in a calendar month.
+	PeriodMonth Period = "month"
+	// PeriodWeek covers tournaments finished in a week starting on Monday.
+	PeriodWeek Period = "week"
+)
+
+// Periods lists every leaderboard period.
+var Periods = []Period{PeriodAllTime, PeriodMonth, PeriodWeek}
+
+// LeaderboardOrder is a statistic that leaderboard is ranked by.
+type LeaderboardOrder string
+
+const (
+	// OrderPrize ranks users by total prize won.
+	OrderPrize LeaderboardOrder = "prize"
+	// OrderWins ranks users by number of tournaments won.
+	OrderWins LeaderboardOrder = "wins"
+	// OrderProfit ranks users by prizes minus entry fees.
+	OrderProfit LeaderboardOrder = "profit"
+)
+
+// LeaderboardEntry holds statistics of a single user in a leaderboard period. Users with
+// equal statistic share the same rank.
+type LeaderboardEntry struct {
+	Rank        uint64 `json:"rank"`
+	UserID      int64  `json:"userId"`
+	Prize       uint64 `json:"prize"`
+	Wins        uint32 `json:"wins"`
+	Tournaments uint32 `json:"tournaments"`
+	Profit      int64  `json:"profit"`
+}
+
+// LeaderboardQuery selects a page of a leaderboard. Zero At means the current period.
+// If UserID isn't zero, rank of this user is returned as well.
+type LeaderboardQuery struct {
+	Period Period
+	Order  LeaderboardOrder
+	At     time.Time
+	Offset uint64
+	Limit  uint64
+	UserID int64
+}
+
+// Leaderboard is a page of leaderboard entries and an entry of requested user, if the user
+// has finished any tournament in the period.
+type Leaderboard struct {
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry  `json:"me,omitempty"`
+}
+
+// Validate fills omitted query parameters with default values and checks that query is correct.
+func (q *LeaderboardQuery) Validate() error {
+	if q.Period == "" {
+		q.Period = PeriodAllTime
+	}
+	if q.Order == "" {
+		q.Order = OrderPrize
+	}
+	switch q.Period {
+	case PeriodAllTime, PeriodMonth, PeriodWeek:
+	default:
+		return ErrInvalidLeaderboard
+	}
+	switch q.Order {
+	case OrderPrize, OrderWins, OrderProfit:
+	default:
+		return ErrInvalidLeaderboard
+	}
+	if q.Limit == 0 {
+		return ErrInvalidLeaderboard
+	}
+	return nil
+}
+
+// PeriodStart returns the beginning of period that contains passed time in UTC.
+// All-time period starts at Unix epoch.
+func PeriodStart(p Period, t time.Time) time.Time {
+	t = t.UTC()
+	switch p {
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case PeriodWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// Value returns statistic of entry that leaderboard is ranked by.
+func (e LeaderboardEntry) Value(order LeaderboardOrder) int64 {
+	switch order {
+	case OrderWins:
+		return int64(e.Wins)
+	case OrderProfit:
+		return e.Profit
+	}
+	return int64(e.Prize)
+}
+
+// RankEntries sets ranks of a page of entries ordered by passed statistic. firstRank is
+// the rank of the first entry and offset is its position in the whole leaderboard.
+func RankEntries(entries []LeaderboardEntry, order LeaderboardOrder, firstRank, offset uint64) {
+	for i := range entries {
+		if i == 0 {
+			entries[i].Rank = firstRank
+			continue
+		}
+		if entries[i].Value(order) == entries[i-1].Value(order) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = offset + uint64(i) + 1
+		}
+	}
+}
+
+// RankingResults returns results of ranked users that have paid passed deposit.
+func RankingResults(ranking []int64, payouts []Payout, deposit uint64) []LeaderboardEntry {
+	prizes := make(map[int64]uint64, len(payouts))
+	for _, p := range payouts {
+		prizes[p.UserID] += p.Amount
+	}
+	results := make([]LeaderboardEntry, 0, len(ranking))
+	for i, userID := range ranking {
+		r := LeaderboardEntry{
+			UserID:      userID,
+			Prize:       prizes[userID],
+			Tournaments: 1,
+			Profit:      int64(prizes[userID]) - int64(deposit),
+		}
+		if i == 0 {
+			r.Wins = 1
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,69 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	at := time.Date(2019, 10, 10, 15, 30, 0, 0, time.UTC)
+	tt := []struct {
+		period   Period
+		expected time.Time
+	}{
+		{period: PeriodAllTime, expected: time.Unix(0, 0).UTC()},
+		{period: PeriodMonth, expected: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{period: PeriodWeek, expected: time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tt {
+		t.Run(string(tc.period), func(t *testing.T) {
+			if start := PeriodStart(tc.period, at); !start.Equal(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, start)
+			}
+		})
+	}
+	sunday := time.Date(2019, 10, 13, 23, 0, 0, 0, time.UTC)
+	if start := PeriodStart(PeriodWeek, sunday); !start.Equal(time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected start of week %v", start)
+	}
+}
+
+func TestRankEntries(t *testing.T) {
+	entries := []LeaderboardEntry{
+		{UserID: 1, Wins: 3},
+		{UserID: 2, Wins: 3},
+		{UserID: 3, Wins: 2},
+		{UserID: 4, Wins: 1},
+	}
+	RankEntries(entries, OrderWins, 9, 10)
+	var ranks []uint64
+	for _, e := range entries {
+		ranks = append(ranks, e.Rank)
+	}
+	if expected := []uint64{9, 9, 13, 14}; !reflect.DeepEqual(ranks, expected) {
+		t.Fatalf("expected ranks %v, got %v", expected, ranks)
+	}
+}
+
+func TestLeaderboardQueryValidate(t *testing.T) {
+	q := LeaderboardQuery{Limit: 20}
+	if err := q.Validate(); err != nil || q.Period != PeriodAllTime || q.Order != OrderPrize {
+		t.Fatalf("expected default query, got %+v and %v", q, err)
+	}
+	q = LeaderboardQuery{Period: "year", Limit: 20}
+	if err := q.Validate(); err != ErrInvalidLeaderboard {
+		t.Fatalf("expected %v, got %v", ErrInvalidLeaderboard, err)
+	}
+}
+
+func TestRankingResults(t *testing.T) {
+	payouts := RankingPayouts(300, []uint32{100}, []int64{2, 1})
+	expected := []LeaderboardEntry{
+		{UserID: 2, Prize: 300, Wins: 1, Tournaments: 1, Profit: 150},
+		{UserID: 1, Tournaments: 1, Profit: -150},
+	}
+	if results := RankingResults([]int64{2, 1}, payouts, 150); !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %v, got %v", expected, results)
+	}
+}
@@ -0,0 +1,36 @@
+package sts
+
+import "time"
+
+// Reason describes why user balance has changed.
+type Reason string
+
+const (
+	// ReasonFund is a reason of points that were given to user.
+	ReasonFund Reason = "fund"
+	// ReasonTake is a reason of points that were taken from user.
+	ReasonTake Reason = "take"
+	// ReasonEntryFee is a reason of deposit that was paid to join a tournament.
+	ReasonEntryFee Reason = "entry_fee"
+	// ReasonPrize is a reason of points that were won in a tournament.
+	ReasonPrize Reason = "prize"
+	// ReasonRefund is a reason of deposit that was returned from a tournament.
+	ReasonRefund Reason = "refund"
+	// ReasonExpiry is a reason of credited points that weren't spent before they expired.
+	ReasonExpiry Reason = "expiry"
+	// ReasonTransfer is a reason of points that were sent to or received from another user.
+	ReasonTransfer Reason = "transfer"
+)
+
+// Transaction represents a single change of user balance.
+type Transaction struct {
+	ID           int64    `json:"id"`
+	UserID       int64    `json:"userId"`
+	Amount       int64    `json:"amount"`
+	Currency     Currency `json:"currency"`
+	Reason       Reason   `json:"reason"`
+	TournamentID int64    `json:"tournamentId,omitempty"`
+	// PeerID is set for transfers and holds the user who sent or received the points.
+	PeerID    int64     `json:"peerId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,143 @@
+package sts
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// TournamentSort is an order of listed tournaments.
+type TournamentSort string
+
+const (
+	// SortNewest lists the most recently created tournaments first.
+	SortNewest TournamentSort = "newest"
+	// SortLargestPrize lists tournaments with the largest prize first.
+	SortLargestPrize TournamentSort = "prize"
+	// SortSoonestStart lists tournaments that start the soonest first. Tournaments without
+	// start time aren't listed.
+	SortSoonestStart TournamentSort = "start"
+)
+
+// TournamentQuery selects a page of tournaments. Zero filters don't restrict anything.
+// After is a cursor of the last tournament of the previous page.
+type TournamentQuery struct {
+	// ViewerID is a user that lists tournaments. Besides public tournaments, private ones are
+	// listed when the viewer organizes them, participates in them or waits for a seat.
+	ViewerID      int64
+	Status        Status
+	MinDeposit    uint64
+	MaxDeposit    uint64
+	NamePrefix    string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          TournamentSort
+	After         string
+	Limit         uint64
+}
+
+// TournamentPage is a page of listed tournaments. NextCursor is empty on the last page.
+type TournamentPage struct {
+	Tournaments []Tournament `json:"tournaments"`
+	NextCursor  string       `json:"nextCursor,omitempty"`
+}
+
+// UserQuery selects a page of users ordered by id. After is a cursor of the last user of the previous page.
+type UserQuery struct {
+	After string
+	Limit uint64
+}
+
+// UserPage is a page of listed users. NextCursor is empty on the last page.
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Validate fills omitted query parameters with default values and checks that query is correct.
+func (q *TournamentQuery) Validate() error {
+	if q.Sort == "" {
+		q.Sort = SortNewest
+	}
+	switch q.Sort {
+	case SortNewest, SortLargestPrize, SortSoonestStart:
+	default:
+		return ErrInvalidListing
+	}
+	switch q.Status {
+	case "", StatusDraft, StatusRegistration, StatusRunning, StatusFinished, StatusCancelled:
+	default:
+		return ErrInvalidListing
+	}
+	if q.MaxDeposit != 0 && q.MinDeposit > q.MaxDeposit {
+		return ErrInvalidListing
+	}
+	if q.CreatedAfter != nil && q.CreatedBefore != nil && !q.CreatedAfter.Before(*q.CreatedBefore) {
+		return ErrInvalidListing
+	}
+	if q.Limit == 0 {
+		return ErrInvalidListing
+	}
+	_, _, err := DecodeCursor(q.After)
+	return err
+}
+
+// Validate checks that query is correct.
+func (q UserQuery) Validate() error {
+	if q.Limit == 0 {
+		return ErrInvalidListing
+	}
+	_, _, err := DecodeCursor(q.After)
+	return err
+}
+
+// Key returns value of t that tournaments are ordered by. Times are Unix nanoseconds.
+func (s TournamentSort) Key(t *Tournament) int64 {
+	switch s {
+	case SortLargestPrize:
+		return int64(t.Prize)
+	case SortSoonestStart:
+		if t.StartsAt == nil {
+			return 0
+		}
+		return t.StartsAt.UnixNano()
+	}
+	return t.CreatedAt.UnixNano()
+}
+
+// TournamentCursor returns a cursor of t in a listing with passed sort.
+func TournamentCursor(t *Tournament, sort TournamentSort) string {
+	return EncodeCursor(sort.Key(t), t.ID)
+}
+
+// UserCursor returns a cursor of user with passed id in a listing of users.
+func UserCursor(id int64) string {
+	return EncodeCursor(id, id)
+}
+
+// EncodeCursor returns an opaque cursor of an item with passed id and sort key.
+func EncodeCursor(key, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", key, id)))
+}
+
+// DecodeCursor returns sort key and id of an item that cursor points to. Empty cursor points
+// before the first item and has zero id. If cursor is malformed, function returns ErrInvalidListing.
+func DecodeCursor(cursor string) (key, id int64, err error) {
+	if cursor == "" {
+		return 0, 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, ErrInvalidListing
+	}
+	_, err = fmt.Sscanf(string(b), "%d:%d", &key, &id)
+	if err != nil || id < 1 {
+		return 0, 0, ErrInvalidListing
+	}
+	return key, id, nil
+}
+
+// CursorTime returns time that is a sort key of a cursor.
+func CursorTime(key int64) time.Time {
+	return time.Unix(0, key).UTC()
+}
@@ -0,0 +1,74 @@
+package sts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTournamentQueryValidate(t *testing.T) {
+	q := TournamentQuery{Limit: 20}
+	if err := q.Validate(); err != nil || q.Sort != SortNewest {
+		t.Fatalf("expected default query, got %+v and %v", q, err)
+	}
+	after := time.Date(2019, 12, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name  string
+		query TournamentQuery
+	}{
+		{name: "unknown sort", query: TournamentQuery{Sort: "oldest", Limit: 20}},
+		{name: "unknown status", query: TournamentQuery{Status: "paused", Limit: 20}},
+		{name: "deposit range", query: TournamentQuery{MinDeposit: 100, MaxDeposit: 10, Limit: 20}},
+		{name: "creation range", query: TournamentQuery{CreatedAfter: &after, CreatedBefore: &before, Limit: 20}},
+		{name: "zero limit", query: TournamentQuery{}},
+		{name: "malformed cursor", query: TournamentQuery{After: "abc", Limit: 20}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.query.Validate(); err != ErrInvalidListing {
+				t.Fatalf("expected %v, got %v", ErrInvalidListing, err)
+			}
+		})
+	}
+}
+
+func TestTournamentCursor(t *testing.T) {
+	startsAt := time.Date(2019, 12, 10, 18, 0, 0, 0, time.UTC)
+	tournament := Tournament{
+		ID:                 7,
+		TournamentSettings: TournamentSettings{StartsAt: &startsAt},
+		CreatedAt:          time.Date(2019, 12, 1, 9, 30, 0, 0, time.UTC),
+		Prize:              900,
+	}
+	tt := []struct {
+		sort     TournamentSort
+		expected int64
+	}{
+		{sort: SortNewest, expected: tournament.CreatedAt.UnixNano()},
+		{sort: SortLargestPrize, expected: 900},
+		{sort: SortSoonestStart, expected: startsAt.UnixNano()},
+	}
+	for _, tc := range tt {
+		t.Run(string(tc.sort), func(t *testing.T) {
+			key, id, err := DecodeCursor(TournamentCursor(&tournament, tc.sort))
+			if err != nil || key != tc.expected || id != 7 {
+				t.Fatalf("expected key %d and id 7, got %d, %d and %v", tc.expected, key, id, err)
+			}
+		})
+	}
+	if at := CursorTime(tournament.CreatedAt.UnixNano()); !at.Equal(tournament.CreatedAt) {
+		t.Fatalf("expected %v, got %v", tournament.CreatedAt, at)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	key, id, err := DecodeCursor("")
+	if key != 0 || id != 0 || err != nil {
+		t.Fatalf("expected empty cursor, got %d, %d and %v", key, id, err)
+	}
+	for _, cursor := range []string{"!", EncodeCursor(5, 0), "MTIz"} {
+		if _, _, err := DecodeCursor(cursor); err != ErrInvalidListing {
+			t.Fatalf("expected %v for cursor %s, got %v", ErrInvalidListing, cursor, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package sts
+
+import "sort"
+
+// Result is an outcome of a game for the first user of a pairing.
+type Result string
+
+const (
+	// ResultNone means that the game hasn't been reported yet.
+	ResultNone Result = ""
+	// ResultWin means that the first user has won.
+	ResultWin Result = "win"
+	// ResultDraw means that the game has ended in a draw.
+	ResultDraw Result = "draw"
+	// ResultLoss means that the first user has lost.
+	ResultLoss Result = "loss"
+)
+
+// Pairing represents a single game of a round-robin or Swiss tournament. Zero User2 means
+// that User1 has a bye, which counts as a win in Swiss tournaments.
+type Pairing struct {
+	Round  uint32 `json:"round"`
+	Board  uint32 `json:"board"`
+	User1  int64  `json:"user1"`
+	User2  int64  `json:"user2,omitempty"`
+	Result Result `json:"result,omitempty"`
+}
+
+// Pairings holds games of a tournament ordered by round and board.
+type Pairings []Pairing
+
+// Standing holds score of a single user. Win gives a point and draw gives half a point.
+// Buchholz is a sum of points of all opponents the user has played.
+type Standing struct {
+	UserID   int64   `json:"userId"`
+	Points   float64 `json:"points"`
+	Buchholz float64 `json:"buchholz"`
+	Wins     uint32  `json:"wins"`
+	Draws    uint32  `json:"draws"`
+	Losses   uint32  `json:"losses"`
+}
+
+// LastRound returns the number of the last paired round or zero if nothing is paired yet.
+func (p Pairings) LastRound() uint32 {
+	if len(p) == 0 {
+		return 0
+	}
+	return p[len(p)-1].Round
+}
+
+// RoundFinished reports whether every game of the last paired round has been reported.
+func (p Pairings) RoundFinished() bool {
+	last := p.LastRound()
+	for _, g := range p {
+		if g.Round == last && g.Result == ResultNone {
+			return false
+		}
+	}
+	return true
+}
+
+// Pairing returns game of passed round and board or nil if there is no such game.
+func (p Pairings) Pairing(round, board uint32) *Pairing {
+	for i := range p {
+		if p[i].Round == round && p[i].Board == board {
+			return &p[i]
+		}
+	}
+	return nil
+}
+
+// Report records result of game with passed round and board. If game isn't found, function
+// returns ErrNotFound. If result isn't a win, draw or loss, function returns ErrInvalidResult.
+// If game has already been reported or is a bye, function returns ErrMatchReported.
+func (p Pairings) Report(round, board uint32, result Result) error {
+	g := p.Pairing(round, board)
+	if g == nil {
+		return ErrNotFound
+	}
+	if result != ResultWin && result != ResultDraw && result != ResultLoss {
+		return ErrInvalidResult
+	}
+	if g.Result != ResultNone {
+		return ErrMatchReported
+	}
+	g.Result = result
+	return nil
+}
+
+// PairRoundRobin returns games of passed round of a round-robin tournament, so that every
+// user meets every other user once in TotalRounds rounds. With odd number of users
+// one of them sits out each round.
+func PairRoundRobin(users []int64, round uint32) Pairings {
+	circle := append([]int64(nil), users...)
+	sort.Slice(circle, func(i, j int) bool { return circle[i] < circle[j] })
+	if len(circle)%2 == 1 {
+		circle = append(circle, 0)
+	}
+	n := len(circle)
+	if n < 2 {
+		return nil
+	}
+	// The first user stays in place while the others rotate by one seat each round.
+	seat := func(i int) int64 {
+		if i == 0 {
+			return circle[0]
+		}
+		return circle[1+(i-1+int(round)-1)%(n-1)]
+	}
+	var pairings Pairings
+	for i := 0; i < n/2; i++ {
+		user1, user2 := seat(i), seat(n-1-i)
+		if user1 == 0 || user2 == 0 {
+			continue
+		}
+		pairings = append(pairings, Pairing{
+			Round: round,
+			Board: uint32(len(pairings) + 1),
+			User1: user1,
+			User2: user2,
+		})
+	}
+	return pairings
+}
+
+// PairSwiss returns games of passed round of a Swiss tournament. Users are paired in the order
+// of their standings with the next user they haven't played yet, if there is one. With odd
+// number of users the lowest ranked user that hasn't had a bye yet gets one.
+func PairSwiss(users []int64, previous Pairings, round uint32) Pairings {
+	played := make(map[[2]int64]bool)
+	hadBye := make(map[int64]bool)
+	for _, g := range previous {
+		if g.User2 == 0 {
+			hadBye[g.User1] = true
+			continue
+		}
+		played[[2]int64{g.User1, g.User2}] = true
+		played[[2]int64{g.User2, g.User1}] = true
+	}
+	var order []int64
+	for _, s := range Standings(users, previous) {
+		order = append(order, s.UserID)
+	}
+
+	var bye int64
+	if len(order)%2 == 1 {
+		i := len(order) - 1
+		for i > 0 && hadBye[order[i]] {
+			i--
+		}
+		bye = order[i]
+		order = append(order[:i], order[i+1:]...)
+	}
+
+	var pairings Pairings
+	for len(order) > 1 {
+		opponent := 1
+		for i := 1; i < len(order); i++ {
+			if !played[[2]int64{order[0], order[i]}] {
+				opponent = i
+				break
+			}
+		}
+		pairings = append(pairings, Pairing{
+			Round: round,
+			Board: uint32(len(pairings) + 1),
+			User1: order[0],
+			User2: order[opponent],
+		})
+		order = append(order[1:opponent], order[opponent+1:]...)
+	}
+	if bye != 0 {
+		pairings = append(pairings, Pairing{
+			Round:  round,
+			Board:  uint32(len(pairings) + 1),
+			User1:  bye,
+			Result: ResultWin,
+		})
+	}
+	return pairings
+}
+
+// Standings returns scores of passed users from the leader. Ties are broken by Buchholz,
+// then by the number of wins and then by user id.
+func Standings(users []int64, pairings Pairings) []Standing {
+	standings := make([]Standing, len(users))
+	index := make(map[int64]*Standing, len(users))
+	for i, id := range users {
+		standings[i].UserID = id
+		index[id] = &standings[i]
+	}
+	record := func(id int64, points float64) {
+		s, ok := index[id]
+		if !ok {
+			return
+		}
+		s.Points += points
+		switch points {
+		case 1:
+			s.Wins++
+		case 0.5:
+			s.Draws++
+		default:
+			s.Losses++
+		}
+	}
+	for _, g := range pairings {
+		switch g.Result {
+		case ResultWin:
+			record(g.User1, 1)
+			if g.User2 != 0 {
+				record(g.User2, 0)
+			}
+		case ResultDraw:
+			record(g.User1, 0.5)
+			record(g.User2, 0.5)
+		case ResultLoss:
+			record(g.User1, 0)
+			record(g.User2, 1)
+		}
+	}
+	for _, g := range pairings {
+		if g.Result == ResultNone || g.User2 == 0 {
+			continue
+		}
+		if s1, s2 := index[g.User1], index[g.User2]; s1 != nil && s2 != nil {
+			s1.Buchholz += s2.Points
+			s2.Buchholz += s1.Points
+		}
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.UserID < b.UserID
+	})
+	return standings
+}
+
+// StandingsRanking returns users of passed standings from the leader.
+func StandingsRanking(standings []Standing) []int64 {
+	ranking := make([]int64, 0, len(standings))
+	for _, s := range standings {
+		ranking = append(ranking, s.UserID)
+	}
+	return ranking
+}
+
+// Score returns the result of User1 in reported game: 1 for a win, 0.5 for a draw and 0 for a loss.
+func (p Pairing) Score() float64 {
+	switch p.Result {
+	case ResultWin:
+		return 1
+	case ResultDraw:
+		return 0.5
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPairRoundRobin(t *testing.T) {
+	for _, users := range [][]int64{{1, 2, 3, 4}, {1, 2, 3, 4, 5}} {
+		s := TournamentSettings{Format: FormatRoundRobin}
+		met := make(map[[2]int64]int)
+		for round := uint32(1); round <= s.TotalRounds(len(users)); round++ {
+			seen := make(map[int64]bool)
+			for _, g := range PairRoundRobin(users, round) {
+				if seen[g.User1] || seen[g.User2] {
+					t.Fatalf("user plays twice in round %d: %v", round, g)
+				}
+				seen[g.User1], seen[g.User2] = true, true
+				if g.User1 > g.User2 {
+					g.User1, g.User2 = g.User2, g.User1
+				}
+				met[[2]int64{g.User1, g.User2}]++
+			}
+		}
+		if expected := len(users) * (len(users) - 1) / 2; len(met) != expected {
+			t.Fatalf("expected %d different games, got %d", expected, len(met))
+		}
+		for pair, games := range met {
+			if games != 1 {
+				t.Fatalf("users %v have met %d times", pair, games)
+			}
+		}
+	}
+}
+
+func TestPairSwiss(t *testing.T) {
+	users := []int64{1, 2, 3, 4, 5}
+	first := PairSwiss(users, nil, 1)
+	expected := Pairings{
+		{Round: 1, Board: 1, User1: 1, User2: 2},
+		{Round: 1, Board: 2, User1: 3, User2: 4},
+		{Round: 1, Board: 3, User1: 5, Result: ResultWin},
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Fatalf("expected %v, got %v", expected, first)
+	}
+
+	first[0].Result = ResultWin
+	first[1].Result = ResultDraw
+	second := PairSwiss(users, first, 2)
+	expected = Pairings{
+		{Round: 2, Board: 1, User1: 1, User2: 5},
+		{Round: 2, Board: 2, User1: 3, User2: 4},
+		{Round: 2, Board: 3, User1: 2, Result: ResultWin},
+	}
+	if !reflect.DeepEqual(second, expected) {
+		t.Fatalf("expected %v, got %v", expected, second)
+	}
+}
+
+func TestPairingsReport(t *testing.T) {
+	pairings := Pairings{
+		{Round: 1, Board: 1, User1: 1, User2: 2},
+		{Round: 1, Board: 2, User1: 3, Result: ResultWin},
+	}
+	tt := []struct {
+		name   string
+		board  uint32
+		result Result
+		err    error
+	}{
+		{name: "unknown game", board: 3, result: ResultWin, err: ErrNotFound},
+		{name: "bye", board: 2, result: ResultLoss, err: ErrMatchReported},
+		{name: "invalid result", board: 1, result: "lose", err: ErrInvalidResult},
+		{name: "draw", board: 1, result: ResultDraw},
+		{name: "reported twice", board: 1, result: ResultWin, err: ErrMatchReported},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := pairings.Report(1, tc.board, tc.result); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+	if !pairings.RoundFinished() {
+		t.Fatal("expected round to be finished")
+	}
+}
+
+func TestStandings(t *testing.T) {
+	pairings := Pairings{
+		{Round: 1, Board: 1, User1: 1, User2: 2, Result: ResultWin},
+		{Round: 1, Board: 2, User1: 3, User2: 4, Result: ResultDraw},
+		{Round: 2, Board: 1, User1: 1, User2: 3, Result: ResultLoss},
+		{Round: 2, Board: 2, User1: 2, User2: 4, Result: ResultWin},
+	}
+	expected := []Standing{
+		{UserID: 3, Points: 1.5, Buchholz: 1.5, Wins: 1, Draws: 1},
+		{UserID: 1, Points: 1, Buchholz: 2.5, Wins: 1, Losses: 1},
+		{UserID: 2, Points: 1, Buchholz: 1.5, Wins: 1, Losses: 1},
+		{UserID: 4, Points: 0.5, Buchholz: 2.5, Draws: 1, Losses: 1},
+	}
+	standings := Standings([]int64{1, 2, 3, 4}, pairings)
+	if !reflect.DeepEqual(standings, expected) {
+		t.Fatalf("expected %v, got %v", expected, standings)
+	}
+	if ranking := StandingsRanking(standings); !reflect.DeepEqual(ranking, []int64{3, 1, 2, 4}) {
+		t.Fatalf("unexpected ranking %v", ranking)
+	}
+}
@@ -0,0 +1,111 @@
+package sts
+
+// DefaultPrizeShares is used when tournament is created without prize shares:
+// the whole prize goes to the winner.
+var DefaultPrizeShares = []uint32{100}
+
+// Payout represents a part of tournament prize that is paid to a certain place.
+type Payout struct {
+	Place  uint32 `json:"place"`
+	Share  uint32 `json:"share"`
+	Amount uint64 `json:"amount"`
+	// UserID is set after tournament has finished. TeamID is set instead of it when tournament
+	// is team-based.
+	UserID int64 `json:"userId,omitempty"`
+	TeamID int64 `json:"teamId,omitempty"`
+}
+
+// ValidatePrizeShares checks that every share is positive and all of them sum up to 100.
+func ValidatePrizeShares(shares []uint32) error {
+	if len(shares) == 0 {
+		return ErrInvalidPrizeShares
+	}
+	var sum uint32
+	for _, share := range shares {
+		if share == 0 || share > 100 {
+			return ErrInvalidPrizeShares
+		}
+		sum += share
+	}
+	if sum != 100 {
+		return ErrInvalidPrizeShares
+	}
+	return nil
+}
+
+// SplitPrize splits prize proportionally to passed shares. Every amount is rounded down and
+// the remaining points are handed out one by one starting from the first place, so the sum of
+// returned amounts is always equal to prize.
+func SplitPrize(prize uint64, shares []uint32) []uint64 {
+	var total uint64
+	for _, share := range shares {
+		total += uint64(share)
+	}
+	amounts := make([]uint64, len(shares))
+	if total == 0 {
+		return amounts
+	}
+	var paid uint64
+	for i, share := range shares {
+		amounts[i] = prize / total * uint64(share)
+		amounts[i] += prize % total * uint64(share) / total
+		paid += amounts[i]
+	}
+	for i := 0; paid < prize; i = (i + 1) % len(amounts) {
+		amounts[i]++
+		paid++
+	}
+	return amounts
+}
+
+// RankingPayouts returns payouts of ranked users. When there are fewer users than paid places,
+// shares of the remaining places are distributed between ranked users proportionally.
+func RankingPayouts(prize uint64, shares []uint32, ranking []int64) []Payout {
+	if len(shares) > len(ranking) {
+		shares = shares[:len(ranking)]
+	}
+	payouts := PlannedPayouts(prize, shares)
+	for i := range payouts {
+		payouts[i].UserID = ranking[i]
+	}
+	return payouts
+}
+
+// ValidateRanking checks that ranking contains every participant exactly once.
+func ValidateRanking(ranking, participants []int64) error {
+	if len(ranking) == 0 {
+		return ErrInvalidRanking
+	}
+	joined := make(map[int64]bool, len(participants))
+	for _, id := range participants {
+		joined[id] = true
+	}
+	ranked := make(map[int64]bool, len(ranking))
+	for _, id := range ranking {
+		if !joined[id] {
+			return ErrNotParticipant
+		}
+		if ranked[id] {
+			return ErrInvalidRanking
+		}
+		ranked[id] = true
+	}
+	if len(ranked) != len(joined) {
+		return ErrInvalidRanking
+	}
+	return nil
+}
+
+// PlannedPayouts returns payouts of every paid place calculated from the current prize.
+func PlannedPayouts(prize uint64, shares []uint32) []Payout {
+	amounts := SplitPrize(prize, shares)
+	payouts := make([]Payout, 0, len(shares))
+	for i, share := range shares {
+		payouts = append(payouts, Payout{
+			Place:  uint32(i + 1),
+			Share:  share,
+			Amount: amounts[i],
+		})
+	}
+	return payouts
+}
@@ -0,0 +1,110 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPrize(t *testing.T) {
+	tt := []struct {
+		name    string
+		prize   uint64
+		shares  []uint32
+		amounts []uint64
+	}{
+		{
+			name:    "whole prize",
+			prize:   1000,
+			shares:  []uint32{100},
+			amounts: []uint64{1000},
+		},
+		{
+			name:    "exact split",
+			prize:   1000,
+			shares:  []uint32{50, 30, 20},
+			amounts: []uint64{500, 300, 200},
+		},
+		{
+			name:    "remainder goes to top places",
+			prize:   101,
+			shares:  []uint32{34, 33, 33},
+			amounts: []uint64{35, 33, 33},
+		},
+		{
+			name:    "remainder is spread",
+			prize:   8,
+			shares:  []uint32{35, 35, 30},
+			amounts: []uint64{3, 3, 2},
+		},
+		{
+			name:    "shares of missing places",
+			prize:   80,
+			shares:  []uint32{50, 30},
+			amounts: []uint64{50, 30},
+		},
+		{
+			name:    "empty prize",
+			prize:   0,
+			shares:  []uint32{60, 40},
+			amounts: []uint64{0, 0},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			amounts := SplitPrize(tc.prize, tc.shares)
+			if !reflect.DeepEqual(tc.amounts, amounts) {
+				t.Fatalf("expected %v, got %v", tc.amounts, amounts)
+			}
+			var sum uint64
+			for _, a := range amounts {
+				sum += a
+			}
+			if sum != tc.prize {
+				t.Fatalf("expected sum %d, got %d", tc.prize, sum)
+			}
+		})
+	}
+}
+
+func TestValidatePrizeShares(t *testing.T) {
+	tt := []struct {
+		name   string
+		shares []uint32
+		err    error
+	}{
+		{name: "single place", shares: []uint32{100}},
+		{name: "three places", shares: []uint32{50, 30, 20}},
+		{name: "empty", shares: []uint32{}, err: ErrInvalidPrizeShares},
+		{name: "less than 100", shares: []uint32{50, 30}, err: ErrInvalidPrizeShares},
+		{name: "zero share", shares: []uint32{100, 0}, err: ErrInvalidPrizeShares},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidatePrizeShares(tc.shares); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateRanking(t *testing.T) {
+	participants := []int64{1, 2, 3}
+	tt := []struct {
+		name    string
+		ranking []int64
+		err     error
+	}{
+		{name: "full ranking", ranking: []int64{3, 1, 2}},
+		{name: "missing participant", ranking: []int64{3, 1}, err: ErrInvalidRanking},
+		{name: "duplicate", ranking: []int64{3, 1, 1}, err: ErrInvalidRanking},
+		{name: "stranger", ranking: []int64{3, 1, 2, 4}, err: ErrNotParticipant},
+		{name: "empty", ranking: nil, err: ErrInvalidRanking},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateRanking(tc.ranking, participants); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package sts
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	// MaxHandleLength fits handles into name column of users in every db.
+	MaxHandleLength = 20
+	// MaxDisplayNameLength is a maximum number of characters of a display name.
+	MaxDisplayNameLength = 50
+	// MaxAvatarURLLength is a maximum number of characters of an avatar URL.
+	MaxAvatarURLLength = 255
+	// MaxBioLength is a maximum number of characters of a bio.
+	MaxBioLength = 500
+)
+
+// handlePattern matches handles of 3 to MaxHandleLength ASCII letters, digits or underscores.
+var handlePattern = regexp.MustCompile(fmt.Sprintf(`^[A-Za-z0-9_]{3,%d}$`, MaxHandleLength))
+
+// countryPattern matches ISO 3166-1 alpha-2 country codes.
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// UserUpdate holds changes of user profile. Nil fields are left as they are, empty optional
+// fields are cleared.
+type UserUpdate struct {
+	Name        *string
+	DisplayName *string
+	AvatarURL   *string
+	Country     *string
+	Bio         *string
+}
+
+// ValidateHandle returns ErrInvalidHandle if name isn't a valid handle. Handles are unique
+// regardless of case.
+func ValidateHandle(name string) error {
+	if !handlePattern.MatchString(name) {
+		return ErrInvalidHandle
+	}
+	return nil
+}
+
+// Validate checks that changed fields of profile of user with passed current handle are correct.
+// Handle is checked only when it changes, so users keep handles given before the handle rules.
+func (u UserUpdate) Validate(current string) error {
+	if u.Name != nil && *u.Name != current {
+		err := ValidateHandle(*u.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if u.DisplayName != nil && utf8.RuneCountInString(*u.DisplayName) > MaxDisplayNameLength {
+		return ErrInvalidProfile
+	}
+	if u.AvatarURL != nil && *u.AvatarURL != "" {
+		if utf8.RuneCountInString(*u.AvatarURL) > MaxAvatarURLLength {
+			return ErrInvalidProfile
+		}
+		avatar, err := url.Parse(*u.AvatarURL)
+		if err != nil || (avatar.Scheme != "http" && avatar.Scheme != "https") || avatar.Host == "" {
+			return ErrInvalidProfile
+		}
+	}
+	if u.Country != nil && *u.Country != "" && !countryPattern.MatchString(*u.Country) {
+		return ErrInvalidProfile
+	}
+	if u.Bio != nil && utf8.RuneCountInString(*u.Bio) > MaxBioLength {
+		return ErrInvalidProfile
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package sts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHandle(t *testing.T) {
+	tt := []struct {
+		name     string
+		handle   string
+		expected error
+	}{
+		{name: "correct handle", handle: "Magnus_1990", expected: nil},
+		{name: "longest handle", handle: strings.Repeat("a", MaxHandleLength), expected: nil},
+		{name: "too short", handle: "ab", expected: ErrInvalidHandle},
+		{name: "too long", handle: strings.Repeat("a", MaxHandleLength+1), expected: ErrInvalidHandle},
+		{name: "space", handle: "ahoi ahoi", expected: ErrInvalidHandle},
+		{name: "not ascii", handle: "Жора", expected: ErrInvalidHandle},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateHandle(tc.handle); err != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestUserUpdateValidate(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	tt := []struct {
+		name     string
+		update   UserUpdate
+		expected error
+	}{
+		{name: "empty update", update: UserUpdate{}, expected: nil},
+		{name: "full update", update: UserUpdate{
+			Name:        str("ahoi"),
+			DisplayName: str("Ахой"),
+			AvatarURL:   str("https://example.com/a.png"),
+			Country:     str("BY"),
+			Bio:         str("chess"),
+		}, expected: nil},
+		{name: "cleared fields", update: UserUpdate{
+			DisplayName: str(""),
+			AvatarURL:   str(""),
+			Country:     str(""),
+			Bio:         str(""),
+		}, expected: nil},
+		{name: "cleared name", update: UserUpdate{Name: str("")}, expected: ErrInvalidHandle},
+		{name: "kept invalid name", update: UserUpdate{Name: str("old name")}, expected: nil},
+		{name: "changed invalid name", update: UserUpdate{Name: str("old name!")}, expected: ErrInvalidHandle},
+		{name: "long display name", update: UserUpdate{DisplayName: str(strings.Repeat("ж", MaxDisplayNameLength+1))},
+			expected: ErrInvalidProfile},
+		{name: "relative avatar", update: UserUpdate{AvatarURL: str("/a.png")}, expected: ErrInvalidProfile},
+		{name: "ftp avatar", update: UserUpdate{AvatarURL: str("ftp://example.com/a.png")}, expected: ErrInvalidProfile},
+		{name: "lowercase country", update: UserUpdate{Country: str("by")}, expected: ErrInvalidProfile},
+		{name: "long bio", update: UserUpdate{Bio: str(strings.Repeat("a", MaxBioLength+1))}, expected: ErrInvalidProfile},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.update.Validate("old name"); err != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package sts
+
+// RakeType defines how the house rake is taken from each deposit.
+type RakeType string
+
+const (
+	// RakeNone means that the whole deposit goes to the prize.
+	RakeNone RakeType = ""
+	// RakePercent means that rake is a percent of each deposit.
+	RakePercent RakeType = "percent"
+	// RakeFixed means that rake is a fixed amount of points taken from each deposit.
+	RakeFixed RakeType = "fixed"
+)
+
+// RakeAccount is a name of the house account that accumulates rake.
+const RakeAccount = "rake"
+
+// HouseAccount represents points of a single currency that belong to the service itself.
+type HouseAccount struct {
+	Name     string   `json:"name"`
+	Currency Currency `json:"currency"`
+	Balance  uint64   `json:"balance"`
+}
+
+// RakeAmount returns the part of a single deposit that is kept by the house.
+func (s TournamentSettings) RakeAmount() uint64 {
+	switch s.RakeType {
+	case RakePercent:
+		return s.Deposit * s.Rake / 100
+	case RakeFixed:
+		return s.Rake
+	}
+	return 0
+}
+
+func validateRake(s TournamentSettings) error {
+	switch s.RakeType {
+	case RakeNone:
+		if s.Rake != 0 {
+			return ErrInvalidRake
+		}
+	case RakePercent:
+		if s.Rake > 100 {
+			return ErrInvalidRake
+		}
+	case RakeFixed:
+		if s.Rake > s.Deposit {
+			return ErrInvalidRake
+		}
+	default:
+		return ErrInvalidRake
+	}
+	return nil
+}
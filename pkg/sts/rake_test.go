@@ -0,0 +1,37 @@
+package sts
+
+import "testing"
+
+func TestRakeAmount(t *testing.T) {
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		rake     uint64
+		err      error
+	}{
+		{name: "no rake", settings: TournamentSettings{Deposit: 1000}},
+		{name: "percent", settings: TournamentSettings{Deposit: 1000, RakeType: RakePercent, Rake: 5}, rake: 50},
+		{name: "percent rounds down", settings: TournamentSettings{Deposit: 15, RakeType: RakePercent, Rake: 10}, rake: 1},
+		{name: "fixed", settings: TournamentSettings{Deposit: 1000, RakeType: RakeFixed, Rake: 30}, rake: 30},
+		{name: "rake without type", settings: TournamentSettings{Deposit: 1000, Rake: 30}, err: ErrInvalidRake},
+		{name: "percent above 100", settings: TournamentSettings{Deposit: 1000, RakeType: RakePercent, Rake: 101},
+			err: ErrInvalidRake},
+		{name: "fixed above deposit", settings: TournamentSettings{Deposit: 10, RakeType: RakeFixed, Rake: 11},
+			err: ErrInvalidRake},
+		{name: "unknown type", settings: TournamentSettings{Deposit: 10, RakeType: "flat"}, err: ErrInvalidRake},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if rake := tc.settings.RakeAmount(); rake != tc.rake {
+				t.Fatalf("expected rake %d, got %d", tc.rake, rake)
+			}
+		})
+	}
+}
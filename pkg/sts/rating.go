@@ -0,0 +1,171 @@
+package sts
+
+import (
+	"math"
+	"time"
+)
+
+// Glicko holds Glicko-2 rating of a user.
+type Glicko struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+// DefaultGlicko is a rating of a user that hasn't played any rated games yet.
+var DefaultGlicko = Glicko{
+	Rating:     1500,
+	Deviation:  350,
+	Volatility: 0.06,
+}
+
+const (
+	// glickoScale converts ratings between Glicko and Glicko-2 scales.
+	glickoScale = 173.7178
+	// glickoTau constrains the change of volatility over time.
+	glickoTau = 0.5
+	// glickoEpsilon is a convergence tolerance of the volatility iteration.
+	glickoEpsilon = 0.000001
+)
+
+// Game is a single rated game. Score is the result of User1: 1 for a win, 0.5 for a draw
+// and 0 for a loss.
+type Game struct {
+	User1 int64
+	User2 int64
+	Score float64
+}
+
+// RatingChange is a rating of a user after a rating update.
+type RatingChange struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"userId"`
+	TournamentID int64     `json:"tournamentId,omitempty"`
+	Rating       float64   `json:"rating"`
+	Deviation    float64   `json:"ratingDeviation"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RankingGames converts ranking of a tournament without tracked games into games where
+// every user has won against every user ranked below.
+func RankingGames(ranking []int64) []Game {
+	var games []Game
+	for i := range ranking {
+		for j := i + 1; j < len(ranking); j++ {
+			games = append(games, Game{
+				User1: ranking[i],
+				User2: ranking[j],
+				Score: 1,
+			})
+		}
+	}
+	return games
+}
+
+// RateGames returns new ratings and numbers of played games of every user that has played
+// passed games. All games are rated as a single rating period against ratings before it.
+// Users missing in ratings are treated as having DefaultGlicko.
+func RateGames(ratings map[int64]Glicko, games []Game) (map[int64]Glicko, map[int64]int) {
+	rating := func(id int64) Glicko {
+		if g, ok := ratings[id]; ok {
+			return g
+		}
+		return DefaultGlicko
+	}
+	results := make(map[int64][]glickoResult)
+	for _, g := range games {
+		results[g.User1] = append(results[g.User1], glickoResult{opponent: rating(g.User2), score: g.Score})
+		results[g.User2] = append(results[g.User2], glickoResult{opponent: rating(g.User1), score: 1 - g.Score})
+	}
+	updated := make(map[int64]Glicko, len(results))
+	played := make(map[int64]int, len(results))
+	for id, r := range results {
+		updated[id] = rating(id).update(r)
+		played[id] = len(r)
+	}
+	return updated, played
+}
+
+type glickoResult struct {
+	opponent Glicko
+	score    float64
+}
+
+// update returns rating after passed results of a single rating period as described in
+// http://www.glicko.net/glicko/glicko2.pdf.
+func (g Glicko) update(results []glickoResult) Glicko {
+	mu := (g.Rating - DefaultGlicko.Rating) / glickoScale
+	phi := g.Deviation / glickoScale
+	if len(results) == 0 {
+		return Glicko{
+			Rating:     g.Rating,
+			Deviation:  math.Min(math.Sqrt(phi*phi+g.Volatility*g.Volatility)*glickoScale, DefaultGlicko.Deviation),
+			Volatility: g.Volatility,
+		}
+	}
+
+	var variance, improvement float64
+	for _, r := range results {
+		muJ := (r.opponent.Rating - DefaultGlicko.Rating) / glickoScale
+		phiJ := r.opponent.Deviation / glickoScale
+		gJ := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+		variance += gJ * gJ * expected * (1 - expected)
+		improvement += gJ * (r.score - expected)
+	}
+	variance = 1 / variance
+	delta := variance * improvement
+
+	a := math.Log(g.Volatility * g.Volatility)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-phi*phi-variance-ex)/(2*d*d) - (x-a)/(glickoTau*glickoTau)
+	}
+	lower, upper := a, 0.0
+	if delta*delta > phi*phi+variance {
+		upper = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		upper = a - k*glickoTau
+	}
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > glickoEpsilon {
+		c := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fC := f(c)
+		if fC*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = c, fC
+	}
+	volatility := math.Exp(lower / 2)
+
+	phiStar := math.Sqrt(phi*phi + volatility*volatility)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	newMu := mu + newPhi*newPhi*improvement
+	return Glicko{
+		Rating:     newMu*glickoScale + DefaultGlicko.Rating,
+		Deviation:  newPhi * glickoScale,
+		Volatility: volatility,
+	}
+}
+
+// InRatingRange reports whether user with passed rating may join tournament.
+func (s TournamentSettings) InRatingRange(rating float64) bool {
+	if s.MinRating != 0 && rating < float64(s.MinRating) {
+		return false
+	}
+	return s.MaxRating == 0 || rating <= float64(s.MaxRating)
+}
+
+func validateRatingRange(s TournamentSettings) error {
+	if s.MaxRating != 0 && s.MinRating > s.MaxRating {
+		return ErrInvalidRatingRange
+	}
+	return nil
+}
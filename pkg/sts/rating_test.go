@@ -0,0 +1,57 @@
+package sts
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRateGames(t *testing.T) {
+	// Example from the description of Glicko-2 system.
+	ratings := map[int64]Glicko{
+		1: {Rating: 1500, Deviation: 200, Volatility: 0.06},
+		2: {Rating: 1400, Deviation: 30, Volatility: 0.06},
+		3: {Rating: 1550, Deviation: 100, Volatility: 0.06},
+		4: {Rating: 1700, Deviation: 300, Volatility: 0.06},
+	}
+	games := []Game{
+		{User1: 1, User2: 2, Score: 1},
+		{User1: 3, User2: 1, Score: 1},
+		{User1: 1, User2: 4, Score: 0},
+	}
+	updated, played := RateGames(ratings, games)
+	expected := Glicko{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999}
+	if g := updated[1]; math.Abs(g.Rating-expected.Rating) > 0.01 ||
+		math.Abs(g.Deviation-expected.Deviation) > 0.01 || math.Abs(g.Volatility-expected.Volatility) > 0.00001 {
+		t.Fatalf("expected %+v, got %+v", expected, g)
+	}
+	if played[1] != 3 || played[2] != 1 {
+		t.Fatalf("unexpected number of played games %v", played)
+	}
+	if updated[2].Rating >= ratings[2].Rating || updated[3].Rating <= ratings[3].Rating {
+		t.Fatalf("unexpected ratings of opponents %+v", updated)
+	}
+}
+
+func TestRankingGames(t *testing.T) {
+	games := RankingGames([]int64{3, 1, 2})
+	if len(games) != 3 {
+		t.Fatalf("expected 3 games, got %d", len(games))
+	}
+	updated, _ := RateGames(nil, games)
+	if !(updated[3].Rating > updated[1].Rating && updated[1].Rating > updated[2].Rating) {
+		t.Fatalf("ratings don't follow ranking: %+v", updated)
+	}
+}
+
+func TestRatingRange(t *testing.T) {
+	s := TournamentSettings{MinRating: 1400, MaxRating: 1600}
+	for rating, expected := range map[float64]bool{1399.5: false, 1400: true, 1600: true, 1600.5: false} {
+		if in := s.InRatingRange(rating); in != expected {
+			t.Fatalf("expected %v for rating %v, got %v", expected, rating, in)
+		}
+	}
+	s = TournamentSettings{MinRating: 1600, MaxRating: 1400}
+	if err := s.Validate(); err != ErrInvalidRatingRange {
+		t.Fatalf("expected %v, got %v", ErrInvalidRatingRange, err)
+	}
+}
@@ -0,0 +1,44 @@
+package sts
+
+import "time"
+
+// InRegistrationWindow reports whether passed time is between registration open and close times
+// of tournament. Times that aren't set don't limit the window.
+func (s TournamentSettings) InRegistrationWindow(now time.Time) bool {
+	if s.RegistrationOpensAt != nil && now.Before(*s.RegistrationOpensAt) {
+		return false
+	}
+	if s.RegistrationClosesAt != nil && !now.Before(*s.RegistrationClosesAt) {
+		return false
+	}
+	return true
+}
+
+func validateSchedule(s TournamentSettings) error {
+	if s.RegistrationOpensAt != nil && s.RegistrationClosesAt != nil &&
+		!s.RegistrationOpensAt.Before(*s.RegistrationClosesAt) {
+		return ErrInvalidSchedule
+	}
+	if s.StartsAt == nil {
+		return nil
+	}
+	if s.RegistrationOpensAt != nil && !s.RegistrationOpensAt.Before(*s.StartsAt) {
+		return ErrInvalidSchedule
+	}
+	if s.RegistrationClosesAt != nil && s.RegistrationClosesAt.After(*s.StartsAt) {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// DueTournaments holds ids of tournaments whose status has to be changed according to their schedule.
+type DueTournaments struct {
+	// Open holds drafts whose registration open time has come.
+	Open []int64
+	// Start holds tournaments open for registration whose start time has come and which have
+	// enough players.
+	Start []int64
+	// Cancel holds tournaments open for registration whose start time has come but which don't
+	// have enough players.
+	Cancel []int64
+}
@@ -0,0 +1,64 @@
+package sts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInRegistrationWindow(t *testing.T) {
+	opens := time.Date(2019, 9, 10, 12, 0, 0, 0, time.UTC)
+	closes := opens.Add(time.Hour)
+	s := TournamentSettings{
+		RegistrationOpensAt:  &opens,
+		RegistrationClosesAt: &closes,
+	}
+	tt := []struct {
+		name string
+		now  time.Time
+		open bool
+	}{
+		{name: "before window", now: opens.Add(-time.Second)},
+		{name: "window opens", now: opens, open: true},
+		{name: "inside window", now: opens.Add(30 * time.Minute), open: true},
+		{name: "window closes", now: closes},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if open := s.InRegistrationWindow(tc.now); open != tc.open {
+				t.Fatalf("expected %v, got %v", tc.open, open)
+			}
+		})
+	}
+	if !(TournamentSettings{}).InRegistrationWindow(opens) {
+		t.Fatalf("expected unlimited window to be open")
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	opens := time.Date(2019, 9, 10, 12, 0, 0, 0, time.UTC)
+	closes := opens.Add(time.Hour)
+	starts := closes.Add(time.Hour)
+	tt := []struct {
+		name     string
+		settings TournamentSettings
+		err      error
+	}{
+		{name: "no times", settings: TournamentSettings{}},
+		{name: "full schedule", settings: TournamentSettings{RegistrationOpensAt: &opens,
+			RegistrationClosesAt: &closes, StartsAt: &starts}},
+		{name: "closes at start", settings: TournamentSettings{RegistrationClosesAt: &closes, StartsAt: &closes}},
+		{name: "closes before opens", settings: TournamentSettings{RegistrationOpensAt: &closes,
+			RegistrationClosesAt: &opens}, err: ErrInvalidSchedule},
+		{name: "opens after start", settings: TournamentSettings{RegistrationOpensAt: &starts, StartsAt: &closes},
+			err: ErrInvalidSchedule},
+		{name: "closes after start", settings: TournamentSettings{RegistrationClosesAt: &starts, StartsAt: &closes},
+			err: ErrInvalidSchedule},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.settings.Validate(); err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -3,30 +3,294 @@ package sts
 import (
 	"context"
 	"errors"
+	"time"
 )
 
+// TournamentSettings holds tournament parameters that are chosen by its organizer.
+type TournamentSettings struct {
+	Name    string `json:"name"`
+	Deposit uint64 `json:"deposit"`
+	// Currency of deposit and prize.
+	Currency Currency `json:"currency,omitempty"`
+	// PrizeShares holds percents of prize that are paid to each place, starting from the first one.
+	PrizeShares []uint32 `json:"prizeShares,omitempty"`
+	RakeType    RakeType `json:"rakeType,omitempty"`
+	// Rake is a percent or a fixed amount of each deposit that is kept by the house, depending on RakeType.
+	Rake       uint64 `json:"rake,omitempty"`
+	MinPlayers uint32 `json:"minPlayers,omitempty"`
+	// MaxPlayers of zero means that the number of players isn't limited.
+	MaxPlayers uint32 `json:"maxPlayers,omitempty"`
+	// RegistrationOpensAt and RegistrationClosesAt limit the time when users can join tournament.
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty"`
+	StartsAt             *time.Time `json:"startsAt,omitempty"`
+	Format               Format     `json:"format,omitempty"`
+	// Rounds is a number of rounds of Swiss tournament. Zero means enough rounds to find a single winner.
+	Rounds uint32 `json:"rounds,omitempty"`
+	// MinRating and MaxRating restrict entry to users with rating in this range. Zero means no limit.
+	MinRating uint32 `json:"minRating,omitempty"`
+	MaxRating uint32 `json:"maxRating,omitempty"`
+	// TeamBased tournaments are joined by teams. MinPlayers and MaxPlayers limit the number of teams.
+	TeamBased bool `json:"teamBased,omitempty"`
+	// Visibility of private tournaments requires an organizer who manages their invite codes.
+	Visibility  Visibility `json:"visibility,omitempty"`
+	OrganizerID int64      `json:"organizerId,omitempty"`
+	// GuaranteedPrize is the minimum prize that is paid out. When deposits fall short of it,
+	// the difference is paid from the house account named by Sponsor.
+	GuaranteedPrize uint64 `json:"guaranteedPrize,omitempty"`
+	Sponsor         string `json:"sponsor,omitempty"`
+}
+
+// Validate fills omitted settings with default values and checks that settings are correct.
+func (s *TournamentSettings) Validate() error {
+	if s.PrizeShares == nil {
+		s.PrizeShares = DefaultPrizeShares
+	}
+	if s.Format == "" {
+		s.Format = FormatPool
+	}
+	if s.Visibility == "" {
+		s.Visibility = VisibilityPublic
+	}
+	if s.Currency == "" {
+		s.Currency = DefaultCurrency
+	}
+	err := ValidatePrizeShares(s.PrizeShares)
+	if err != nil {
+		return err
+	}
+	err = validateRake(*s)
+	if err != nil {
+		return err
+	}
+	err = validateCapacity(*s)
+	if err != nil {
+		return err
+	}
+	err = validateSchedule(*s)
+	if err != nil {
+		return err
+	}
+	err = validateFormat(*s)
+	if err != nil {
+		return err
+	}
+	if s.TeamBased && s.Format != FormatPool {
+		return ErrInvalidFormat
+	}
+	err = validateRatingRange(*s)
+	if err != nil {
+		return err
+	}
+	err = validateGuarantee(s)
+	if err != nil {
+		return err
+	}
+	return validateVisibility(*s)
+}
+
 // Tournament represents a tournament in a social tournaments service.
 type Tournament struct {
-	ID      int64   `json:"id"`
-	Name    string  `json:"name"`
-	Deposit uint64  `json:"deposit"`
-	Prize   uint64  `json:"prize"`
-	Winner  int64   `json:"winner"`
-	Users   []int64 `json:"users"`
+	ID int64 `json:"id"`
+	TournamentSettings
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	// GrossPrize is a sum of all deposits, Prize is what is left of it after rake.
+	GrossPrize uint64 `json:"grossPrize"`
+	Prize      uint64 `json:"prize"`
+	// Overlay is set when tournament has finished and holds the part of its guaranteed prize
+	// that has been paid by the sponsor.
+	Overlay uint64 `json:"overlay,omitempty"`
+	Winner  int64  `json:"winner"`
+	// WinnerTeam is set when team-based tournament has finished.
+	WinnerTeam int64   `json:"winnerTeam,omitempty"`
+	Users      []int64 `json:"users"`
+	// Teams holds teams that have joined team-based tournament. Users holds their members.
+	Teams []int64 `json:"teams,omitempty"`
+	// Waitlist holds users that wait for a free seat, in the order they have joined.
+	Waitlist []int64 `json:"waitlist"`
+	// Underfilled is set when tournament hasn't started yet and doesn't have MinPlayers.
+	Underfilled bool     `json:"underfilled"`
+	Payouts     []Payout `json:"payouts"`
 }
 
 // User represents a single user that is registered in a social tournaments service.
 type User struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	Balance uint64 `json:"balance"`
+	ID int64 `json:"id"`
+	// Name is a unique handle of user, see ValidateHandle.
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
+	// Country is an ISO 3166-1 alpha-2 code.
+	Country string `json:"country,omitempty"`
+	Bio     string `json:"bio,omitempty"`
+	// Balances holds a wallet of every currency.
+	Balances        []Balance `json:"balances"`
+	Rating          float64   `json:"rating"`
+	RatingDeviation float64   `json:"ratingDeviation"`
+	// Games is a number of rated games that user has played.
+	Games uint32 `json:"games"`
+	// Expirations holds credited points that are going to expire, the soonest ones first.
+	Expirations []Expiration `json:"expirations"`
 }
 
-// ErrNotFound is returned when item hasn't been found in db.
-var ErrNotFound = errors.New("not found")
+var (
+	// ErrNotFound is returned when item hasn't been found in db.
+	ErrNotFound = errors.New("not found")
+
+	// ErrTournamentClosed is returned when tournament isn't open for registration
+	// or registration window is over.
+	ErrTournamentClosed = errors.New("tournament isn't open for registration")
+
+	// ErrNotParticipant is returned when user doesn't participate in tournament.
+	ErrNotParticipant = errors.New("user isn't a participant")
+
+	// ErrInvalidPrizeShares is returned when prize shares are incorrect.
+	ErrInvalidPrizeShares = errors.New("prize shares must be positive and sum up to 100")
+
+	// ErrInvalidRake is returned when rake settings are incorrect.
+	ErrInvalidRake = errors.New("rake must be a percent up to 100 or a fixed amount up to deposit")
+
+	// ErrInvalidCapacity is returned when minimum number of players exceeds maximum.
+	ErrInvalidCapacity = errors.New("min players must not exceed max players")
+
+	// ErrInvalidSchedule is returned when registration doesn't open before it closes or
+	// doesn't close before tournament starts.
+	ErrInvalidSchedule = errors.New("registration must open before it closes and close before start")
+
+	// ErrNotEnoughPlayers is returned when tournament can't start because it doesn't have minimum
+	// number of players.
+	ErrNotEnoughPlayers = errors.New("tournament doesn't have enough players")
+
+	// ErrInvalidRanking is returned when ranking doesn't contain every participant exactly once.
+	ErrInvalidRanking = errors.New("ranking must contain every participant exactly once")
+
+	// ErrTournamentNotRunning is returned when tournament games can't be played because
+	// tournament isn't running.
+	ErrTournamentNotRunning = errors.New("tournament isn't running")
+
+	// ErrInvalidSeeding is returned when seeding doesn't contain every participant exactly once.
+	ErrInvalidSeeding = errors.New("seeding must contain every participant exactly once")
+
+	// ErrBracketExists is returned when tournament bracket has already been generated.
+	ErrBracketExists = errors.New("bracket has already been generated")
+
+	// ErrMatchNotReady is returned when match result is reported before both users are known.
+	ErrMatchNotReady = errors.New("match doesn't have both users yet")
+
+	// ErrMatchReported is returned when match result has already been reported.
+	ErrMatchReported = errors.New("match has already been reported")
+
+	// ErrInvalidFormat is returned when tournament format is unknown, rounds are set for
+	// a format other than Swiss or team-based tournament isn't a pool.
+	ErrInvalidFormat = errors.New("format must be pool, single_elimination, round_robin or swiss")
+
+	// ErrUnsupportedFormat is returned when requested games don't match tournament format.
+	ErrUnsupportedFormat = errors.New("operation isn't supported by tournament format")
+
+	// ErrRoundNotFinished is returned when next round is paired before every game of
+	// the current round has been reported.
+	ErrRoundNotFinished = errors.New("current round hasn't finished yet")
+
+	// ErrInvalidResult is returned when game result isn't a win, draw or loss.
+	ErrInvalidResult = errors.New("result must be win, draw or loss")
+
+	// ErrInvalidRatingRange is returned when minimum rating exceeds maximum.
+	ErrInvalidRatingRange = errors.New("min rating must not exceed max rating")
+
+	// ErrRatingOutOfRange is returned when user rating doesn't allow to join tournament.
+	ErrRatingOutOfRange = errors.New("user rating is out of tournament range")
+
+	// ErrInvalidLeaderboard is returned when leaderboard period, order or page is incorrect.
+	ErrInvalidLeaderboard = errors.New("period must be all, month or week, order must be prize, wins or profit")
+
+	// ErrNotInvited is returned when user joins a team without an invitation.
+	ErrNotInvited = errors.New("user hasn't been invited to the team")
+
+	// ErrInvalidTeamShares is returned when team shares aren't set for every member exactly once,
+	// aren't positive or don't sum up to 100.
+	ErrInvalidTeamShares = errors.New("team shares must be positive, cover every member and sum up to 100")
+
+	// ErrTeamTournament is returned when user joins or leaves team-based tournament alone.
+	ErrTeamTournament = errors.New("tournament is played in teams")
+
+	// ErrSoloTournament is returned when team joins tournament that isn't team-based.
+	ErrSoloTournament = errors.New("tournament isn't played in teams")
+
+	// ErrTournamentFull is returned when team joins tournament without free seats.
+	ErrTournamentFull = errors.New("tournament is full")
+
+	// ErrTeamFull is returned when user joins a team that already has MaxTeamMembers members.
+	ErrTeamFull = errors.New("team is full")
+
+	// ErrAlreadyJoined is returned when user or a member of team joins tournament that they have already joined.
+	ErrAlreadyJoined = errors.New("user has already joined tournament")
+
+	// ErrInvalidVisibility is returned when tournament visibility is unknown or private tournament
+	// doesn't have an organizer.
+	ErrInvalidVisibility = errors.New("visibility must be public or private, private tournament needs an organizer")
+
+	// ErrInvalidInviteCode is returned when private tournament is joined without a valid, unexpired
+	// and unexhausted invite code.
+	ErrInvalidInviteCode = errors.New("invite code is invalid, expired or exhausted")
+
+	// ErrNotOrganizer is returned when invite codes are managed by user other than tournament organizer.
+	ErrNotOrganizer = errors.New("user isn't the tournament organizer")
+
+	// ErrPublicTournament is returned when invite codes are managed for a public tournament.
+	ErrPublicTournament = errors.New("tournament is public")
+
+	// ErrInvalidGuarantee is returned when sponsor is set without a guaranteed prize or
+	// sponsor account doesn't exist.
+	ErrInvalidGuarantee = errors.New("sponsor needs a guaranteed prize and an existing house account")
+
+	// ErrInsufficientBudget is returned when sponsor account can't reserve guaranteed prize of tournament.
+	ErrInsufficientBudget = errors.New("sponsor doesn't have enough points to guarantee the prize")
+
+	// ErrInvalidCurrency is returned when currency doesn't exist.
+	ErrInvalidCurrency = errors.New("currency doesn't exist")
+
+	// ErrInvalidExpiry is returned when points are credited with a negative expiry period.
+	ErrInvalidExpiry = errors.New("expiry period must not be negative")
+
+	// ErrInsufficientFunds is returned when user doesn't have enough points to pay.
+	ErrInsufficientFunds = errors.New("user doesn't have enough points")
+
+	// ErrInvalidTransfer is returned when transfer doesn't move any points or moves them to the same user.
+	ErrInvalidTransfer = errors.New("transfer needs a positive amount and two different users")
+
+	// ErrTransferCapExceeded is returned when transfer would exceed the daily transfer cap of its currency.
+	ErrTransferCapExceeded = errors.New("daily transfer cap is exceeded")
+
+	// ErrIdempotencyKeyReused is returned when idempotency key has already been used for another request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key has been used for another request")
+
+	// ErrValidation is returned when db rejects data that breaks one of its rules.
+	ErrValidation = errors.New("data breaks validation rules")
+
+	// ErrInvalidIdempotencyKey is returned when idempotency key is empty or too long.
+	ErrInvalidIdempotencyKey = errors.New("idempotency key must have 1 to 255 characters")
+
+	// ErrInvalidListing is returned when listing filter, sort, cursor or page size is incorrect.
+	ErrInvalidListing = errors.New("listing filter, sort, cursor or limit is incorrect")
+
+	// ErrInvalidHandle is returned when user name isn't a valid handle.
+	ErrInvalidHandle = errors.New("handle must have 3 to 20 letters, digits or underscores")
+
+	// ErrHandleTaken is returned when another user has the same handle regardless of case.
+	ErrHandleTaken = errors.New("handle is already taken")
+
+	// ErrInvalidProfile is returned when display name, avatar URL, country or bio is incorrect.
+	ErrInvalidProfile = errors.New("display name, avatar url, country or bio is incorrect")
+
+	// ErrTournamentLocked is returned when settings other than name are changed after players
+	// have joined tournament.
+	ErrTournamentLocked = errors.New("only name of tournament can be changed after players have joined")
+)
 
 type Service interface {
-	// AddUser adds user with passed name to db. It returns id of this user.
+	// AddUser adds user with passed name to db. It returns id of this user. If name isn't a valid
+	// handle, function returns ErrInvalidHandle. If handle is taken, function returns ErrHandleTaken.
 	AddUser(ctx context.Context, name string) (int64, error)
 
 	// GetUser returns user with passed id. If user isn't found, function returns ErrNotFound.
@@ -35,17 +299,228 @@ type Service interface {
 	// DeleteUser deletes user with passed id. If user isn't found, function returns ErrNotFound.
 	DeleteUser(ctx context.Context, id int64) error
 
-	// AddPoints adds points to user with passed id. If user isn't found, function returns ErrNotFound.
-	AddPoints(ctx context.Context, id, points int64) error
+	// UpdateUser changes profile of user with passed id. If user isn't found, function returns
+	// ErrNotFound. If name changes to an invalid handle, function returns ErrInvalidHandle. If handle
+	// is taken, function returns ErrHandleTaken. Other incorrect fields are ErrInvalidProfile.
+	UpdateUser(ctx context.Context, id int64, update UserUpdate) error
+
+	// ListUsers returns a page of users ordered by id, starting after the cursor of the query.
+	// If query is incorrect, function returns ErrInvalidListing.
+	ListUsers(ctx context.Context, q UserQuery) (*UserPage, error)
+
+	// Credit adds amount of passed currency to balance of user with passed id. Credited points expire
+	// after expiresIn unless it's zero. If user isn't found, function returns ErrNotFound.
+	// If currency doesn't exist, function returns ErrInvalidCurrency. Retries with the same
+	// idempotency key in ctx succeed without crediting again, see WithIdempotencyKey.
+	Credit(ctx context.Context, id int64, currency Currency, amount uint64, expiresIn time.Duration) error
 
-	// AddTournament adds tournament with passed name and deposit. Return id of this tournament.
-	AddTournament(ctx context.Context, name string, deposit uint64) (int64, error)
+	// Debit takes amount of passed currency from balance of user with passed id. Points that expire
+	// the soonest are taken first. If user doesn't have enough points, function returns
+	// ErrInsufficientFunds. See Credit for other errors.
+	Debit(ctx context.Context, id int64, currency Currency, amount uint64) error
+
+	// TransferPoints moves amount of passed currency from user with fromID to user with toID at once.
+	// Expiring points keep their expiry and ledger entries of both users hold the other one as
+	// their peer. If either user isn't found, function returns ErrNotFound.
+	// If sender doesn't have enough points, function returns ErrInsufficientFunds. If transfer would
+	// exceed what sender may transfer a day, function returns ErrTransferCapExceeded.
+	TransferPoints(ctx context.Context, fromID, toID int64, currency Currency, amount uint64) error
+
+	// GetUserTransactions returns up to limit latest balance changes of user with passed userID,
+	// starting from the newest one. If beforeID isn't zero, only transactions older than the
+	// transaction with beforeID are returned. If user isn't found, function returns ErrNotFound.
+	GetUserTransactions(ctx context.Context, userID, beforeID int64, limit uint64) ([]Transaction, error)
+
+	// GetRatingHistory returns rating changes of user with passed userID, starting from the oldest
+	// one. If user isn't found, function returns ErrNotFound.
+	GetRatingHistory(ctx context.Context, userID int64) ([]RatingChange, error)
+
+	// AddTournament adds tournament with passed settings in draft status. Return id of this tournament.
+	// If settings are incorrect, function returns ErrInvalidPrizeShares, ErrInvalidRake,
+	// ErrInvalidCapacity, ErrInvalidSchedule, ErrInvalidFormat, ErrInvalidRatingRange,
+	// ErrInvalidVisibility, ErrInvalidGuarantee or ErrInvalidCurrency.
+	AddTournament(ctx context.Context, settings TournamentSettings) (int64, error)
 
 	// GetTournament returns tournament with passed id. If tournament isn't found,
 	// function returns ErrNotFound.
 	GetTournament(ctx context.Context, id int64) (*Tournament, error)
 
+	// UpdateTournament applies passed changes to settings of tournament with passed id. Every setting
+	// can be changed while nobody has joined tournament or its waitlist, afterwards only name can be
+	// changed, otherwise function returns ErrTournamentLocked. If tournament isn't found, function
+	// returns ErrNotFound. If tournament has started, function returns ErrTournamentClosed. Budget
+	// of open tournament is reserved for the changed guaranteed prize, if sponsor doesn't have enough
+	// points, function returns ErrInsufficientBudget. See AddTournament for errors of incorrect settings.
+	UpdateTournament(ctx context.Context, id int64, update TournamentUpdate) error
+
+	// CloneTournament adds a draft with settings of tournament with passed id and returns id of
+	// the draft. Schedule times that have passed aren't copied. If tournament isn't found,
+	// function returns ErrNotFound. See AddTournament for other errors.
+	CloneTournament(ctx context.Context, id int64) (int64, error)
+
+	// ListTournaments returns a page of tournaments that are visible to the viewer of the query and
+	// match its filters, in its sort order and starting after its cursor. Private tournaments are
+	// visible to their organizer, participants and waitlisted users only. If query is incorrect,
+	// function returns ErrInvalidListing.
+	ListTournaments(ctx context.Context, q TournamentQuery) (*TournamentPage, error)
+
 	// JoinTournament adds user with passed userID to tournament with passed tournamentID.
-	// If tournament or user isn't found, function returns ErrNotFound.
-	JoinTournament(ctx context.Context, tournamentID, userID int64) error
+	// If tournament is full, user is put on the waitlist without charging the deposit and
+	// function returns true. If tournament or user isn't found, function returns ErrNotFound.
+	// If tournament isn't open for registration or current time is out of its registration
+	// window, function returns ErrTournamentClosed. If user rating is out of tournament range,
+	// function returns ErrRatingOutOfRange. If tournament is team-based, function returns ErrTeamTournament.
+	// Users other than the organizer must pass a valid invite code to join private tournament,
+	// otherwise function returns ErrInvalidInviteCode. The code is ignored for public tournaments.
+	// If user has already joined tournament or its waitlist, function returns ErrAlreadyJoined.
+	// Retries with the same idempotency key in ctx return the first result without joining again.
+	JoinTournament(ctx context.Context, tournamentID, userID int64, inviteCode string) (waitlisted bool, err error)
+
+	// LeaveTournament removes user with passed userID from tournament with passed tournamentID
+	// and refunds the deposit. The freed seat is taken by the first waitlisted user that can
+	// pay the deposit. Waitlisted user is just removed from the waitlist. If tournament isn't
+	// found, function returns ErrNotFound. If user doesn't participate in tournament, function
+	// returns ErrNotParticipant. If tournament has already started, function returns
+	// ErrTournamentClosed. If tournament is team-based, function returns ErrTeamTournament.
+	LeaveTournament(ctx context.Context, tournamentID, userID int64) error
+
+	// JoinTournamentAsTeam registers team with passed teamID in team-based tournament with passed
+	// tournamentID. Deposit is charged to team members according to their shares. If tournament,
+	// team or one of its members isn't found, function returns ErrNotFound. If tournament isn't
+	// team-based, function returns ErrSoloTournament. If tournament isn't open for registration or
+	// current time is out of its registration window, function returns ErrTournamentClosed. If rating
+	// of a member is out of tournament range, function returns ErrRatingOutOfRange. If tournament
+	// is full, function returns ErrTournamentFull. If a member has already joined tournament,
+	// function returns ErrAlreadyJoined. Private tournament requires a valid invite code,
+	// otherwise function returns ErrInvalidInviteCode. Retries with the same idempotency key in ctx
+	// succeed without joining again.
+	JoinTournamentAsTeam(ctx context.Context, tournamentID, teamID int64, inviteCode string) error
+
+	// CreateInviteCode generates a new invite code of private tournament with passed tournamentID.
+	// If tournament isn't found, function returns ErrNotFound. If tournament is public, function
+	// returns ErrPublicTournament. If user with passed organizerID doesn't organize tournament,
+	// function returns ErrNotOrganizer.
+	CreateInviteCode(ctx context.Context, tournamentID, organizerID int64, settings InviteSettings) (*InviteCode, error)
+
+	// GetInviteCodes returns every invite code of private tournament with passed tournamentID,
+	// starting from the oldest one. See CreateInviteCode for errors.
+	GetInviteCodes(ctx context.Context, tournamentID, organizerID int64) ([]InviteCode, error)
+
+	// RevokeInviteCode makes passed code of private tournament with passed tournamentID unusable.
+	// If code isn't found, function returns ErrNotFound. See CreateInviteCode for other errors.
+	RevokeInviteCode(ctx context.Context, tournamentID, organizerID int64, code string) error
+
+	// RegenerateInviteCode revokes passed code of private tournament with passed tournamentID and
+	// returns a new code with the same limits. Uses of the new code start from zero. If code isn't
+	// found, function returns ErrNotFound. See CreateInviteCode for other errors.
+	RegenerateInviteCode(ctx context.Context, tournamentID, organizerID int64, code string) (*InviteCode, error)
+
+	// OpenRegistration moves tournament from draft to registration status and reserves its guaranteed
+	// prize from the budget of its sponsor. If tournament isn't found, function returns ErrNotFound.
+	// If tournament isn't a draft, function returns TransitionError. If sponsor doesn't have enough
+	// points, function returns ErrInsufficientBudget.
+	OpenRegistration(ctx context.Context, tournamentID int64) error
+
+	// StartTournament closes registration, clears the waitlist and moves tournament to running
+	// status. If tournament isn't found, function returns ErrNotFound. If tournament isn't
+	// open for registration, function returns TransitionError. If tournament doesn't have
+	// minimum number of players, function returns ErrNotEnoughPlayers.
+	StartTournament(ctx context.Context, tournamentID int64) error
+
+	// FinishTournament splits tournament prize between users according to passed ranking
+	// and marks tournament as finished. Ranking must contain every participant exactly once,
+	// starting from the winner. If tournament isn't found, function returns ErrNotFound.
+	// If ranked user doesn't participate in tournament, function returns ErrNotParticipant.
+	// If tournament isn't running, function returns TransitionError. Ratings of participants of
+	// a pool tournament are updated as if every user has won against every user ranked below.
+	// Ranking of team-based tournament contains teams instead of users, prizes of teams are split
+	// between their members by their shares and ratings aren't updated. When prize falls short of
+	// the guaranteed one, the overlay is paid out of the budget that has been reserved from the
+	// sponsor, the rest of the budget is returned.
+	FinishTournament(ctx context.Context, tournamentID int64, ranking []int64) error
+
+	// CancelTournament refunds deposits to every participant, returns the reserved guaranteed prize
+	// to the sponsor, resets tournament prize, clears the waitlist and moves tournament to cancelled
+	// status. If tournament isn't found, function returns ErrNotFound. If tournament has already
+	// finished or been cancelled, function returns TransitionError.
+	CancelTournament(ctx context.Context, tournamentID int64) error
+
+	// GenerateBracket generates single-elimination bracket from participants of running tournament
+	// with passed tournamentID. Seeding lists every participant from the strongest one, empty
+	// seeding means random order. If tournament isn't found, function returns ErrNotFound.
+	// If tournament isn't running, function returns ErrTournamentNotRunning. If tournament
+	// isn't a single-elimination one, function returns ErrUnsupportedFormat. If bracket has
+	// already been generated, function returns ErrBracketExists. If seeding is incorrect,
+	// function returns ErrInvalidSeeding.
+	GenerateBracket(ctx context.Context, tournamentID int64, seeding []int64) error
+
+	// GetBracket returns matches of tournament with passed tournamentID ordered by round and
+	// position. If tournament isn't found, function returns ErrNotFound.
+	GetBracket(ctx context.Context, tournamentID int64) (Bracket, error)
+
+	// ReportMatch records the winner of match with passed round and position, updates ratings of
+	// both users and advances the winner to the next round. When the final is reported, tournament
+	// is finished with the ranking of the bracket. If tournament or match isn't found, function
+	// returns ErrNotFound. If tournament isn't running, function returns ErrTournamentNotRunning.
+	// If tournament isn't a single-elimination one, function returns ErrUnsupportedFormat.
+	// See Bracket.Report for other errors.
+	ReportMatch(ctx context.Context, tournamentID int64, round, position uint32, winnerID int64) error
+
+	// PairRound pairs participants of running round-robin or Swiss tournament with passed
+	// tournamentID for the next round and returns games of this round. If tournament isn't
+	// found, function returns ErrNotFound. If tournament isn't running, function returns
+	// ErrTournamentNotRunning. If tournament format doesn't have pairings, function returns
+	// ErrUnsupportedFormat. If current round has unreported games, function returns
+	// ErrRoundNotFinished. If tournament has less than two participants, function returns
+	// ErrNotEnoughPlayers.
+	PairRound(ctx context.Context, tournamentID int64) (Pairings, error)
+
+	// GetPairings returns games of tournament with passed tournamentID ordered by round and
+	// board. If tournament isn't found, function returns ErrNotFound.
+	GetPairings(ctx context.Context, tournamentID int64) (Pairings, error)
+
+	// ReportResult records result of game with passed round and board and updates ratings of
+	// both users. When the last game of the last round is reported, tournament is finished with
+	// the ranking of its standings. If tournament or game isn't found, function returns ErrNotFound.
+	// If tournament isn't running, function returns ErrTournamentNotRunning. See Pairings.Report
+	// for other errors.
+	ReportResult(ctx context.Context, tournamentID int64, round, board uint32, result Result) error
+
+	// GetStandings returns standings of tournament with passed tournamentID from the leader.
+	// If tournament isn't found, function returns ErrNotFound.
+	GetStandings(ctx context.Context, tournamentID int64) ([]Standing, error)
+
+	// GetLeaderboard returns a page of leaderboard of users that have finished tournaments in
+	// requested period. If query is incorrect, function returns ErrInvalidLeaderboard.
+	GetLeaderboard(ctx context.Context, q LeaderboardQuery) (*Leaderboard, error)
+
+	// CreateTeam adds team with passed name and makes user with passed captainID its captain and
+	// the only member. It returns id of this team. If user isn't found, function returns ErrNotFound.
+	CreateTeam(ctx context.Context, name string, captainID int64) (int64, error)
+
+	// GetTeam returns team with passed id. If team isn't found, function returns ErrNotFound.
+	GetTeam(ctx context.Context, id int64) (*Team, error)
+
+	// InviteToTeam allows user with passed userID to join team with passed teamID. Inviting a member
+	// of the team does nothing. If team or user isn't found, function returns ErrNotFound.
+	InviteToTeam(ctx context.Context, teamID, userID int64) error
+
+	// JoinTeam adds invited user with passed userID to team with passed teamID and splits shares
+	// of the team equally between its members. If team isn't found, function returns ErrNotFound.
+	// If user hasn't been invited, function returns ErrNotInvited. If team already has
+	// MaxTeamMembers members, function returns ErrTeamFull.
+	JoinTeam(ctx context.Context, teamID, userID int64) error
+
+	// SetTeamShares sets percents of deposits and prizes that fall on members of team with passed
+	// teamID. If team isn't found, function returns ErrNotFound. If shares aren't set for every
+	// member, aren't positive or don't sum up to 100, function returns ErrInvalidTeamShares.
+	SetTeamShares(ctx context.Context, teamID int64, shares []TeamMember) error
+
+	// GetHouseAccount returns house account with passed name and currency. If account isn't found,
+	// function returns ErrNotFound.
+	GetHouseAccount(ctx context.Context, name string, currency Currency) (*HouseAccount, error)
+
+	// FundHouseAccount adds points to house account with passed name and currency, e.g. to the budget
+	// of a sponsor. If account isn't found, function returns ErrNotFound.
+	FundHouseAccount(ctx context.Context, name string, currency Currency, points uint64) error
 }
@@ -0,0 +1,48 @@
+package sts
+
+import "fmt"
+
+// Status represents a stage of tournament lifecycle.
+type Status string
+
+const (
+	// StatusDraft is a status of a newly created tournament that isn't open for players yet.
+	StatusDraft Status = "draft"
+	// StatusRegistration is a status of a tournament that players can join.
+	StatusRegistration Status = "registration"
+	// StatusRunning is a status of a tournament that is being played.
+	StatusRunning Status = "running"
+	// StatusFinished is a status of a tournament whose prize has been paid out.
+	StatusFinished Status = "finished"
+	// StatusCancelled is a status of a tournament that has been called off.
+	StatusCancelled Status = "cancelled"
+)
+
+var transitions = map[Status][]Status{
+	StatusDraft:        {StatusRegistration, StatusCancelled},
+	StatusRegistration: {StatusRunning, StatusCancelled},
+	StatusRunning:      {StatusFinished, StatusCancelled},
+}
+
+// TransitionError is returned when tournament can't be moved from its current status to the requested one.
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("tournament can't be moved from %s to %s", e.From, e.To)
+}
+
+// CheckTransition returns TransitionError if tournament can't be moved from status from to status to.
+func CheckTransition(from, to Status) error {
+	for _, s := range transitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return &TransitionError{
+		From: from,
+		To:   to,
+	}
+}
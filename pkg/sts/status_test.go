@@ -0,0 +1,34 @@
+package sts
+
+import "testing"
+
+func TestCheckTransition(t *testing.T) {
+	tt := []struct {
+		from  Status
+		to    Status
+		legal bool
+	}{
+		{from: StatusDraft, to: StatusRegistration, legal: true},
+		{from: StatusDraft, to: StatusRunning},
+		{from: StatusDraft, to: StatusCancelled, legal: true},
+		{from: StatusRegistration, to: StatusRunning, legal: true},
+		{from: StatusRegistration, to: StatusFinished},
+		{from: StatusRunning, to: StatusFinished, legal: true},
+		{from: StatusRunning, to: StatusCancelled, legal: true},
+		{from: StatusFinished, to: StatusCancelled},
+		{from: StatusCancelled, to: StatusRegistration},
+	}
+	for _, tc := range tt {
+		t.Run(string(tc.from)+"->"+string(tc.to), func(t *testing.T) {
+			err := CheckTransition(tc.from, tc.to)
+			if tc.legal && err != nil {
+				t.Fatalf("expected legal transition, got %v", err)
+			}
+			if !tc.legal {
+				if _, ok := err.(*TransitionError); !ok {
+					t.Fatalf("expected TransitionError, got %v", err)
+				}
+			}
+		})
+	}
+}
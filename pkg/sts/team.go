@@ -0,0 +1,112 @@
+package sts
+
+// TeamMember is a member of a team with the percent of deposits and prizes that falls on this member.
+type TeamMember struct {
+	UserID int64  `json:"userId"`
+	Share  uint32 `json:"share"`
+}
+
+// Team represents a group of users that joins team tournaments together.
+type Team struct {
+	ID        int64        `json:"id"`
+	Name      string       `json:"name"`
+	CaptainID int64        `json:"captainId"`
+	Members   []TeamMember `json:"members"`
+	// Invited holds users that have been invited to the team but haven't joined it yet.
+	Invited []int64 `json:"invited"`
+}
+
+// TeamEntry is a team registered in a tournament with shares its members had at registration.
+type TeamEntry struct {
+	TeamID  int64
+	Members []TeamMember
+}
+
+// MaxTeamMembers is the largest team whose members can all have a positive share.
+const MaxTeamMembers = 100
+
+// EqualShares returns shares for passed number of team members that are as equal as possible.
+// The remaining percents go to the first members.
+func EqualShares(members int) []uint32 {
+	if members == 0 || members > MaxTeamMembers {
+		return nil
+	}
+	shares := make([]uint32, members)
+	for i := range shares {
+		shares[i] = uint32(100 / members)
+		if i < 100%members {
+			shares[i]++
+		}
+	}
+	return shares
+}
+
+// ValidateTeamShares checks that shares are set for every team member exactly once, every share
+// is positive and all of them sum up to 100.
+func ValidateTeamShares(shares, members []TeamMember) error {
+	if len(shares) != len(members) {
+		return ErrInvalidTeamShares
+	}
+	joined := make(map[int64]bool, len(members))
+	for _, m := range members {
+		joined[m.UserID] = true
+	}
+	var sum uint32
+	for _, s := range shares {
+		if !joined[s.UserID] || s.Share == 0 || s.Share > 100 {
+			return ErrInvalidTeamShares
+		}
+		delete(joined, s.UserID)
+		sum += s.Share
+	}
+	if sum != 100 {
+		return ErrInvalidTeamShares
+	}
+	return nil
+}
+
+// Split splits amount between team members proportionally to their shares.
+func (e TeamEntry) Split(amount uint64) []uint64 {
+	shares := make([]uint32, 0, len(e.Members))
+	for _, m := range e.Members {
+		shares = append(shares, m.Share)
+	}
+	return SplitPrize(amount, shares)
+}
+
+// TeamPayouts returns payouts of ranked teams. See RankingPayouts for details.
+func TeamPayouts(prize uint64, shares []uint32, ranking []int64) []Payout {
+	payouts := RankingPayouts(prize, shares, ranking)
+	for i := range payouts {
+		payouts[i].TeamID, payouts[i].UserID = payouts[i].UserID, 0
+	}
+	return payouts
+}
+
+// TeamResults returns results of members of ranked teams. Prizes and deposits of every team
+// are split between its members by their shares.
+func TeamResults(ranking []int64, payouts []Payout, deposit uint64, teams map[int64]TeamEntry) []LeaderboardEntry {
+	prizes := make(map[int64]uint64, len(payouts))
+	for _, p := range payouts {
+		prizes[p.TeamID] += p.Amount
+	}
+	var results []LeaderboardEntry
+	for i, teamID := range ranking {
+		team := teams[teamID]
+		amounts := team.Split(prizes[teamID])
+		deposits := team.Split(deposit)
+		for j, m := range team.Members {
+			r := LeaderboardEntry{
+				UserID:      m.UserID,
+				Prize:       amounts[j],
+				Tournaments: 1,
+				Profit:      int64(amounts[j]) - int64(deposits[j]),
+			}
+			if i == 0 {
+				r.Wins = 1
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
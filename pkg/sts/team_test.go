@@ -0,0 +1,68 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEqualShares(t *testing.T) {
+	if shares := EqualShares(3); !reflect.DeepEqual(shares, []uint32{34, 33, 33}) {
+		t.Fatalf("unexpected shares %v", shares)
+	}
+	if shares := EqualShares(101); shares != nil {
+		t.Fatalf("expected no shares, got %v", shares)
+	}
+}
+
+func TestValidateTeamShares(t *testing.T) {
+	members := []TeamMember{{UserID: 1, Share: 50}, {UserID: 2, Share: 50}}
+	tt := []struct {
+		name   string
+		shares []TeamMember
+		err    error
+	}{
+		{name: "correct shares", shares: []TeamMember{{UserID: 2, Share: 70}, {UserID: 1, Share: 30}}},
+		{name: "missing member", shares: []TeamMember{{UserID: 1, Share: 100}}, err: ErrInvalidTeamShares},
+		{name: "unknown member", shares: []TeamMember{{UserID: 1, Share: 70}, {UserID: 3, Share: 30}},
+			err: ErrInvalidTeamShares},
+		{name: "repeated member", shares: []TeamMember{{UserID: 1, Share: 70}, {UserID: 1, Share: 30}},
+			err: ErrInvalidTeamShares},
+		{name: "zero share", shares: []TeamMember{{UserID: 1, Share: 100}, {UserID: 2}}, err: ErrInvalidTeamShares},
+		{name: "wrong sum", shares: []TeamMember{{UserID: 1, Share: 60}, {UserID: 2, Share: 30}},
+			err: ErrInvalidTeamShares},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateTeamShares(tc.shares, members); err != tc.err {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestTeamEntrySplit(t *testing.T) {
+	e := TeamEntry{TeamID: 1, Members: []TeamMember{{UserID: 1, Share: 50}, {UserID: 2, Share: 25},
+		{UserID: 3, Share: 25}}}
+	if amounts := e.Split(101); !reflect.DeepEqual(amounts, []uint64{51, 25, 25}) {
+		t.Fatalf("unexpected amounts %v", amounts)
+	}
+}
+
+func TestTeamResults(t *testing.T) {
+	teams := map[int64]TeamEntry{
+		1: {TeamID: 1, Members: []TeamMember{{UserID: 10, Share: 50}, {UserID: 11, Share: 50}}},
+		2: {TeamID: 2, Members: []TeamMember{{UserID: 20, Share: 100}}},
+	}
+	payouts := TeamPayouts(300, []uint32{100}, []int64{1, 2})
+	if !reflect.DeepEqual(payouts, []Payout{{Place: 1, Share: 100, Amount: 300, TeamID: 1}}) {
+		t.Fatalf("unexpected payouts %v", payouts)
+	}
+	expected := []LeaderboardEntry{
+		{UserID: 10, Prize: 150, Wins: 1, Tournaments: 1, Profit: 100},
+		{UserID: 11, Prize: 150, Wins: 1, Tournaments: 1, Profit: 100},
+		{UserID: 20, Tournaments: 1, Profit: -100},
+	}
+	if results := TeamResults([]int64{1, 2}, payouts, 100, teams); !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %v, got %v", expected, results)
+	}
+}
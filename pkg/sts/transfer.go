@@ -0,0 +1,17 @@
+package sts
+
+import "time"
+
+// ValidateTransfer returns ErrInvalidTransfer if transfer of amount from user with fromID
+// to user with toID doesn't move any points.
+func ValidateTransfer(fromID, toID int64, amount uint64) error {
+	if amount == 0 || fromID == toID {
+		return ErrInvalidTransfer
+	}
+	return nil
+}
+
+// TransferDay returns the start of UTC day that daily transfer caps are counted from at now.
+func TransferDay(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,36 @@
+package sts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTransfer(t *testing.T) {
+	tt := []struct {
+		name   string
+		fromID int64
+		toID   int64
+		amount uint64
+		err    error
+	}{
+		{name: "correct transfer", fromID: 1, toID: 2, amount: 10},
+		{name: "zero amount", fromID: 1, toID: 2, err: ErrInvalidTransfer},
+		{name: "same user", fromID: 1, toID: 1, amount: 10, err: ErrInvalidTransfer},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTransfer(tc.fromID, tc.toID, tc.amount)
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestTransferDay(t *testing.T) {
+	now := time.Date(2019, 11, 25, 23, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	expected := time.Date(2019, 11, 25, 0, 0, 0, 0, time.UTC)
+	if day := TransferDay(now); !day.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, day)
+	}
+}
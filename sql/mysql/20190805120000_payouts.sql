@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE payouts (
+    tournament_id INT NOT NULL,
+    place INT UNSIGNED NOT NULL,
+    share INT UNSIGNED NOT NULL,
+    amount INT(10) UNSIGNED,
+    user_id INT,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tournament_id, place)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO payouts (tournament_id, place, share, amount, user_id)
+SELECT id, 1, 100, IF(finished, prize, NULL), winner
+  FROM tournaments;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE payouts;
+-- +goose StatementEnd
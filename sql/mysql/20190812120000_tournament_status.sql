@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN status ENUM('draft', 'registration', 'running', 'finished', 'cancelled') NOT NULL DEFAULT 'draft';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE tournaments
+   SET status = IF(finished, 'finished', 'registration');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN finished;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN finished BOOL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE tournaments
+   SET finished = status = 'finished';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE transactions (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    amount BIGINT NOT NULL,
+    reason ENUM('fund', 'take', 'entry_fee', 'prize', 'refund') NOT NULL,
+    tournament_id INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE SET NULL,
+    INDEX (user_id, id),
+    PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE transactions;
+-- +goose StatementEnd
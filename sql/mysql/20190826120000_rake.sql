@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN rake_type ENUM('', 'percent', 'fixed') NOT NULL DEFAULT '',
+    ADD COLUMN rake INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN gross_prize INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE tournaments
+   SET gross_prize = prize;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE house_accounts (
+    name VARCHAR(20) NOT NULL,
+    balance INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (name)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO house_accounts (name)
+VALUES ('rake');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE house_accounts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN rake_type,
+    DROP COLUMN rake,
+    DROP COLUMN gross_prize;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN min_players INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN max_players INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE waitlist (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    tournament_id INT NOT NULL,
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    UNIQUE (user_id, tournament_id),
+    INDEX (tournament_id, joined_at, id),
+    PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE waitlist;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN min_players,
+    DROP COLUMN max_players;
+-- +goose StatementEnd
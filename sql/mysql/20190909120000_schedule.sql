@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN registration_opens_at DATETIME NULL,
+    ADD COLUMN registration_closes_at DATETIME NULL,
+    ADD COLUMN starts_at DATETIME NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN registration_opens_at,
+    DROP COLUMN registration_closes_at,
+    DROP COLUMN starts_at;
+-- +goose StatementEnd
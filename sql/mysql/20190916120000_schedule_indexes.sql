@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX tournaments_status_registration_opens_at_idx ON tournaments (status, registration_opens_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX tournaments_status_starts_at_idx ON tournaments (status, starts_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tournaments_status_registration_opens_at_idx ON tournaments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX tournaments_status_starts_at_idx ON tournaments;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE matches (
+    tournament_id INT NOT NULL,
+    round INT(10) UNSIGNED NOT NULL,
+    position INT(10) UNSIGNED NOT NULL,
+    user1_id INT,
+    user2_id INT,
+    winner_id INT,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user1_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (user2_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (winner_id) REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (tournament_id, round, position)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE matches;
+-- +goose StatementEnd
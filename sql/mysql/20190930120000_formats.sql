@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN format ENUM('pool', 'single_elimination', 'round_robin', 'swiss') NOT NULL DEFAULT 'pool',
+    ADD COLUMN rounds INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE pairings (
+    tournament_id INT NOT NULL,
+    round INT(10) UNSIGNED NOT NULL,
+    board INT(10) UNSIGNED NOT NULL,
+    user1_id INT,
+    user2_id INT,
+    result ENUM('', 'win', 'draw', 'loss') NOT NULL DEFAULT '',
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user1_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (user2_id) REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (tournament_id, round, board)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE pairings;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN format,
+    DROP COLUMN rounds;
+-- +goose StatementEnd
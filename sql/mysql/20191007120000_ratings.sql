@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN rating DOUBLE NOT NULL DEFAULT 1500,
+    ADD COLUMN rating_deviation DOUBLE NOT NULL DEFAULT 350,
+    ADD COLUMN rating_volatility DOUBLE NOT NULL DEFAULT 0.06,
+    ADD COLUMN games INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN min_rating INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN max_rating INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE rating_history (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    tournament_id INT,
+    rating DOUBLE NOT NULL,
+    deviation DOUBLE NOT NULL,
+    volatility DOUBLE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE SET NULL,
+    INDEX (user_id, id),
+    PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rating_history;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN min_rating,
+    DROP COLUMN max_rating;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN rating,
+    DROP COLUMN rating_deviation,
+    DROP COLUMN rating_volatility,
+    DROP COLUMN games;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE leaderboard_stats (
+    period ENUM('all', 'month', 'week') NOT NULL,
+    period_start DATE NOT NULL,
+    user_id INT NOT NULL,
+    prize BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    wins INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    tournaments INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    profit BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX (period, period_start, prize, user_id),
+    INDEX (period, period_start, wins, user_id),
+    INDEX (period, period_start, profit, user_id),
+    PRIMARY KEY (period, period_start, user_id)
+);
+-- +goose StatementEnd
+
+-- Finish time of earlier tournaments isn't known, so they are counted in all-time leaderboard only.
+-- +goose StatementBegin
+INSERT INTO leaderboard_stats (period, period_start, user_id, prize, wins, tournaments, profit)
+     SELECT 'all', '1970-01-01', p.user_id, COALESCE(SUM(pay.amount), 0),
+            SUM(t.winner = p.user_id), COUNT(*),
+            CAST(COALESCE(SUM(pay.amount), 0) AS SIGNED) - CAST(SUM(t.deposit) AS SIGNED)
+       FROM participants AS p
+       JOIN tournaments AS t ON t.id = p.tournament_id AND t.status = 'finished'
+  LEFT JOIN payouts AS pay ON pay.tournament_id = p.tournament_id AND pay.user_id = p.user_id
+   GROUP BY p.user_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE leaderboard_stats;
+-- +goose StatementEnd
@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE teams (
+    id INT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    captain_id INT NOT NULL,
+    FOREIGN KEY (captain_id) REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE team_members (
+    team_id INT NOT NULL,
+    user_id INT NOT NULL,
+    share INT(10) UNSIGNED NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE team_invites (
+    team_id INT NOT NULL,
+    user_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN team_based BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN winner_team INT,
+    ADD CONSTRAINT tournaments_winner_team_fk FOREIGN KEY (winner_team) REFERENCES teams(id);
+-- +goose StatementEnd
+
+-- Members of a team that has joined a tournament keep the shares they had when the team joined.
+-- +goose StatementBegin
+ALTER TABLE participants
+    ADD COLUMN team_id INT,
+    ADD COLUMN share INT(10) UNSIGNED,
+    ADD CONSTRAINT participants_team_id_fk FOREIGN KEY (team_id) REFERENCES teams(id),
+    ADD INDEX participants_team_id_idx (tournament_id, team_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE payouts
+    ADD COLUMN team_id INT,
+    ADD CONSTRAINT payouts_team_id_fk FOREIGN KEY (team_id) REFERENCES teams(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payouts
+    DROP FOREIGN KEY payouts_team_id_fk,
+    DROP COLUMN team_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE participants
+    DROP FOREIGN KEY participants_team_id_fk,
+    DROP INDEX participants_team_id_idx,
+    DROP COLUMN team_id,
+    DROP COLUMN share;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_winner_team_fk,
+    DROP COLUMN team_based,
+    DROP COLUMN winner_team;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE team_invites;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE team_members;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE teams;
+-- +goose StatementEnd
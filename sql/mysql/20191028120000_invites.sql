@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN visibility ENUM('public', 'private') NOT NULL DEFAULT 'public',
+    ADD COLUMN organizer_id INT,
+    ADD CONSTRAINT tournaments_organizer_id_fk FOREIGN KEY (organizer_id) REFERENCES users(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE invite_codes (
+    code VARCHAR(32) NOT NULL,
+    tournament_id INT NOT NULL,
+    expires_at DATETIME NULL,
+    max_uses INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    uses INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    INDEX (tournament_id, created_at),
+    PRIMARY KEY (code)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE invite_codes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_organizer_id_fk,
+    DROP COLUMN visibility,
+    DROP COLUMN organizer_id;
+-- +goose StatementEnd
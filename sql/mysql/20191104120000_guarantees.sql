@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO house_accounts (name)
+VALUES ('sponsor');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN guaranteed_prize INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN sponsor VARCHAR(20),
+    ADD COLUMN overlay INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    ADD CONSTRAINT tournaments_sponsor_fk FOREIGN KEY (sponsor) REFERENCES house_accounts(name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_sponsor_fk,
+    DROP COLUMN guaranteed_prize,
+    DROP COLUMN sponsor,
+    DROP COLUMN overlay;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DELETE
+  FROM house_accounts
+ WHERE name = 'sponsor';
+-- +goose StatementEnd
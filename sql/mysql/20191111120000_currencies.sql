@@ -0,0 +1,123 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE currencies (
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (code)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO currencies (code, name)
+VALUES ('bonus', 'Bonus points'),
+       ('premium', 'Premium coins'),
+       ('play', 'Play money');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE balances (
+    user_id INT NOT NULL,
+    currency VARCHAR(20) NOT NULL,
+    balance INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (currency) REFERENCES currencies(code),
+    PRIMARY KEY (user_id, currency)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO balances (user_id, currency, balance)
+SELECT id, 'bonus', balance
+  FROM users;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN balance;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    ADD COLUMN currency VARCHAR(20) NOT NULL DEFAULT 'bonus',
+    ADD CONSTRAINT transactions_currency_fk FOREIGN KEY (currency) REFERENCES currencies(code);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_sponsor_fk,
+    ADD COLUMN currency VARCHAR(20) NOT NULL DEFAULT 'bonus',
+    ADD CONSTRAINT tournaments_currency_fk FOREIGN KEY (currency) REFERENCES currencies(code);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE house_accounts
+    DROP PRIMARY KEY,
+    ADD COLUMN currency VARCHAR(20) NOT NULL DEFAULT 'bonus',
+    ADD PRIMARY KEY (name, currency),
+    ADD CONSTRAINT house_accounts_currency_fk FOREIGN KEY (currency) REFERENCES currencies(code);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO house_accounts (name, currency)
+SELECT h.name, c.code
+  FROM house_accounts AS h
+       CROSS JOIN currencies AS c
+ WHERE c.code <> 'bonus';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD CONSTRAINT tournaments_sponsor_fk FOREIGN KEY (sponsor, currency) REFERENCES house_accounts(name, currency);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_sponsor_fk;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DELETE
+  FROM house_accounts
+ WHERE currency <> 'bonus';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE house_accounts
+    DROP FOREIGN KEY house_accounts_currency_fk,
+    DROP PRIMARY KEY,
+    DROP COLUMN currency,
+    ADD PRIMARY KEY (name);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP FOREIGN KEY tournaments_currency_fk,
+    DROP COLUMN currency,
+    ADD CONSTRAINT tournaments_sponsor_fk FOREIGN KEY (sponsor) REFERENCES house_accounts(name);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    DROP FOREIGN KEY transactions_currency_fk,
+    DROP COLUMN currency;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN balance INT(10) UNSIGNED NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE users AS u
+  JOIN balances AS b ON b.user_id = u.id AND b.currency = 'bonus'
+   SET u.balance = b.balance;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE balances;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE currencies;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE point_lots (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    currency VARCHAR(20) NOT NULL,
+    amount INT(10) UNSIGNED NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id, currency) REFERENCES balances(user_id, currency) ON DELETE CASCADE,
+    INDEX (user_id, currency, expires_at),
+    INDEX (expires_at),
+    PRIMARY KEY (id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE lot_spends (
+    lot_id INT NOT NULL,
+    tournament_id INT NOT NULL,
+    amount INT(10) UNSIGNED NOT NULL,
+    FOREIGN KEY (lot_id) REFERENCES point_lots(id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    INDEX (tournament_id),
+    PRIMARY KEY (lot_id, tournament_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    MODIFY COLUMN reason ENUM('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry') NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+  FROM transactions
+ WHERE reason = 'expiry';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    MODIFY COLUMN reason ENUM('fund', 'take', 'entry_fee', 'prize', 'refund') NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE lot_spends;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE point_lots;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE currencies
+    ADD COLUMN daily_transfer_cap INT(10) UNSIGNED NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    MODIFY COLUMN reason ENUM('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry', 'transfer') NOT NULL,
+    ADD COLUMN peer_id INT,
+    ADD CONSTRAINT transactions_peer_fk FOREIGN KEY (peer_id) REFERENCES users(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+  FROM transactions
+ WHERE reason = 'transfer';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE transactions
+    DROP FOREIGN KEY transactions_peer_fk,
+    DROP COLUMN peer_id,
+    MODIFY COLUMN reason ENUM('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry') NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE currencies
+    DROP COLUMN daily_transfer_cap;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL,
+    request TEXT NOT NULL,
+    response VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    INDEX (created_at),
+    PRIMARY KEY (idempotency_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
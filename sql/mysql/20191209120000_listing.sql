@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX tournaments_visibility_created_at_idx ON tournaments (visibility, created_at, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX tournaments_visibility_prize_idx ON tournaments (visibility, prize, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX tournaments_visibility_starts_at_idx ON tournaments (visibility, starts_at, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX tournaments_visibility_name_idx ON tournaments (visibility, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tournaments_visibility_created_at_idx ON tournaments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX tournaments_visibility_prize_idx ON tournaments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX tournaments_visibility_starts_at_idx ON tournaments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX tournaments_visibility_name_idx ON tournaments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN created_at;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    MODIFY name VARCHAR(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    ADD COLUMN display_name VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN country CHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN bio VARCHAR(500) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE users AS u
+  JOIN (SELECT DISTINCT d.id
+          FROM users AS d
+          JOIN users AS o ON o.name = d.name AND o.id < d.id) AS dup ON dup.id = u.id
+   SET u.name = CONCAT(LEFT(u.name, 19 - LENGTH(u.id)), '_', u.id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX users_name_idx ON users (name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_name_idx ON users;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users
+    MODIFY name VARCHAR(20) NOT NULL,
+    DROP COLUMN display_name,
+    DROP COLUMN avatar_url,
+    DROP COLUMN country,
+    DROP COLUMN bio;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE payouts
+(
+    tournament_id INT    NOT NULL,
+    place         INT    NOT NULL CHECK (place > 0),
+    share         INT    NOT NULL CHECK (share > 0 AND share <= 100),
+    amount        BIGINT CHECK (amount >= 0),
+    user_id       INT,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    PRIMARY KEY (tournament_id, place)
+);
+
+INSERT INTO payouts (tournament_id, place, share, amount, user_id)
+SELECT id, 1, 100, CASE WHEN finished THEN prize END, winner
+  FROM tournaments;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE payouts;
+-- +goose StatementEnd
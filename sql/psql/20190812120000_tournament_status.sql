@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'registration', 'running', 'finished', 'cancelled'));
+
+UPDATE tournaments
+   SET status = CASE WHEN finished THEN 'finished' ELSE 'registration' END;
+
+ALTER TABLE tournaments
+    DROP COLUMN finished;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN finished BOOL DEFAULT FALSE;
+
+UPDATE tournaments
+   SET finished = status = 'finished';
+
+ALTER TABLE tournaments
+    DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE transactions
+(
+    id            SERIAL,
+    user_id       INT         NOT NULL,
+    amount        BIGINT      NOT NULL,
+    reason        TEXT        NOT NULL CHECK (reason IN ('fund', 'take', 'entry_fee', 'prize', 'refund')),
+    tournament_id INT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE SET NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX transactions_user_id_idx ON transactions (user_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE transactions;
+-- +goose StatementEnd
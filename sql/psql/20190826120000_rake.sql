@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN rake_type   TEXT   NOT NULL DEFAULT '' CHECK (rake_type IN ('', 'percent', 'fixed')),
+    ADD COLUMN rake        BIGINT NOT NULL DEFAULT 0 CHECK (rake >= 0),
+    ADD COLUMN gross_prize BIGINT NOT NULL DEFAULT 0 CHECK (gross_prize >= 0);
+
+UPDATE tournaments
+   SET gross_prize = prize;
+
+CREATE TABLE house_accounts
+(
+    name    TEXT   NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    PRIMARY KEY (name)
+);
+
+INSERT INTO house_accounts (name)
+VALUES ('rake');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE house_accounts;
+
+ALTER TABLE tournaments
+    DROP COLUMN rake_type,
+    DROP COLUMN rake,
+    DROP COLUMN gross_prize;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN min_players INT NOT NULL DEFAULT 0 CHECK (min_players >= 0),
+    ADD COLUMN max_players INT NOT NULL DEFAULT 0 CHECK (max_players >= 0);
+
+CREATE TABLE waitlist
+(
+    id            SERIAL,
+    user_id       INT         NOT NULL,
+    tournament_id INT         NOT NULL,
+    joined_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE CASCADE,
+    UNIQUE (user_id, tournament_id),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX waitlist_tournament_id_idx ON waitlist (tournament_id, joined_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE waitlist;
+
+ALTER TABLE tournaments
+    DROP COLUMN min_players,
+    DROP COLUMN max_players;
+-- +goose StatementEnd
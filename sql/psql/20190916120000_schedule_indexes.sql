@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX tournaments_registration_opens_at_idx ON tournaments (registration_opens_at) WHERE status = 'draft';
+CREATE INDEX tournaments_starts_at_idx ON tournaments (starts_at) WHERE status = 'registration';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tournaments_registration_opens_at_idx;
+DROP INDEX tournaments_starts_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN format TEXT NOT NULL DEFAULT 'pool'
+        CHECK (format IN ('pool', 'single_elimination', 'round_robin', 'swiss')),
+    ADD COLUMN rounds INT  NOT NULL DEFAULT 0 CHECK (rounds >= 0);
+
+CREATE TABLE pairings
+(
+    tournament_id INT  NOT NULL,
+    round         INT  NOT NULL CHECK (round > 0),
+    board         INT  NOT NULL CHECK (board > 0),
+    user1_id      INT,
+    user2_id      INT,
+    result        TEXT NOT NULL DEFAULT '' CHECK (result IN ('', 'win', 'draw', 'loss')),
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE CASCADE,
+    FOREIGN KEY (user1_id) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (user2_id) REFERENCES users (id) ON DELETE SET NULL,
+    PRIMARY KEY (tournament_id, round, board)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE pairings;
+
+ALTER TABLE tournaments
+    DROP COLUMN format,
+    DROP COLUMN rounds;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN rating            DOUBLE PRECISION NOT NULL DEFAULT 1500,
+    ADD COLUMN rating_deviation  DOUBLE PRECISION NOT NULL DEFAULT 350 CHECK (rating_deviation > 0),
+    ADD COLUMN rating_volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06 CHECK (rating_volatility > 0),
+    ADD COLUMN games             INT              NOT NULL DEFAULT 0 CHECK (games >= 0);
+
+ALTER TABLE tournaments
+    ADD COLUMN min_rating INT NOT NULL DEFAULT 0 CHECK (min_rating >= 0),
+    ADD COLUMN max_rating INT NOT NULL DEFAULT 0 CHECK (max_rating >= 0);
+
+CREATE TABLE rating_history
+(
+    id            SERIAL,
+    user_id       INT              NOT NULL,
+    tournament_id INT,
+    rating        DOUBLE PRECISION NOT NULL,
+    deviation     DOUBLE PRECISION NOT NULL,
+    volatility    DOUBLE PRECISION NOT NULL,
+    created_at    TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE SET NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX rating_history_user_id_idx ON rating_history (user_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rating_history;
+
+ALTER TABLE tournaments
+    DROP COLUMN min_rating,
+    DROP COLUMN max_rating;
+
+ALTER TABLE users
+    DROP COLUMN rating,
+    DROP COLUMN rating_deviation,
+    DROP COLUMN rating_volatility,
+    DROP COLUMN games;
+-- +goose StatementEnd
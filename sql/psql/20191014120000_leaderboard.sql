@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE leaderboard_stats
+(
+    period       TEXT   NOT NULL CHECK (period IN ('all', 'month', 'week')),
+    period_start DATE   NOT NULL,
+    user_id      INT    NOT NULL,
+    prize        BIGINT NOT NULL DEFAULT 0 CHECK (prize >= 0),
+    wins         INT    NOT NULL DEFAULT 0 CHECK (wins >= 0),
+    tournaments  INT    NOT NULL DEFAULT 0 CHECK (tournaments >= 0),
+    profit       BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (period, period_start, user_id)
+);
+
+CREATE INDEX leaderboard_stats_prize_idx ON leaderboard_stats (period, period_start, prize DESC, user_id);
+CREATE INDEX leaderboard_stats_wins_idx ON leaderboard_stats (period, period_start, wins DESC, user_id);
+CREATE INDEX leaderboard_stats_profit_idx ON leaderboard_stats (period, period_start, profit DESC, user_id);
+
+-- Finish time of earlier tournaments isn't known, so they are counted in all-time leaderboard only.
+INSERT INTO leaderboard_stats (period, period_start, user_id, prize, wins, tournaments, profit)
+     SELECT 'all', '1970-01-01', p.user_id, COALESCE(SUM(pay.amount), 0),
+            COUNT(*) FILTER (WHERE t.winner = p.user_id), COUNT(*),
+            COALESCE(SUM(pay.amount), 0) - SUM(t.deposit)
+       FROM participants AS p
+       JOIN tournaments AS t ON t.id = p.tournament_id AND t.status = 'finished'
+  LEFT JOIN payouts AS pay ON pay.tournament_id = p.tournament_id AND pay.user_id = p.user_id
+   GROUP BY p.user_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE leaderboard_stats;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE teams
+(
+    id         SERIAL,
+    name       TEXT NOT NULL,
+    captain_id INT  NOT NULL,
+    FOREIGN KEY (captain_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE team_members
+(
+    team_id INT NOT NULL,
+    user_id INT NOT NULL,
+    share   INT NOT NULL CHECK (share > 0 AND share <= 100),
+    FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE TABLE team_invites
+(
+    team_id INT NOT NULL,
+    user_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, user_id)
+);
+
+ALTER TABLE tournaments
+    ADD COLUMN team_based  BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN winner_team INT REFERENCES teams (id);
+
+-- Members of a team that has joined a tournament keep the shares they had when the team joined.
+ALTER TABLE participants
+    ADD COLUMN team_id INT REFERENCES teams (id),
+    ADD COLUMN share   INT CHECK (share > 0 AND share <= 100);
+
+CREATE INDEX participants_team_id_idx ON participants (tournament_id, team_id);
+
+ALTER TABLE payouts
+    ADD COLUMN team_id INT REFERENCES teams (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payouts
+    DROP COLUMN team_id;
+
+DROP INDEX participants_team_id_idx;
+
+ALTER TABLE participants
+    DROP COLUMN team_id,
+    DROP COLUMN share;
+
+ALTER TABLE tournaments
+    DROP COLUMN team_based,
+    DROP COLUMN winner_team;
+
+DROP TABLE team_invites;
+DROP TABLE team_members;
+DROP TABLE teams;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN visibility   TEXT NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private')),
+    ADD COLUMN organizer_id INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE TABLE invite_codes
+(
+    code          TEXT        NOT NULL,
+    tournament_id INT         NOT NULL,
+    expires_at    TIMESTAMPTZ,
+    max_uses      INT         NOT NULL DEFAULT 0 CHECK (max_uses >= 0),
+    uses          INT         NOT NULL DEFAULT 0 CHECK (uses >= 0),
+    revoked       BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE CASCADE,
+    PRIMARY KEY (code)
+);
+
+CREATE INDEX invite_codes_tournament_id_idx ON invite_codes (tournament_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE invite_codes;
+
+ALTER TABLE tournaments
+    DROP COLUMN visibility,
+    DROP COLUMN organizer_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO house_accounts (name)
+VALUES ('sponsor');
+
+ALTER TABLE tournaments
+    ADD COLUMN guaranteed_prize BIGINT NOT NULL DEFAULT 0 CHECK (guaranteed_prize >= 0),
+    ADD COLUMN sponsor          TEXT REFERENCES house_accounts (name),
+    ADD COLUMN overlay          BIGINT NOT NULL DEFAULT 0 CHECK (overlay >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP COLUMN guaranteed_prize,
+    DROP COLUMN sponsor,
+    DROP COLUMN overlay;
+
+DELETE
+  FROM house_accounts
+ WHERE name = 'sponsor';
+-- +goose StatementEnd
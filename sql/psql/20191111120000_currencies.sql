@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE currencies
+(
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (code)
+);
+
+INSERT INTO currencies (code, name)
+VALUES ('bonus', 'Bonus points'),
+       ('premium', 'Premium coins'),
+       ('play', 'Play money');
+
+CREATE TABLE balances
+(
+    user_id  INT    NOT NULL,
+    currency TEXT   NOT NULL,
+    balance  BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (currency) REFERENCES currencies (code),
+    PRIMARY KEY (user_id, currency)
+);
+
+INSERT INTO balances (user_id, currency, balance)
+SELECT id, 'bonus', balance
+  FROM users;
+
+ALTER TABLE users
+    DROP COLUMN balance;
+
+ALTER TABLE transactions
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'bonus' REFERENCES currencies (code);
+
+ALTER TABLE tournaments
+    DROP CONSTRAINT tournaments_sponsor_fkey,
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'bonus' REFERENCES currencies (code);
+
+ALTER TABLE house_accounts
+    DROP CONSTRAINT house_accounts_pkey,
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'bonus' REFERENCES currencies (code),
+    ADD PRIMARY KEY (name, currency);
+
+INSERT INTO house_accounts (name, currency)
+SELECT h.name, c.code
+  FROM house_accounts AS h
+       CROSS JOIN currencies AS c
+ WHERE c.code <> 'bonus';
+
+ALTER TABLE tournaments
+    ADD CONSTRAINT tournaments_sponsor_fkey FOREIGN KEY (sponsor, currency)
+        REFERENCES house_accounts (name, currency);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    DROP CONSTRAINT tournaments_sponsor_fkey;
+
+DELETE
+  FROM house_accounts
+ WHERE currency <> 'bonus';
+
+ALTER TABLE house_accounts
+    DROP CONSTRAINT house_accounts_pkey,
+    DROP COLUMN currency,
+    ADD PRIMARY KEY (name);
+
+ALTER TABLE tournaments
+    DROP COLUMN currency,
+    ADD CONSTRAINT tournaments_sponsor_fkey FOREIGN KEY (sponsor) REFERENCES house_accounts (name);
+
+ALTER TABLE transactions
+    DROP COLUMN currency;
+
+ALTER TABLE users
+    ADD COLUMN balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0);
+
+UPDATE users AS u
+   SET balance = b.balance
+  FROM balances AS b
+ WHERE b.user_id = u.id AND b.currency = 'bonus';
+
+DROP TABLE balances;
+
+DROP TABLE currencies;
+-- +goose StatementEnd
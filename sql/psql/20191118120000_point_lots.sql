@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE point_lots
+(
+    id         SERIAL,
+    user_id    INT         NOT NULL,
+    currency   TEXT        NOT NULL,
+    amount     BIGINT      NOT NULL CHECK (amount >= 0),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (user_id, currency) REFERENCES balances (user_id, currency) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX point_lots_user_id_idx ON point_lots (user_id, currency, expires_at);
+CREATE INDEX point_lots_expires_at_idx ON point_lots (expires_at);
+
+CREATE TABLE lot_spends
+(
+    lot_id        INT    NOT NULL,
+    tournament_id INT    NOT NULL,
+    amount        BIGINT NOT NULL CHECK (amount > 0),
+    FOREIGN KEY (lot_id) REFERENCES point_lots (id) ON DELETE CASCADE,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments (id) ON DELETE CASCADE,
+    PRIMARY KEY (lot_id, tournament_id)
+);
+
+CREATE INDEX lot_spends_tournament_id_idx ON lot_spends (tournament_id);
+
+ALTER TABLE transactions
+    DROP CONSTRAINT transactions_reason_check,
+    ADD CONSTRAINT transactions_reason_check
+        CHECK (reason IN ('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+  FROM transactions
+ WHERE reason = 'expiry';
+
+ALTER TABLE transactions
+    DROP CONSTRAINT transactions_reason_check,
+    ADD CONSTRAINT transactions_reason_check
+        CHECK (reason IN ('fund', 'take', 'entry_fee', 'prize', 'refund'));
+
+DROP TABLE lot_spends;
+
+DROP TABLE point_lots;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE currencies
+    ADD COLUMN daily_transfer_cap BIGINT CHECK (daily_transfer_cap >= 0);
+
+ALTER TABLE transactions
+    DROP CONSTRAINT transactions_reason_check,
+    ADD CONSTRAINT transactions_reason_check
+        CHECK (reason IN ('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry', 'transfer')),
+    ADD COLUMN peer_id INT REFERENCES users (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+  FROM transactions
+ WHERE reason = 'transfer';
+
+ALTER TABLE transactions
+    DROP COLUMN peer_id,
+    DROP CONSTRAINT transactions_reason_check,
+    ADD CONSTRAINT transactions_reason_check
+        CHECK (reason IN ('fund', 'take', 'entry_fee', 'prize', 'refund', 'expiry'));
+
+ALTER TABLE currencies
+    DROP COLUMN daily_transfer_cap;
+-- +goose StatementEnd
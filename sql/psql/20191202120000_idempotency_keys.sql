@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys
+(
+    idempotency_key VARCHAR(255) NOT NULL,
+    request         TEXT         NOT NULL,
+    response        TEXT         NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
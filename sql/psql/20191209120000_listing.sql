@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tournaments
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX tournaments_public_created_at_idx ON tournaments (created_at, id) WHERE visibility = 'public';
+CREATE INDEX tournaments_public_prize_idx ON tournaments (prize, id) WHERE visibility = 'public';
+CREATE INDEX tournaments_public_starts_at_idx ON tournaments (starts_at, id) WHERE visibility = 'public';
+CREATE INDEX tournaments_public_name_idx ON tournaments (name text_pattern_ops) WHERE visibility = 'public';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tournaments_public_created_at_idx;
+DROP INDEX tournaments_public_prize_idx;
+DROP INDEX tournaments_public_starts_at_idx;
+DROP INDEX tournaments_public_name_idx;
+
+ALTER TABLE tournaments
+    DROP COLUMN created_at;
+-- +goose StatementEnd
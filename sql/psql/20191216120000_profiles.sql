@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN display_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url   TEXT NOT NULL DEFAULT '',
+    ADD COLUMN country      TEXT NOT NULL DEFAULT '',
+    ADD COLUMN bio          TEXT NOT NULL DEFAULT '';
+
+UPDATE users AS u
+   SET name = left(u.name, 19 - length(u.id::TEXT)) || '_' || u.id
+ WHERE EXISTS(SELECT 1 FROM users AS o WHERE lower(o.name) = lower(u.name) AND o.id < u.id);
+
+CREATE UNIQUE INDEX users_name_idx ON users (lower(name));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_name_idx;
+
+ALTER TABLE users
+    DROP COLUMN display_name,
+    DROP COLUMN avatar_url,
+    DROP COLUMN country,
+    DROP COLUMN bio;
+-- +goose StatementEnd